@@ -0,0 +1,98 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env
+
+// AWSEnv is a typed view of the environment variables consumed by the AWS
+// SDKs and command line tools.
+type AWSEnv struct {
+	AccessKeyID           string
+	SecretAccessKey       string
+	SessionToken          string
+	Region                string
+	Profile               string
+	SharedCredentialsFile string
+	ConfigFile            string
+}
+
+// awsVars lists the variables making up each AWSEnv field, in order of
+// precedence. The first variable is the canonical one.
+var awsVars = []struct {
+	field func(a *AWSEnv) *string
+	keys  []string
+}{
+	{
+		field: func(a *AWSEnv) *string { return &a.AccessKeyID },
+		keys:  []string{"AWS_ACCESS_KEY_ID", "AWS_ACCESS_KEY"},
+	},
+	{
+		field: func(a *AWSEnv) *string { return &a.SecretAccessKey },
+		keys:  []string{"AWS_SECRET_ACCESS_KEY", "AWS_SECRET_KEY"},
+	},
+	{
+		field: func(a *AWSEnv) *string { return &a.SessionToken },
+		keys:  []string{"AWS_SESSION_TOKEN", "AWS_SECURITY_TOKEN"},
+	},
+	{
+		field: func(a *AWSEnv) *string { return &a.Region },
+		keys:  []string{"AWS_REGION", "AWS_DEFAULT_REGION"},
+	},
+	{
+		field: func(a *AWSEnv) *string { return &a.Profile },
+		keys:  []string{"AWS_PROFILE", "AWS_DEFAULT_PROFILE"},
+	},
+	{
+		field: func(a *AWSEnv) *string { return &a.SharedCredentialsFile },
+		keys:  []string{"AWS_SHARED_CREDENTIALS_FILE"},
+	},
+	{
+		field: func(a *AWSEnv) *string { return &a.ConfigFile },
+		keys:  []string{"AWS_CONFIG_FILE"},
+	},
+}
+
+// AWS returns the AWS configuration described by m. Where several
+// variables configure the same setting, AWS honors the precedence
+// documented by the AWS SDKs: for example, AWS_REGION takes precedence
+// over AWS_DEFAULT_REGION, and AWS_ACCESS_KEY_ID over the legacy
+// AWS_ACCESS_KEY. Empty values are treated as unset.
+func AWS(m Map) AWSEnv {
+	var a AWSEnv
+	for _, v := range awsVars {
+		for _, k := range v.keys {
+			if val := m[k]; val != "" {
+				*v.field(&a) = val
+				break
+			}
+		}
+	}
+	return a
+}
+
+// Inject sets the canonical variables for each non-empty setting in a
+// into m. Variables of lower precedence which configure the same setting
+// are removed from m, so that stale values cannot be picked up by
+// programs which only understand the legacy names.
+func (a AWSEnv) Inject(m Map) {
+	for _, v := range awsVars {
+		val := *v.field(&a)
+		if val == "" {
+			continue
+		}
+		m[v.keys[0]] = val
+		for _, k := range v.keys[1:] {
+			delete(m, k)
+		}
+	}
+}
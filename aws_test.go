@@ -0,0 +1,100 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env_test
+
+import (
+	"testing"
+
+	"acln.ro/env"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestAWS(t *testing.T) {
+	tests := []struct {
+		m    env.Map
+		want env.AWSEnv
+	}{
+		{
+			m:    env.Map{},
+			want: env.AWSEnv{},
+		},
+		{
+			m: env.Map{
+				"AWS_ACCESS_KEY_ID":     "AKID",
+				"AWS_SECRET_ACCESS_KEY": "secret",
+				"AWS_SESSION_TOKEN":     "token",
+				"AWS_REGION":            "eu-west-1",
+				"AWS_DEFAULT_REGION":    "us-east-1",
+				"AWS_PROFILE":           "prod",
+				"AWS_CONFIG_FILE":       "/etc/aws/config",
+			},
+			want: env.AWSEnv{
+				AccessKeyID:     "AKID",
+				SecretAccessKey: "secret",
+				SessionToken:    "token",
+				Region:          "eu-west-1",
+				Profile:         "prod",
+				ConfigFile:      "/etc/aws/config",
+			},
+		},
+		{
+			m: env.Map{
+				"AWS_ACCESS_KEY":              "legacy",
+				"AWS_SECRET_KEY":              "legacy-secret",
+				"AWS_REGION":                  "",
+				"AWS_DEFAULT_REGION":          "us-east-1",
+				"AWS_DEFAULT_PROFILE":         "dev",
+				"AWS_SHARED_CREDENTIALS_FILE": "/creds",
+			},
+			want: env.AWSEnv{
+				AccessKeyID:           "legacy",
+				SecretAccessKey:       "legacy-secret",
+				Region:                "us-east-1",
+				Profile:               "dev",
+				SharedCredentialsFile: "/creds",
+			},
+		},
+	}
+	for _, tt := range tests {
+		got := env.AWS(tt.m)
+		if diff := cmp.Diff(got, tt.want); diff != "" {
+			t.Errorf("AWS(%v) = %+v, want %+v: %s", tt.m, got, tt.want, diff)
+		}
+	}
+}
+
+func TestAWSInject(t *testing.T) {
+	a := env.AWSEnv{
+		AccessKeyID: "AKID",
+		Region:      "eu-west-1",
+	}
+	m := env.Map{
+		"AWS_ACCESS_KEY":     "stale",
+		"AWS_DEFAULT_REGION": "us-east-1",
+		"AWS_PROFILE":        "dev",
+		"HOME":               "/root",
+	}
+	a.Inject(m)
+	want := env.Map{
+		"AWS_ACCESS_KEY_ID": "AKID",
+		"AWS_REGION":        "eu-west-1",
+		"AWS_PROFILE":       "dev",
+		"HOME":              "/root",
+	}
+	if diff := cmp.Diff(m, want); diff != "" {
+		t.Errorf("after Inject: got %v, want %v: %s", m, want, diff)
+	}
+}
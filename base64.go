@@ -0,0 +1,63 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env
+
+import (
+	"encoding/base64"
+	"strings"
+)
+
+// base64Encodings lists the encodings accepted by Map.Base64, in the order
+// in which they are tried.
+var base64Encodings = []*base64.Encoding{
+	base64.StdEncoding,
+	base64.RawStdEncoding,
+	base64.URLEncoding,
+	base64.RawURLEncoding,
+}
+
+// Base64 decodes the base64-encoded value of the variable named by key.
+// The standard and URL-safe alphabets are accepted, with or without
+// padding. Whitespace, such as that introduced by wrapping long encoded
+// values across lines, is ignored.
+func (m Map) Base64(key string) ([]byte, error) {
+	return m.base64(key, base64Encodings)
+}
+
+// Base64Encoding is like Base64, but decodes the value using enc only.
+// For example, Base64Encoding(key, base64.RawURLEncoding) decodes values
+// which are URL-safe and unpadded, and rejects all others.
+func (m Map) Base64Encoding(key string, enc *base64.Encoding) ([]byte, error) {
+	return m.base64(key, []*base64.Encoding{enc})
+}
+
+func (m Map) base64(key string, encs []*base64.Encoding) ([]byte, error) {
+	v, ok := m[key]
+	if !ok {
+		return nil, notSet(key)
+	}
+	v = strings.Join(strings.Fields(v), "")
+	var first error
+	for _, enc := range encs {
+		b, err := enc.DecodeString(v)
+		if err == nil {
+			return b, nil
+		}
+		if first == nil {
+			first = err
+		}
+	}
+	return nil, invalid(key, first)
+}
@@ -0,0 +1,61 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env_test
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"acln.ro/env"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestBase64(t *testing.T) {
+	data := []byte{0xfb, 0xff, 0xbf, 'h', 'i'}
+	m := env.Map{
+		"STD":     base64.StdEncoding.EncodeToString(data),
+		"RAW_STD": base64.RawStdEncoding.EncodeToString(data),
+		"URL":     base64.URLEncoding.EncodeToString(data),
+		"RAW_URL": base64.RawURLEncoding.EncodeToString(data),
+		"WRAPPED": "+/+/\naGk=\n",
+		"BAD":     "not base64!",
+	}
+	for _, key := range []string{"STD", "RAW_STD", "URL", "RAW_URL", "WRAPPED"} {
+		got, err := m.Base64(key)
+		if err != nil {
+			t.Errorf("Base64(%s): %v", key, err)
+			continue
+		}
+		if diff := cmp.Diff(data, got); diff != "" {
+			t.Errorf("Base64(%s): (-want +got)\n%s", key, diff)
+		}
+	}
+	var verr *env.ValidationError
+	if _, err := m.Base64("BAD"); !errors.As(err, &verr) || verr.Key != "BAD" {
+		t.Errorf("Base64(BAD): got %v, want ValidationError", err)
+	}
+	if _, err := m.Base64("UNSET"); !errors.Is(err, env.ErrNotFound) {
+		t.Errorf("Base64(UNSET): got %v, want ErrNotFound", err)
+	}
+
+	if _, err := m.Base64Encoding("RAW_URL", base64.RawURLEncoding); err != nil {
+		t.Errorf("Base64Encoding(RAW_URL, RawURLEncoding): %v", err)
+	}
+	if _, err := m.Base64Encoding("STD", base64.RawURLEncoding); err == nil {
+		t.Errorf("Base64Encoding(STD, RawURLEncoding): got nil error")
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env
+
+import "runtime"
+
+// Default search paths used by Baseline.
+const (
+	unixBaselinePath = "/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin"
+	windowsPathExt   = ".COM;.EXE;.BAT;.CMD"
+)
+
+// Baseline returns a minimal environment, suitable as the starting point
+// for the environment of a sandboxed child process, for use with
+// CommandIsolated. The identity and directories of the user are taken from
+// the current process; everything else is fixed.
+//
+// On Unix systems, Baseline holds:
+//
+//	PATH     /usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin
+//	HOME     the home directory of the user
+//	USER     the name of the user, also set as LOGNAME
+//	LANG     C.UTF-8
+//	TMPDIR   the temporary directory, as reported by TempDir
+//
+// On Windows, Baseline holds:
+//
+//	SystemRoot   the Windows directory, by default C:\Windows
+//	ComSpec      %SystemRoot%\System32\cmd.exe
+//	PATH         the System32, Windows, Wbem and PowerShell directories
+//	PATHEXT      .COM;.EXE;.BAT;.CMD
+//	TEMP, TMP    the temporary directory, as reported by TempDir
+//	USERPROFILE  the home directory of the user
+//	USERNAME     the name of the user
+//
+// Variables which cannot be determined are omitted.
+func Baseline() Map {
+	return baseline(runtime.GOOS, Variables())
+}
+
+// BaselineIn is like Baseline, but takes the identity and directories of
+// the user from e, rather than from the environment of the current
+// process.
+func BaselineIn(e Environment) Map {
+	return baseline(runtime.GOOS, e.All())
+}
+
+// baseline returns the baseline environment for goos, given the
+// environment cur of the current process.
+func baseline(goos string, cur Map) Map {
+	b := make(Map)
+	setNonEmpty := func(key, value string) {
+		if value != "" {
+			b[key] = value
+		}
+	}
+	if goos == "windows" {
+		root := cur.lookupFold("SystemRoot")
+		if root == "" {
+			root = `C:\Windows`
+		}
+		b["SystemRoot"] = root
+		b["ComSpec"] = root + `\System32\cmd.exe`
+		b["PATH"] = root + `\System32;` + root + ";" + root + `\System32\Wbem;` + root + `\System32\WindowsPowerShell\v1.0\`
+		b["PATHEXT"] = windowsPathExt
+		setNonEmpty("TEMP", cur.tempDir(goos))
+		setNonEmpty("TMP", cur.tempDir(goos))
+		setNonEmpty("USERPROFILE", cur.home(goos))
+		setNonEmpty("USERNAME", cur.user(goos))
+		return b
+	}
+	b["PATH"] = unixBaselinePath
+	b["LANG"] = "C.UTF-8"
+	b["TMPDIR"] = cur.tempDir(goos)
+	setNonEmpty("HOME", cur.home(goos))
+	setNonEmpty("USER", cur.user(goos))
+	setNonEmpty("LOGNAME", cur.user(goos))
+	return b
+}
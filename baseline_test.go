@@ -0,0 +1,100 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env_test
+
+import (
+	"os/exec"
+	"runtime"
+	"testing"
+
+	"acln.ro/env"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestBaselineUnix(t *testing.T) {
+	cur := env.Map{
+		"HOME":       "/home/u",
+		"LOGNAME":    "u",
+		"PATH":       "/home/u/bin:/usr/bin",
+		"AWS_SECRET": "s",
+		"TERM":       "xterm",
+	}
+	got := env.BaselineFor("linux", cur)
+	want := env.Map{
+		"PATH":    "/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin",
+		"HOME":    "/home/u",
+		"USER":    "u",
+		"LOGNAME": "u",
+		"LANG":    "C.UTF-8",
+		"TMPDIR":  "/tmp",
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("Baseline: %s", diff)
+	}
+}
+
+func TestBaselineWindows(t *testing.T) {
+	cur := env.Map{
+		"SYSTEMROOT":  `D:\Win`,
+		"USERPROFILE": `C:\Users\u`,
+		"USERNAME":    "u",
+		"TEMP":        `C:\Users\u\AppData\Local\Temp`,
+	}
+	got := env.BaselineFor("windows", cur)
+	want := env.Map{
+		"SystemRoot":  `D:\Win`,
+		"ComSpec":     `D:\Win\System32\cmd.exe`,
+		"PATH":        `D:\Win\System32;D:\Win;D:\Win\System32\Wbem;D:\Win\System32\WindowsPowerShell\v1.0\`,
+		"PATHEXT":     ".COM;.EXE;.BAT;.CMD",
+		"TEMP":        `C:\Users\u\AppData\Local\Temp`,
+		"TMP":         `C:\Users\u\AppData\Local\Temp`,
+		"USERPROFILE": `C:\Users\u`,
+		"USERNAME":    "u",
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("Baseline: %s", diff)
+	}
+}
+
+func TestBaselineRunsCommands(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	out, err := env.CommandIsolated(env.Baseline(), "sh", "-c", "command -v ls >/dev/null && echo ok").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != "ok\n" {
+		t.Errorf("got %q, want %q", out, "ok\n")
+	}
+}
+
+func TestBaselineIn(t *testing.T) {
+	cur := env.Map{
+		"HOME":        "/home/u",
+		"LOGNAME":     "u",
+		"SYSTEMROOT":  `D:\Win`,
+		"USERPROFILE": `C:\Users\u`,
+		"USERNAME":    "u",
+	}
+	got := env.BaselineIn(env.Fake(cur))
+	if diff := cmp.Diff(got, env.BaselineFor(runtime.GOOS, cur)); diff != "" {
+		t.Errorf("BaselineIn: %s", diff)
+	}
+}
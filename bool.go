@@ -0,0 +1,102 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env
+
+import (
+	"fmt"
+	"strings"
+)
+
+// A BoolOption configures the parsing of boolean values.
+type BoolOption func(*boolConfig)
+
+type boolConfig struct {
+	trues     []string
+	falses    []string
+	unknown   bool
+	unknownOK bool
+}
+
+// Default spellings of boolean values.
+var (
+	defaultTrues  = []string{"true", "yes", "on", "1"}
+	defaultFalses = []string{"false", "no", "off", "0"}
+)
+
+// StrictBool restricts the accepted spellings to "true" and "false".
+func StrictBool() BoolOption {
+	return BoolWords([]string{"true"}, []string{"false"})
+}
+
+// BoolWords sets the accepted spellings of true and false values, to
+// accommodate systems with other conventions, such as "enabled" and
+// "disabled". Spellings are compared ignoring case.
+func BoolWords(trues, falses []string) BoolOption {
+	return func(cfg *boolConfig) {
+		cfg.trues = trues
+		cfg.falses = falses
+	}
+}
+
+// UnknownBool causes values which are not accepted spellings to parse as
+// def, rather than causing an error.
+func UnknownBool(def bool) BoolOption {
+	return func(cfg *boolConfig) {
+		cfg.unknown = def
+		cfg.unknownOK = true
+	}
+}
+
+// ParseBool parses s as a boolean value. By default, "true", "yes", "on"
+// and "1" are true, and "false", "no", "off" and "0" are false, ignoring
+// case and surrounding whitespace. Other values are errors. Options change
+// the accepted spellings, and the treatment of other values.
+func ParseBool(s string, opts ...BoolOption) (bool, error) {
+	cfg := boolConfig{trues: defaultTrues, falses: defaultFalses}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	s = strings.TrimSpace(s)
+	for _, w := range cfg.trues {
+		if strings.EqualFold(s, w) {
+			return true, nil
+		}
+	}
+	for _, w := range cfg.falses {
+		if strings.EqualFold(s, w) {
+			return false, nil
+		}
+	}
+	if cfg.unknownOK {
+		return cfg.unknown, nil
+	}
+	return false, fmt.Errorf("invalid boolean %q: want one of %s",
+		s, strings.Join(append(append([]string(nil), cfg.trues...), cfg.falses...), ", "))
+}
+
+// Bool parses the value of the variable named by key as a boolean value,
+// as described by ParseBool. If the variable is not set, the error wraps
+// ErrNotFound, regardless of UnknownBool.
+func (m Map) Bool(key string, opts ...BoolOption) (bool, error) {
+	v, ok := m[key]
+	if !ok {
+		return false, notSet(key)
+	}
+	b, err := ParseBool(v, opts...)
+	if err != nil {
+		return false, invalid(key, err)
+	}
+	return b, nil
+}
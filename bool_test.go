@@ -0,0 +1,80 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env_test
+
+import (
+	"errors"
+	"testing"
+
+	"acln.ro/env"
+)
+
+func TestParseBool(t *testing.T) {
+	enabled := env.BoolWords([]string{"enabled"}, []string{"disabled"})
+	tests := []struct {
+		in   string
+		opts []env.BoolOption
+		want bool
+		err  bool
+	}{
+		{in: "true", want: true},
+		{in: "TRUE", want: true},
+		{in: " yes ", want: true},
+		{in: "On", want: true},
+		{in: "1", want: true},
+		{in: "false", want: false},
+		{in: "no", want: false},
+		{in: "OFF", want: false},
+		{in: "0", want: false},
+		{in: "", err: true},
+		{in: "t", err: true},
+		{in: "maybe", err: true},
+		{in: "True", opts: []env.BoolOption{env.StrictBool()}, want: true},
+		{in: "yes", opts: []env.BoolOption{env.StrictBool()}, err: true},
+		{in: "1", opts: []env.BoolOption{env.StrictBool()}, err: true},
+		{in: "Enabled", opts: []env.BoolOption{enabled}, want: true},
+		{in: "disabled", opts: []env.BoolOption{enabled}, want: false},
+		{in: "true", opts: []env.BoolOption{enabled}, err: true},
+		{in: "maybe", opts: []env.BoolOption{env.UnknownBool(true)}, want: true},
+		{in: "", opts: []env.BoolOption{env.UnknownBool(false)}, want: false},
+		{in: "no", opts: []env.BoolOption{env.UnknownBool(true)}, want: false},
+		{in: "yes", opts: []env.BoolOption{env.StrictBool(), env.UnknownBool(false)}, want: false},
+	}
+	for _, tt := range tests {
+		got, err := env.ParseBool(tt.in, tt.opts...)
+		if (err != nil) != tt.err {
+			t.Errorf("ParseBool(%q): got error %v, want error: %t", tt.in, err, tt.err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseBool(%q) = %t, want %t", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestMapBool(t *testing.T) {
+	m := env.Map{"DEBUG": "on", "VERBOSE": "sure"}
+	if b, err := m.Bool("DEBUG"); err != nil || !b {
+		t.Errorf("Bool(DEBUG) = %t, %v", b, err)
+	}
+	_, err := m.Bool("VERBOSE")
+	var verr *env.ValidationError
+	if !errors.As(err, &verr) || verr.Key != "VERBOSE" {
+		t.Errorf("Bool(VERBOSE): got %v, want ValidationError", err)
+	}
+	if _, err := m.Bool("UNSET", env.UnknownBool(true)); !errors.Is(err, env.ErrNotFound) {
+		t.Errorf("Bool(UNSET): got %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// byteUnits maps the units accepted by ParseBytes, in lower case, to
+// their sizes in bytes.
+var byteUnits = map[string]int64{
+	"": 1, "b": 1,
+
+	"k": 1e3, "kb": 1e3,
+	"m": 1e6, "mb": 1e6,
+	"g": 1e9, "gb": 1e9,
+	"t": 1e12, "tb": 1e12,
+	"p": 1e15, "pb": 1e15,
+	"e": 1e18, "eb": 1e18,
+
+	"ki": 1 << 10, "kib": 1 << 10,
+	"mi": 1 << 20, "mib": 1 << 20,
+	"gi": 1 << 30, "gib": 1 << 30,
+	"ti": 1 << 40, "tib": 1 << 40,
+	"pi": 1 << 50, "pib": 1 << 50,
+	"ei": 1 << 60, "eib": 1 << 60,
+}
+
+// ParseBytes parses a human-readable size, such as "512MiB", "2GB" or
+// "1.5 GiB", and returns it in bytes. The number may have a fractional
+// part, and may be followed by spaces and a unit. Units are SI (kB, MB,
+// GB, ..., powers of 1000) or IEC (KiB, MiB, GiB, ..., powers of 1024),
+// and are case insensitive. As in Kubernetes resource quantities, the
+// final B may be omitted, so "512Mi" is 512MiB, and "2G" is 2GB. A number
+// without a unit is in bytes. Fractional results are rounded down.
+func ParseBytes(s string) (int64, error) {
+	num := strings.TrimRight(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ")
+	unit := strings.ToLower(s[len(num):])
+	num = strings.TrimRight(num, " ")
+	mult, ok := byteUnits[unit]
+	if !ok {
+		return 0, fmt.Errorf("invalid size %q: unknown unit %q", s, s[len(s)-len(unit):])
+	}
+	if !isDecimal(num) {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	if !strings.Contains(num, ".") {
+		n, err := strconv.ParseInt(num, 10, 64)
+		if err != nil || n > math.MaxInt64/mult {
+			return 0, fmt.Errorf("invalid size %q: out of range", s)
+		}
+		return n * mult, nil
+	}
+	f, err := strconv.ParseFloat(num, 64)
+	if err != nil || f*float64(mult) >= math.MaxInt64 {
+		return 0, fmt.Errorf("invalid size %q: out of range", s)
+	}
+	return int64(f * float64(mult)), nil
+}
+
+// isDecimal reports whether s is a non-negative decimal number, with an
+// optional fractional part: digits, with at most one '.', and at least one
+// digit.
+func isDecimal(s string) bool {
+	digits, dots := 0, 0
+	for i := 0; i < len(s); i++ {
+		switch {
+		case '0' <= s[i] && s[i] <= '9':
+			digits++
+		case s[i] == '.':
+			dots++
+		default:
+			return false
+		}
+	}
+	return digits > 0 && dots <= 1
+}
+
+// Bytes parses the value of the variable named by key as a size in bytes,
+// as described by ParseBytes. If the variable is not set, the error wraps
+// ErrNotFound.
+func (m Map) Bytes(key string) (int64, error) {
+	v, ok := m[key]
+	if !ok {
+		return 0, notSet(key)
+	}
+	n, err := ParseBytes(v)
+	if err != nil {
+		return 0, invalid(key, err)
+	}
+	return n, nil
+}
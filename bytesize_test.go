@@ -0,0 +1,80 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env_test
+
+import (
+	"errors"
+	"testing"
+
+	"acln.ro/env"
+)
+
+func TestParseBytes(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int64
+	}{
+		{"0", 0},
+		{"1024", 1024},
+		{"512B", 512},
+		{"2GB", 2000000000},
+		{"2gb", 2000000000},
+		{"2G", 2000000000},
+		{"512MiB", 512 << 20},
+		{"512Mi", 512 << 20},
+		{"512 MiB", 512 << 20},
+		{"1.5GiB", 3 << 29},
+		{"1.5 kB", 1500},
+		{".5KiB", 512},
+		{"10KB", 10000},
+		{"1EiB", 1 << 60},
+		{"7EiB", 7 << 60},
+	}
+	for _, tt := range tests {
+		got, err := env.ParseBytes(tt.in)
+		if err != nil {
+			t.Errorf("ParseBytes(%q): %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseBytes(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseBytesErrors(t *testing.T) {
+	for _, in := range []string{
+		"", "MiB", "-1", "+1", "1..5GB", "1e3", "12XB", "1 2MB", " 1MB", "8EiB", "99999999999999999999",
+	} {
+		if n, err := env.ParseBytes(in); err == nil {
+			t.Errorf("ParseBytes(%q) = %d, want error", in, n)
+		}
+	}
+}
+
+func TestMapBytes(t *testing.T) {
+	m := env.Map{"CACHE_SIZE": "256MiB", "BAD": "lots"}
+	if n, err := m.Bytes("CACHE_SIZE"); err != nil || n != 256<<20 {
+		t.Errorf("Bytes(CACHE_SIZE) = %d, %v", n, err)
+	}
+	_, err := m.Bytes("BAD")
+	var verr *env.ValidationError
+	if !errors.As(err, &verr) || verr.Key != "BAD" {
+		t.Errorf("Bytes(BAD): got %v, want ValidationError", err)
+	}
+	if _, err := m.Bytes("UNSET"); !errors.Is(err, env.ErrNotFound) {
+		t.Errorf("Bytes(UNSET): got %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,61 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env
+
+import (
+	"os"
+	"sync"
+)
+
+var cache struct {
+	sync.Mutex
+	m Map
+}
+
+// Cached returns a Map of the process environment, like Variables, but
+// parses the process environment only on the first call, and on the first
+// call after the cache is invalidated. The cache is invalidated by
+// Invalidate, Setenv and Unsetenv.
+//
+// The returned Map is shared by all callers, and must not be modified.
+func Cached() Map {
+	cache.Lock()
+	defer cache.Unlock()
+	if cache.m == nil {
+		cache.m = Variables()
+	}
+	return cache.m
+}
+
+// Invalidate invalidates the cache used by Cached. Programs which modify
+// the process environment by means other than Setenv and Unsetenv must
+// call Invalidate afterwards.
+func Invalidate() {
+	cache.Lock()
+	cache.m = nil
+	cache.Unlock()
+}
+
+// Setenv calls os.Setenv, and invalidates the cache used by Cached.
+func Setenv(key, value string) error {
+	defer Invalidate()
+	return os.Setenv(key, value)
+}
+
+// Unsetenv calls os.Unsetenv, and invalidates the cache used by Cached.
+func Unsetenv(key string) error {
+	defer Invalidate()
+	return os.Unsetenv(key)
+}
@@ -0,0 +1,69 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env_test
+
+import (
+	"os"
+	"testing"
+
+	"acln.ro/env"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCached(t *testing.T) {
+	defer env.Invalidate()
+	defer os.Unsetenv("ENV_TEST_CACHED")
+
+	env.Invalidate()
+	if diff := cmp.Diff(env.Cached(), env.Variables()); diff != "" {
+		t.Errorf("Cached() differs from Variables(): %s", diff)
+	}
+
+	os.Setenv("ENV_TEST_CACHED", "stale")
+	if _, ok := env.Cached()["ENV_TEST_CACHED"]; ok {
+		t.Error("cache refreshed without invalidation")
+	}
+	env.Invalidate()
+	if got := env.Cached()["ENV_TEST_CACHED"]; got != "stale" {
+		t.Errorf("after Invalidate: ENV_TEST_CACHED = %q, want %q", got, "stale")
+	}
+
+	if err := env.Setenv("ENV_TEST_CACHED", "fresh"); err != nil {
+		t.Fatal(err)
+	}
+	if got := env.Cached()["ENV_TEST_CACHED"]; got != "fresh" {
+		t.Errorf("after Setenv: ENV_TEST_CACHED = %q, want %q", got, "fresh")
+	}
+
+	if err := env.Unsetenv("ENV_TEST_CACHED"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := env.Cached()["ENV_TEST_CACHED"]; ok {
+		t.Error("after Unsetenv: ENV_TEST_CACHED is still set")
+	}
+}
+
+func BenchmarkCached(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		env.Cached()
+	}
+}
+
+func BenchmarkVariables(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		env.Variables()
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env
+
+import (
+	"net"
+	"net/http"
+	"net/textproto"
+	"strconv"
+	"strings"
+)
+
+// FromRequest returns the CGI meta-variables describing r, as specified
+// by RFC 3875. Request headers are represented as HTTP_* variables, with
+// the exception of Proxy, which is omitted to guard against httpoxy
+// style attacks on the CGI program.
+//
+// Since r carries no information about the script being executed,
+// SCRIPT_NAME is empty, and PATH_INFO holds the entire request path.
+func FromRequest(r *http.Request) Map {
+	m := Map{
+		"GATEWAY_INTERFACE": "CGI/1.1",
+		"REQUEST_METHOD":    r.Method,
+		"QUERY_STRING":      r.URL.RawQuery,
+		"REQUEST_URI":       r.URL.RequestURI(),
+		"SCRIPT_NAME":       "",
+		"PATH_INFO":         r.URL.Path,
+		"SERVER_PROTOCOL":   r.Proto,
+	}
+	if r.RequestURI != "" {
+		m["REQUEST_URI"] = r.RequestURI
+	}
+	host, port, err := net.SplitHostPort(r.Host)
+	if err != nil {
+		host = r.Host
+		port = "80"
+		if r.TLS != nil {
+			port = "443"
+		}
+	}
+	m["SERVER_NAME"] = host
+	m["SERVER_PORT"] = port
+	if r.TLS != nil {
+		m["HTTPS"] = "on"
+	}
+	if rhost, rport, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		m["REMOTE_ADDR"] = rhost
+		m["REMOTE_HOST"] = rhost
+		m["REMOTE_PORT"] = rport
+	}
+	if r.ContentLength > 0 {
+		m["CONTENT_LENGTH"] = strconv.FormatInt(r.ContentLength, 10)
+	}
+	if ctype := r.Header.Get("Content-Type"); ctype != "" {
+		m["CONTENT_TYPE"] = ctype
+	}
+	if r.Host != "" {
+		m["HTTP_HOST"] = r.Host
+	}
+	for k, v := range r.Header {
+		k = strings.ToUpper(strings.Replace(k, "-", "_", -1))
+		if k == "PROXY" || k == "CONTENT_TYPE" || k == "CONTENT_LENGTH" {
+			continue
+		}
+		sep := ", "
+		if k == "COOKIE" {
+			sep = "; "
+		}
+		m["HTTP_"+k] = strings.Join(v, sep)
+	}
+	return m
+}
+
+// Header reconstructs request headers from the HTTP_* meta-variables in
+// m, as well as from CONTENT_TYPE and CONTENT_LENGTH. It is the reverse
+// of the header conversion performed by FromRequest.
+func Header(m Map) http.Header {
+	h := make(http.Header)
+	for k, v := range m {
+		switch {
+		case k == "CONTENT_TYPE" || k == "CONTENT_LENGTH":
+		case strings.HasPrefix(k, "HTTP_") && len(k) > len("HTTP_"):
+			k = k[len("HTTP_"):]
+		default:
+			continue
+		}
+		name := textproto.CanonicalMIMEHeaderKey(strings.Replace(k, "_", "-", -1))
+		h[name] = []string{v}
+	}
+	return h
+}
@@ -0,0 +1,83 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"acln.ro/env"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFromRequest(t *testing.T) {
+	body := strings.NewReader("hello")
+	r := httptest.NewRequest("POST", "http://example.com:8080/repo.git/info/refs?service=git-upload-pack", body)
+	r.RemoteAddr = "192.0.2.1:54321"
+	r.Header.Set("Content-Type", "text/plain")
+	r.Header.Set("User-Agent", "git/2.22")
+	r.Header.Set("Proxy", "http://evil")
+	r.Header.Add("Accept", "text/html")
+	r.Header.Add("Accept", "text/plain")
+	r.Header.Add("Cookie", "a=1")
+	r.Header.Add("Cookie", "b=2")
+
+	got := env.FromRequest(r)
+	want := env.Map{
+		"GATEWAY_INTERFACE": "CGI/1.1",
+		"REQUEST_METHOD":    "POST",
+		"QUERY_STRING":      "service=git-upload-pack",
+		"REQUEST_URI":       "http://example.com:8080/repo.git/info/refs?service=git-upload-pack",
+		"SCRIPT_NAME":       "",
+		"PATH_INFO":         "/repo.git/info/refs",
+		"SERVER_PROTOCOL":   "HTTP/1.1",
+		"SERVER_NAME":       "example.com",
+		"SERVER_PORT":       "8080",
+		"REMOTE_ADDR":       "192.0.2.1",
+		"REMOTE_HOST":       "192.0.2.1",
+		"REMOTE_PORT":       "54321",
+		"CONTENT_LENGTH":    "5",
+		"CONTENT_TYPE":      "text/plain",
+		"HTTP_HOST":         "example.com:8080",
+		"HTTP_USER_AGENT":   "git/2.22",
+		"HTTP_ACCEPT":       "text/html, text/plain",
+		"HTTP_COOKIE":       "a=1; b=2",
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("FromRequest: got %+v, want %+v: %s", got, want, diff)
+	}
+}
+
+func TestHeader(t *testing.T) {
+	m := env.Map{
+		"REQUEST_METHOD":  "GET",
+		"CONTENT_TYPE":    "application/json",
+		"HTTP_USER_AGENT": "curl/7.64",
+		"HTTP_X_API_KEY":  "k",
+		"HTTP_":           "bogus",
+	}
+	got := env.Header(m)
+	want := http.Header{
+		"Content-Type": {"application/json"},
+		"User-Agent":   {"curl/7.64"},
+		"X-Api-Key":    {"k"},
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("Header(%v) = %v, want %v: %s", m, got, want, diff)
+	}
+}
@@ -0,0 +1,156 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// An Op is an operation on an environment.
+type Op struct {
+	Unset bool   // if true, the operation unsets Key; otherwise, it sets it
+	Key   string // the variable the operation applies to
+	Value string // the value set by the operation, if Unset is false
+}
+
+// Apply applies op to m.
+func (op Op) Apply(m Map) {
+	if op.Unset {
+		delete(m, op.Key)
+	} else {
+		m[op.Key] = op.Value
+	}
+}
+
+func (op Op) String() string {
+	if op.Unset {
+		return "unset " + strconv.Quote(op.Key)
+	}
+	return "set " + strconv.Quote(op.Key) + " " + strconv.Quote(op.Value)
+}
+
+// A Changeset is an ordered sequence of operations on an environment.
+type Changeset []Op
+
+// Set records the setting of key to value.
+func (c *Changeset) Set(key, value string) {
+	*c = append(*c, Op{Key: key, Value: value})
+}
+
+// Unset records the unsetting of key.
+func (c *Changeset) Unset(key string) {
+	*c = append(*c, Op{Unset: true, Key: key})
+}
+
+// Apply applies the operations in c to m, in order.
+func (c Changeset) Apply(m Map) {
+	for _, op := range c {
+		op.Apply(m)
+	}
+}
+
+// Compose returns a Changeset equivalent to applying the specified
+// changesets in order.
+func Compose(changesets ...Changeset) Changeset {
+	var composed Changeset
+	for _, c := range changesets {
+		composed = append(composed, c...)
+	}
+	return composed
+}
+
+// MarshalText encodes c as text, with one operation per line. Keys and
+// values are quoted using Go syntax:
+//
+//	set "KEY" "value"
+//	unset "KEY"
+func (c Changeset) MarshalText() ([]byte, error) {
+	var buf bytes.Buffer
+	for _, op := range c {
+		buf.WriteString(op.String())
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalText decodes a Changeset encoded by MarshalText. Empty lines
+// and lines starting with '#' are ignored. Malformed lines are reported
+// together, as an ErrorList of *ParseError, and leave c unmodified.
+func (c *Changeset) UnmarshalText(text []byte) error {
+	var (
+		ops  Changeset
+		errs ErrorList
+	)
+	sc := bufio.NewScanner(bytes.NewReader(text))
+	for line := 1; sc.Scan(); line++ {
+		s := strings.TrimSpace(sc.Text())
+		if s == "" || strings.HasPrefix(s, "#") {
+			continue
+		}
+		op, err := parseOp(s)
+		if err != nil {
+			errs = append(errs, &ParseError{Line: line, Input: s, Err: err})
+			continue
+		}
+		ops = append(ops, op)
+	}
+	if err := sc.Err(); err != nil {
+		return err
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	*c = ops
+	return nil
+}
+
+func parseOp(s string) (Op, error) {
+	verb, args := s, ""
+	if i := strings.IndexByte(s, ' '); i != -1 {
+		verb, args = s[:i], s[i+1:]
+	}
+	var op Op
+	var quoted []string
+	switch verb {
+	case "set":
+		quoted = []string{"", ""}
+	case "unset":
+		op.Unset = true
+		quoted = []string{""}
+	default:
+		return Op{}, fmt.Errorf("unknown operation %q", verb)
+	}
+	for i := range quoted {
+		args = strings.TrimLeft(args, " ")
+		q, err := strconv.QuotedPrefix(args)
+		if err != nil {
+			return Op{}, fmt.Errorf("malformed %s operation %q", verb, s)
+		}
+		quoted[i], _ = strconv.Unquote(q)
+		args = args[len(q):]
+	}
+	if strings.TrimSpace(args) != "" {
+		return Op{}, fmt.Errorf("trailing data in %s operation %q", verb, s)
+	}
+	op.Key = quoted[0]
+	if !op.Unset {
+		op.Value = quoted[1]
+	}
+	return op, nil
+}
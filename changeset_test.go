@@ -0,0 +1,96 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env_test
+
+import (
+	"testing"
+
+	"acln.ro/env"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestChangesetApply(t *testing.T) {
+	var c env.Changeset
+	c.Set("FOO", "x")
+	c.Set("BAR", "y")
+	c.Unset("BAZ")
+	c.Set("FOO", "z")
+	m := env.Map{"BAZ": "old", "KEEP": "k"}
+	c.Apply(m)
+	want := env.Map{"FOO": "z", "BAR": "y", "KEEP": "k"}
+	if diff := cmp.Diff(m, want); diff != "" {
+		t.Errorf("Apply: got %v, want %v: %s", m, want, diff)
+	}
+}
+
+func TestCompose(t *testing.T) {
+	var c1, c2 env.Changeset
+	c1.Set("FOO", "x")
+	c1.Set("BAR", "y")
+	c2.Unset("FOO")
+	c2.Set("BAR", "z")
+	composed := env.Compose(c1, c2)
+
+	m1 := env.Map{"FOO": "orig"}
+	c1.Apply(m1)
+	c2.Apply(m1)
+	m2 := env.Map{"FOO": "orig"}
+	composed.Apply(m2)
+	if diff := cmp.Diff(m2, m1); diff != "" {
+		t.Errorf("composed changeset: got %v, want %v: %s", m2, m1, diff)
+	}
+}
+
+func TestChangesetText(t *testing.T) {
+	var c env.Changeset
+	c.Set("FOO", "hello world")
+	c.Set("MULTI", "line 1\nline 2 \"quoted\"")
+	c.Unset("BAR")
+	text, err := c.MarshalText()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `set "FOO" "hello world"
+set "MULTI" "line 1\nline 2 \"quoted\""
+unset "BAR"
+`
+	if diff := cmp.Diff(string(text), want); diff != "" {
+		t.Errorf("MarshalText: got %q, want %q: %s", text, want, diff)
+	}
+	var got env.Changeset
+	if err := got.UnmarshalText(append([]byte("# comment\n\n"), text...)); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(got, c); diff != "" {
+		t.Errorf("UnmarshalText: got %v, want %v: %s", got, c, diff)
+	}
+}
+
+func TestChangesetUnmarshalTextErrors(t *testing.T) {
+	tests := []string{
+		`frob "FOO"`,
+		`set "FOO"`,
+		`set FOO bar`,
+		`unset "FOO" "BAR"`,
+		`unset`,
+	}
+	for _, text := range tests {
+		var c env.Changeset
+		if err := c.UnmarshalText([]byte(text)); err == nil {
+			t.Errorf("UnmarshalText(%q) = %v, want error", text, c)
+		}
+	}
+}
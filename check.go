@@ -0,0 +1,93 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env
+
+import (
+	"errors"
+	"strings"
+)
+
+// A Check validates a relationship between variables, which cannot be
+// expressed by constraints on the individual variables, such as "TLS_CERT
+// and TLS_KEY must be set together".
+type Check struct {
+	// Keys lists the variables involved. Errors are attributed to all
+	// of them.
+	Keys []string
+
+	// Func reports whether m satisfies the relationship. m holds the
+	// default values from the Schema for variables which are not set.
+	Func func(m Map) error
+}
+
+// run runs the check against m, converting failures to a
+// *ValidationError.
+func (c Check) run(m Map) error {
+	err := c.Func(m)
+	if err == nil {
+		return nil
+	}
+	var key string
+	if len(c.Keys) > 0 {
+		key = c.Keys[0]
+	}
+	return &ValidationError{Key: key, Keys: c.Keys, Reason: err.Error(), Err: err}
+}
+
+// SetTogether returns a Check which requires the variables named by keys
+// to be either all set, or all unset.
+func SetTogether(keys ...string) Check {
+	return Check{
+		Keys: keys,
+		Func: func(m Map) error {
+			var set, unset []string
+			for _, k := range keys {
+				if _, ok := m[k]; ok {
+					set = append(set, k)
+				} else {
+					unset = append(unset, k)
+				}
+			}
+			if len(set) == 0 || len(unset) == 0 {
+				return nil
+			}
+			verb := " is"
+			if len(unset) > 1 {
+				verb = " are"
+			}
+			return errors.New("must be set together, but " + strings.Join(unset, ", ") + verb + " not set")
+		},
+	}
+}
+
+// Exclusive returns a Check which requires at most one of the variables
+// named by keys to be set.
+func Exclusive(keys ...string) Check {
+	return Check{
+		Keys: keys,
+		Func: func(m Map) error {
+			var set []string
+			for _, k := range keys {
+				if _, ok := m[k]; ok {
+					set = append(set, k)
+				}
+			}
+			if len(set) > 1 {
+				return errors.New("at most one may be set, but " + strings.Join(set, " and ") + " are")
+			}
+			return nil
+		},
+	}
+}
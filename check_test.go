@@ -0,0 +1,95 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env_test
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"acln.ro/env"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSchemaChecks(t *testing.T) {
+	schema := &env.Schema{
+		Vars: []env.Var{
+			{Key: "QUEUE_MODE", Default: "async"},
+			{Key: "RETRIES", Type: env.Int, Default: "3"},
+		},
+		Checks: []env.Check{
+			env.SetTogether("TLS_CERT", "TLS_KEY"),
+			env.Exclusive("DATABASE_URL", "DATABASE_HOST"),
+			{
+				Keys: []string{"RETRIES", "QUEUE_MODE"},
+				Func: func(m env.Map) error {
+					n, _ := strconv.Atoi(m["RETRIES"])
+					if m["QUEUE_MODE"] == "sync" && n != 0 {
+						return errors.New("RETRIES must be 0 when QUEUE_MODE=sync")
+					}
+					return nil
+				},
+			},
+		},
+	}
+	tests := []struct {
+		m    env.Map
+		want []string // messages of the errors
+	}{
+		{m: env.Map{}},
+		{m: env.Map{"TLS_CERT": "c", "TLS_KEY": "k", "QUEUE_MODE": "sync", "RETRIES": "0"}},
+		{
+			m:    env.Map{"TLS_KEY": "k"},
+			want: []string{"env: TLS_CERT, TLS_KEY: must be set together, but TLS_CERT is not set"},
+		},
+		{
+			m: env.Map{"DATABASE_URL": "u", "DATABASE_HOST": "h", "QUEUE_MODE": "sync"},
+			want: []string{
+				"env: DATABASE_URL, DATABASE_HOST: at most one may be set, but DATABASE_URL and DATABASE_HOST are",
+				"env: RETRIES, QUEUE_MODE: RETRIES must be 0 when QUEUE_MODE=sync",
+			},
+		},
+	}
+	for _, tt := range tests {
+		err := schema.Validate(tt.m)
+		var errs env.ErrorList
+		if err != nil && !errors.As(err, &errs) {
+			t.Fatalf("%v: got %v, want ErrorList", tt.m, err)
+		}
+		var got []string
+		for _, err := range errs {
+			got = append(got, err.Error())
+		}
+		if diff := cmp.Diff(tt.want, got); diff != "" {
+			t.Errorf("%v: (-want +got)\n%s", tt.m, diff)
+		}
+	}
+
+	err := schema.Validate(env.Map{"TLS_CERT": "c"})
+	var verr *env.ValidationError
+	if !errors.As(err, &verr) || verr.Key != "TLS_CERT" || !cmp.Equal(verr.Keys, []string{"TLS_CERT", "TLS_KEY"}) {
+		t.Errorf("got %#v, want error attributed to TLS_CERT and TLS_KEY", verr)
+	}
+}
+
+func TestSetTogetherMessage(t *testing.T) {
+	c := env.SetTogether("TLS_CERT", "TLS_KEY", "TLS_CA")
+	err := c.Func(env.Map{"TLS_CERT": "c"})
+	want := "must be set together, but TLS_KEY, TLS_CA are not set"
+	if err == nil || err.Error() != want {
+		t.Errorf("got %v, want %q", err, want)
+	}
+}
@@ -0,0 +1,182 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env
+
+import "strings"
+
+// CIInfo describes a continuous integration build, normalized across
+// providers. Fields which the provider does not expose are empty.
+type CIInfo struct {
+	Provider    string // e.g. "github-actions"
+	Branch      string // source branch, for pull requests too
+	Commit      string // full commit hash
+	PullRequest string // pull or merge request number
+	BuildURL    string // web page of the build
+}
+
+// ciProviders lists the supported CI providers, in the order in which
+// they are detected.
+var ciProviders = []struct {
+	name    string
+	detect  func(m Map) bool
+	extract func(m Map) CIInfo
+}{
+	{
+		name:   "github-actions",
+		detect: func(m Map) bool { return m["GITHUB_ACTIONS"] == "true" },
+		extract: func(m Map) CIInfo {
+			ci := CIInfo{
+				Branch: lookupAny(m, "GITHUB_HEAD_REF", "GITHUB_REF_NAME"),
+				Commit: m["GITHUB_SHA"],
+			}
+			if ref := m["GITHUB_REF"]; strings.HasPrefix(ref, "refs/pull/") {
+				ci.PullRequest = strings.TrimSuffix(strings.TrimPrefix(ref, "refs/pull/"), "/merge")
+			}
+			if m["GITHUB_REPOSITORY"] != "" && m["GITHUB_RUN_ID"] != "" {
+				server := m["GITHUB_SERVER_URL"]
+				if server == "" {
+					server = "https://github.com"
+				}
+				ci.BuildURL = server + "/" + m["GITHUB_REPOSITORY"] + "/actions/runs/" + m["GITHUB_RUN_ID"]
+			}
+			return ci
+		},
+	},
+	{
+		name:   "gitlab",
+		detect: func(m Map) bool { return m["GITLAB_CI"] != "" },
+		extract: func(m Map) CIInfo {
+			return CIInfo{
+				Branch:      lookupAny(m, "CI_MERGE_REQUEST_SOURCE_BRANCH_NAME", "CI_COMMIT_REF_NAME"),
+				Commit:      m["CI_COMMIT_SHA"],
+				PullRequest: m["CI_MERGE_REQUEST_IID"],
+				BuildURL:    lookupAny(m, "CI_JOB_URL", "CI_PIPELINE_URL"),
+			}
+		},
+	},
+	{
+		name:   "circleci",
+		detect: func(m Map) bool { return m["CIRCLECI"] == "true" },
+		extract: func(m Map) CIInfo {
+			ci := CIInfo{
+				Branch:      m["CIRCLE_BRANCH"],
+				Commit:      m["CIRCLE_SHA1"],
+				PullRequest: m["CIRCLE_PR_NUMBER"],
+				BuildURL:    m["CIRCLE_BUILD_URL"],
+			}
+			if pr := m["CIRCLE_PULL_REQUEST"]; ci.PullRequest == "" && pr != "" {
+				ci.PullRequest = pr[strings.LastIndexByte(pr, '/')+1:]
+			}
+			return ci
+		},
+	},
+	{
+		name:   "buildkite",
+		detect: func(m Map) bool { return m["BUILDKITE"] == "true" },
+		extract: func(m Map) CIInfo {
+			return CIInfo{
+				Branch:      m["BUILDKITE_BRANCH"],
+				Commit:      m["BUILDKITE_COMMIT"],
+				PullRequest: notFalse(m["BUILDKITE_PULL_REQUEST"]),
+				BuildURL:    m["BUILDKITE_BUILD_URL"],
+			}
+		},
+	},
+	{
+		name:   "travis",
+		detect: func(m Map) bool { return m["TRAVIS"] == "true" },
+		extract: func(m Map) CIInfo {
+			return CIInfo{
+				Branch:      lookupAny(m, "TRAVIS_PULL_REQUEST_BRANCH", "TRAVIS_BRANCH"),
+				Commit:      m["TRAVIS_COMMIT"],
+				PullRequest: notFalse(m["TRAVIS_PULL_REQUEST"]),
+				BuildURL:    m["TRAVIS_BUILD_WEB_URL"],
+			}
+		},
+	},
+	{
+		name:   "azure-pipelines",
+		detect: func(m Map) bool { return strings.EqualFold(m["TF_BUILD"], "true") },
+		extract: func(m Map) CIInfo {
+			ci := CIInfo{
+				Branch:      lookupAny(m, "SYSTEM_PULLREQUEST_SOURCEBRANCH", "BUILD_SOURCEBRANCHNAME"),
+				Commit:      m["BUILD_SOURCEVERSION"],
+				PullRequest: m["SYSTEM_PULLREQUEST_PULLREQUESTNUMBER"],
+			}
+			ci.Branch = strings.TrimPrefix(ci.Branch, "refs/heads/")
+			if m["SYSTEM_COLLECTIONURI"] != "" && m["BUILD_BUILDID"] != "" {
+				ci.BuildURL = m["SYSTEM_COLLECTIONURI"] + m["SYSTEM_TEAMPROJECT"] + "/_build/results?buildId=" + m["BUILD_BUILDID"]
+			}
+			return ci
+		},
+	},
+	{
+		name:   "bitbucket",
+		detect: func(m Map) bool { return m["BITBUCKET_BUILD_NUMBER"] != "" },
+		extract: func(m Map) CIInfo {
+			ci := CIInfo{
+				Branch:      m["BITBUCKET_BRANCH"],
+				Commit:      m["BITBUCKET_COMMIT"],
+				PullRequest: m["BITBUCKET_PR_ID"],
+			}
+			if origin := m["BITBUCKET_GIT_HTTP_ORIGIN"]; origin != "" {
+				ci.BuildURL = origin + "/addon/pipelines/home#!/results/" + m["BITBUCKET_BUILD_NUMBER"]
+			}
+			return ci
+		},
+	},
+	{
+		name:   "jenkins",
+		detect: func(m Map) bool { return m["JENKINS_URL"] != "" },
+		extract: func(m Map) CIInfo {
+			return CIInfo{
+				Branch:      lookupAny(m, "CHANGE_BRANCH", "BRANCH_NAME", "GIT_BRANCH"),
+				Commit:      m["GIT_COMMIT"],
+				PullRequest: m["CHANGE_ID"],
+				BuildURL:    m["BUILD_URL"],
+			}
+		},
+	},
+}
+
+// notFalse returns s, or the empty string if s is "false", which some
+// providers use to indicate that a build is not for a pull request.
+func notFalse(s string) string {
+	if s == "false" {
+		return ""
+	}
+	return s
+}
+
+// DetectCI reports whether m describes a continuous integration build,
+// and if so, identifies the provider and extracts information about the
+// build. Supported providers are GitHub Actions, GitLab CI/CD, CircleCI,
+// Buildkite, Travis CI, Azure Pipelines, Bitbucket Pipelines and Jenkins.
+// Other providers are detected through the conventional CI variable, and
+// reported with the provider "unknown", and no further information.
+func DetectCI(m Map) (CIInfo, bool) {
+	for _, p := range ciProviders {
+		if p.detect(m) {
+			ci := p.extract(m)
+			ci.Provider = p.name
+			return ci, true
+		}
+	}
+	switch strings.ToLower(m["CI"]) {
+	case "", "0", "false":
+		return CIInfo{}, false
+	}
+	return CIInfo{Provider: "unknown"}, true
+}
@@ -0,0 +1,140 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env_test
+
+import (
+	"testing"
+
+	"acln.ro/env"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDetectCI(t *testing.T) {
+	tests := []struct {
+		name string
+		m    env.Map
+		want env.CIInfo
+		ok   bool
+	}{
+		{
+			name: "None",
+			m:    env.Map{"HOME": "/home/u"},
+		},
+		{
+			name: "GitHubPullRequest",
+			m: env.Map{
+				"CI":                "true",
+				"GITHUB_ACTIONS":    "true",
+				"GITHUB_HEAD_REF":   "feature",
+				"GITHUB_REF_NAME":   "42/merge",
+				"GITHUB_REF":        "refs/pull/42/merge",
+				"GITHUB_SHA":        "abc123",
+				"GITHUB_SERVER_URL": "https://github.com",
+				"GITHUB_REPOSITORY": "acln0/env",
+				"GITHUB_RUN_ID":     "7",
+			},
+			want: env.CIInfo{
+				Provider:    "github-actions",
+				Branch:      "feature",
+				Commit:      "abc123",
+				PullRequest: "42",
+				BuildURL:    "https://github.com/acln0/env/actions/runs/7",
+			},
+			ok: true,
+		},
+		{
+			name: "GitLab",
+			m: env.Map{
+				"GITLAB_CI":          "true",
+				"CI_COMMIT_REF_NAME": "main",
+				"CI_COMMIT_SHA":      "def456",
+				"CI_JOB_URL":         "https://gitlab.com/g/p/-/jobs/1",
+			},
+			want: env.CIInfo{
+				Provider: "gitlab",
+				Branch:   "main",
+				Commit:   "def456",
+				BuildURL: "https://gitlab.com/g/p/-/jobs/1",
+			},
+			ok: true,
+		},
+		{
+			name: "CircleCI",
+			m: env.Map{
+				"CIRCLECI":            "true",
+				"CIRCLE_BRANCH":       "fix",
+				"CIRCLE_SHA1":         "789",
+				"CIRCLE_PULL_REQUEST": "https://github.com/o/r/pull/12",
+			},
+			want: env.CIInfo{
+				Provider:    "circleci",
+				Branch:      "fix",
+				Commit:      "789",
+				PullRequest: "12",
+			},
+			ok: true,
+		},
+		{
+			name: "BuildkiteNoPullRequest",
+			m: env.Map{
+				"BUILDKITE":              "true",
+				"BUILDKITE_BRANCH":       "main",
+				"BUILDKITE_PULL_REQUEST": "false",
+			},
+			want: env.CIInfo{Provider: "buildkite", Branch: "main"},
+			ok:   true,
+		},
+		{
+			name: "Jenkins",
+			m: env.Map{
+				"JENKINS_URL": "https://ci.example.com/",
+				"BRANCH_NAME": "PR-3",
+				"CHANGE_ID":   "3",
+				"GIT_COMMIT":  "aaa",
+				"BUILD_URL":   "https://ci.example.com/job/x/1/",
+			},
+			want: env.CIInfo{
+				Provider:    "jenkins",
+				Branch:      "PR-3",
+				Commit:      "aaa",
+				PullRequest: "3",
+				BuildURL:    "https://ci.example.com/job/x/1/",
+			},
+			ok: true,
+		},
+		{
+			name: "Unknown",
+			m:    env.Map{"CI": "1"},
+			want: env.CIInfo{Provider: "unknown"},
+			ok:   true,
+		},
+		{
+			name: "CIFalse",
+			m:    env.Map{"CI": "false"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := env.DetectCI(tt.m)
+			if ok != tt.ok {
+				t.Fatalf("ok = %t, want %t", ok, tt.ok)
+			}
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Errorf("got %+v, want %+v: %s", got, tt.want, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,232 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"acln.ro/env"
+)
+
+// Severity is the severity of a Finding.
+type Severity string
+
+// Severities.
+const (
+	Error   Severity = "error"
+	Warning Severity = "warning"
+)
+
+// Checks which produce findings.
+const (
+	checkParse    = "parse"
+	checkMissing  = "missing"
+	checkType     = "type"
+	checkRelation = "relation"
+	checkUnknown  = "unknown"
+	checkSecret   = "secret"
+)
+
+// A Finding is a problem found in an environment.
+type Finding struct {
+	Source   string   `json:"source"`
+	Line     int      `json:"line,omitempty"`
+	Key      string   `json:"key,omitempty"`
+	Check    string   `json:"check"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+}
+
+func (f Finding) String() string {
+	pos := f.Source
+	if f.Line > 0 {
+		pos = fmt.Sprintf("%s:%d", f.Source, f.Line)
+	}
+	if f.Key != "" {
+		return fmt.Sprintf("%s: %s: %s: %s", pos, f.Severity, f.Key, f.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", pos, f.Severity, f.Message)
+}
+
+// lintFile lints the environment file at path.
+func lintFile(schema *env.Schema, path string) []Finding {
+	m, err := env.ReadDotenvFile(path)
+	if err != nil {
+		return parseFindings(path, err)
+	}
+	findings := validate(schema, path, m)
+	known := make(map[string]env.Var)
+	for _, v := range schema.Vars {
+		known[v.Key] = v
+	}
+	plain := make(env.Map)
+	for k, v := range m {
+		if isPlaintext(v) {
+			plain[k] = v
+		}
+	}
+	likely := make(map[string]env.Finding)
+	for _, f := range env.FindLikelySecrets(plain) {
+		likely[f.Key] = f
+	}
+	for _, k := range sortedKeys(m) {
+		v, ok := known[k]
+		if !ok {
+			findings = append(findings, Finding{
+				Source:   path,
+				Key:      k,
+				Check:    checkUnknown,
+				Severity: Warning,
+				Message:  "variable is not described by the schema",
+			})
+		}
+		if _, isPlain := plain[k]; !isPlain {
+			continue
+		}
+		var msg string
+		if f, ok := likely[k]; ok {
+			msg = "likely secret is stored in plain text: " + f.Reason
+		}
+		if v.Secret {
+			msg = "secret value is stored in plain text"
+		}
+		if msg != "" {
+			findings = append(findings, Finding{
+				Source:   path,
+				Key:      k,
+				Check:    checkSecret,
+				Severity: Warning,
+				Message:  msg,
+			})
+		}
+	}
+	return findings
+}
+
+// lintProcess lints the process environment m. Variables not described
+// by the schema are not reported, since process environments contain
+// many variables unrelated to the program.
+func lintProcess(schema *env.Schema, m env.Map) []Finding {
+	return validate(schema, "process", m)
+}
+
+// validate validates m against schema, converting the errors to findings.
+func validate(schema *env.Schema, source string, m env.Map) []Finding {
+	err := schema.Validate(m)
+	if err == nil {
+		return nil
+	}
+	var errs env.ErrorList
+	if !errors.As(err, &errs) {
+		errs = env.ErrorList{err}
+	}
+	var findings []Finding
+	for _, err := range errs {
+		f := Finding{
+			Source:   source,
+			Check:    checkType,
+			Severity: Error,
+			Message:  err.Error(),
+		}
+		var verr *env.ValidationError
+		if errors.As(err, &verr) {
+			f.Key = verr.Key
+			if len(verr.Keys) > 0 {
+				f.Check = checkRelation
+			}
+			if len(verr.Keys) > 1 {
+				f.Key = strings.Join(verr.Keys, ", ")
+			}
+			f.Message = verr.Reason
+		}
+		if errors.Is(err, env.ErrNotFound) {
+			f.Check = checkMissing
+		}
+		findings = append(findings, f)
+	}
+	return findings
+}
+
+// parseFindings converts errors from reading the file at path to findings.
+func parseFindings(path string, err error) []Finding {
+	var errs env.ErrorList
+	if !errors.As(err, &errs) {
+		return []Finding{{
+			Source:   path,
+			Check:    checkParse,
+			Severity: Error,
+			Message:  err.Error(),
+		}}
+	}
+	findings := make([]Finding, 0, len(errs))
+	for _, err := range errs {
+		f := Finding{
+			Source:   path,
+			Check:    checkParse,
+			Severity: Error,
+			Message:  err.Error(),
+		}
+		var perr *env.ParseError
+		if errors.As(err, &perr) {
+			f.Line = perr.Line
+			if perr.Err != nil {
+				f.Message = perr.Err.Error()
+			}
+		}
+		findings = append(findings, f)
+	}
+	return findings
+}
+
+// isPlaintext reports whether v is a non-empty value stored in plain
+// text, rather than encrypted by env.Key.EncryptValue, or a reference to
+// be resolved by an env.Resolver ("ref+...").
+func isPlaintext(v string) bool {
+	return v != "" && !strings.HasPrefix(v, env.EncryptedValuePrefix) && !strings.HasPrefix(v, "ref+")
+}
+
+func sortedKeys(m env.Map) []string {
+	var keys []string
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func writeText(w io.Writer, findings []Finding) error {
+	for _, f := range findings {
+		if _, err := fmt.Fprintln(w, f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeJSON(w io.Writer, findings []Finding) error {
+	if findings == nil {
+		findings = []Finding{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+	return enc.Encode(struct {
+		Findings []Finding `json:"findings"`
+	}{findings})
+}
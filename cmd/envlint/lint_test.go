@@ -0,0 +1,110 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"acln.ro/env"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+var testSchema = &env.Schema{
+	Vars: []env.Var{
+		{Key: "DATABASE_URL", Type: env.URLType, Required: true},
+		{Key: "PORT", Type: env.Int},
+		{Key: "SIGNING_KEY", Secret: true},
+		{Key: "API_TOKEN"},
+	},
+}
+
+func TestLintFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "envlint")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, ".env")
+	data := "PORT=eighty\nSIGNING_KEY=hunter2\nAPI_TOKEN=ref+file:///run/secrets/token\nEXTRA=1\n" +
+		"DEPLOY=ghp_0123456789abcdefghij\nSEALED_KEY=" + env.EncryptedValuePrefix + "c2VhbGVk\n"
+	if err := ioutil.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, f := range lintFile(testSchema, path) {
+		got = append(got, f.Key+" "+f.Check+" "+string(f.Severity))
+	}
+	want := []string{
+		"DATABASE_URL missing error",
+		"PORT type error",
+		"DEPLOY unknown warning",
+		"DEPLOY secret warning",
+		"EXTRA unknown warning",
+		"SEALED_KEY unknown warning",
+		"SIGNING_KEY secret warning",
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("lintFile: %s", diff)
+	}
+}
+
+func TestLintFileParseErrors(t *testing.T) {
+	dir, err := ioutil.TempDir("", "envlint")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, ".env")
+	if err := ioutil.WriteFile(path, []byte("A=1\nBROKEN\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	findings := lintFile(testSchema, path)
+	if len(findings) != 1 || findings[0].Line != 2 || findings[0].Check != checkParse {
+		t.Errorf("got %v, want a parse error on line 2", findings)
+	}
+}
+
+func TestLintRelation(t *testing.T) {
+	schema := &env.Schema{
+		Vars:   []env.Var{{Key: "TLS_CERT"}, {Key: "TLS_KEY"}},
+		Checks: []env.Check{env.SetTogether("TLS_CERT", "TLS_KEY")},
+	}
+	findings := lintProcess(schema, env.Map{"TLS_CERT": "cert.pem"})
+	if len(findings) != 1 || findings[0].Check != checkRelation || findings[0].Key != "TLS_CERT, TLS_KEY" {
+		t.Errorf("got %v, want a relation error for TLS_CERT, TLS_KEY", findings)
+	}
+}
+
+func TestLintProcess(t *testing.T) {
+	findings := lintProcess(testSchema, env.Map{"DATABASE_URL": "postgres://db", "HOME": "/root"})
+	if len(findings) != 0 {
+		t.Errorf("got %v, want no findings", findings)
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	sb := new(strings.Builder)
+	if err := writeJSON(sb, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := sb.String(), "{\n\t\"findings\": []\n}\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+// Command envlint checks environment files, and optionally the process
+// environment, against a schema.
+//
+// Usage:
+//
+//	envlint -schema schema.json [-process] [-json] [-strict] [file ...]
+//
+// The schema is the JSON encoding of an env.Schema:
+//
+//	{"vars": [
+//		{"key": "DATABASE_URL", "type": "url", "required": true, "secret": true},
+//		{"key": "PORT", "type": "int", "default": "8080"}
+//	]}
+//
+// envlint reports missing required variables, values which are not valid
+// for their type, failed relations between variables, and malformed lines
+// as errors. In files, it also reports variables not described by the
+// schema, and secrets stored in plain text, as warnings. Secrets are
+// variables marked as such in the schema, or which env.FindLikelySecrets
+// reports.
+//
+// With -json, findings are written to standard output as a JSON object,
+// for consumption by CI tooling. envlint exits with status 1 if there are
+// errors, or warnings if -strict is set, and with status 2 on usage
+// errors.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"acln.ro/env"
+)
+
+func main() {
+	var (
+		schemaPath = flag.String("schema", "", "path to the JSON schema")
+		process    = flag.Bool("process", false, "lint the process environment")
+		jsonOutput = flag.Bool("json", false, "write findings as JSON")
+		strict     = flag.Bool("strict", false, "treat warnings as errors")
+	)
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: envlint -schema schema.json [-process] [-json] [-strict] [file ...]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if *schemaPath == "" || (flag.NArg() == 0 && !*process) {
+		flag.Usage()
+		os.Exit(2)
+	}
+	schema, err := readSchema(*schemaPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "envlint: %v\n", err)
+		os.Exit(2)
+	}
+
+	var findings []Finding
+	for _, path := range flag.Args() {
+		findings = append(findings, lintFile(schema, path)...)
+	}
+	if *process {
+		findings = append(findings, lintProcess(schema, env.Variables())...)
+	}
+
+	if *jsonOutput {
+		err = writeJSON(os.Stdout, findings)
+	} else {
+		err = writeText(os.Stdout, findings)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "envlint: %v\n", err)
+		os.Exit(2)
+	}
+	for _, f := range findings {
+		if f.Severity == Error || *strict {
+			os.Exit(1)
+		}
+	}
+}
+
+func readSchema(path string) (*env.Schema, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	schema := new(env.Schema)
+	if err := json.Unmarshal(data, schema); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return schema, nil
+}
@@ -0,0 +1,88 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env
+
+// Color is a decision about colored output.
+type Color int
+
+// Color decisions.
+const (
+	// ColorAuto indicates that colored output should be used if the
+	// output is a terminal.
+	ColorAuto Color = iota
+
+	// ColorNever indicates that colored output must not be used.
+	ColorNever
+
+	// ColorAlways indicates that colored output should be used, even if
+	// the output is not a terminal.
+	ColorAlways
+)
+
+// Enabled reports whether colored output should be used, given whether
+// the output is a terminal.
+func (c Color) Enabled(terminal bool) bool {
+	switch c {
+	case ColorNever:
+		return false
+	case ColorAlways:
+		return true
+	default:
+		return terminal
+	}
+}
+
+func (c Color) String() string {
+	switch c {
+	case ColorNever:
+		return "never"
+	case ColorAlways:
+		return "always"
+	default:
+		return "auto"
+	}
+}
+
+// ColorPolicy decides whether colored output should be used, according to
+// the conventions in m. In order of precedence:
+//
+//	NO_COLOR set to a non-empty value disables color (https://no-color.org)
+//	CLICOLOR_FORCE set to a value other than "0" forces color
+//	FORCE_COLOR set to "0" or "false" disables color, and otherwise forces it
+//	TERM=dumb disables color
+//	CLICOLOR=0 disables color
+//
+// If none of these apply, ColorPolicy returns ColorAuto.
+func ColorPolicy(m Map) Color {
+	if m["NO_COLOR"] != "" {
+		return ColorNever
+	}
+	if v, ok := m["CLICOLOR_FORCE"]; ok && v != "0" {
+		return ColorAlways
+	}
+	if v, ok := m["FORCE_COLOR"]; ok {
+		if v == "0" || v == "false" {
+			return ColorNever
+		}
+		return ColorAlways
+	}
+	if m["TERM"] == "dumb" {
+		return ColorNever
+	}
+	if m["CLICOLOR"] == "0" {
+		return ColorNever
+	}
+	return ColorAuto
+}
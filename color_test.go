@@ -0,0 +1,66 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env_test
+
+import (
+	"testing"
+
+	"acln.ro/env"
+)
+
+func TestColorPolicy(t *testing.T) {
+	tests := []struct {
+		name string
+		m    env.Map
+		want env.Color
+	}{
+		{"Empty", env.Map{}, env.ColorAuto},
+		{"NoColor", env.Map{"NO_COLOR": "1", "CLICOLOR_FORCE": "1"}, env.ColorNever},
+		{"NoColorEmpty", env.Map{"NO_COLOR": ""}, env.ColorAuto},
+		{"CLIColorForce", env.Map{"CLICOLOR_FORCE": "1", "TERM": "dumb"}, env.ColorAlways},
+		{"CLIColorForceZero", env.Map{"CLICOLOR_FORCE": "0"}, env.ColorAuto},
+		{"ForceColor", env.Map{"FORCE_COLOR": "", "CLICOLOR": "0"}, env.ColorAlways},
+		{"ForceColorZero", env.Map{"FORCE_COLOR": "0"}, env.ColorNever},
+		{"ForceColorFalse", env.Map{"FORCE_COLOR": "false"}, env.ColorNever},
+		{"DumbTerminal", env.Map{"TERM": "dumb"}, env.ColorNever},
+		{"CLIColorZero", env.Map{"CLICOLOR": "0"}, env.ColorNever},
+		{"CLIColor", env.Map{"CLICOLOR": "1", "TERM": "xterm"}, env.ColorAuto},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := env.ColorPolicy(tt.m); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestColorEnabled(t *testing.T) {
+	tests := []struct {
+		c        env.Color
+		terminal bool
+		want     bool
+	}{
+		{env.ColorAuto, true, true},
+		{env.ColorAuto, false, false},
+		{env.ColorNever, true, false},
+		{env.ColorAlways, false, true},
+	}
+	for _, tt := range tests {
+		if got := tt.c.Enabled(tt.terminal); got != tt.want {
+			t.Errorf("%v.Enabled(%t) = %t, want %t", tt.c, tt.terminal, got, tt.want)
+		}
+	}
+}
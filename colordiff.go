@@ -0,0 +1,97 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"sort"
+)
+
+// ANSI escape sequences used by Colorized.
+const (
+	ansiRed    = "\x1b[31m"
+	ansiGreen  = "\x1b[32m"
+	ansiYellow = "\x1b[33m"
+	ansiReset  = "\x1b[0m"
+)
+
+// Colorized writes d to w for review by a person, one variable per line,
+// sorted by key. Variables only in N are prefixed by '+' and printed in
+// green, variables only in M are prefixed by '-' and printed in red, and
+// changed variables are prefixed by '~', with the old value in red and the
+// new value in green, as in "~ IMAGE=app:1.4 -> app:1.5". Keys and
+// values which would not display faithfully, such as those holding
+// escape sequences or newlines, are quoted, as by Table.
+//
+// Colors are used as decided by ColorPolicy for the environment of the
+// current process, which honors NO_COLOR. Under ColorAuto, colors are used
+// if w is a terminal.
+func (d Diff) Colorized(w io.Writer) error {
+	return d.ColorizedIn(w, OS)
+}
+
+// ColorizedIn is like Colorized, but decides whether to use colors by
+// the ColorPolicy for e, rather than for the environment of the current
+// process.
+func (d Diff) ColorizedIn(w io.Writer, e Environment) error {
+	return d.colorized(w, ColorPolicy(e.All()).Enabled(isTerminal(w)))
+}
+
+func (d Diff) colorized(w io.Writer, color bool) error {
+	paint := func(code, s string) string {
+		if !color {
+			return s
+		}
+		return code + s + ansiReset
+	}
+	changes := make(map[string]Change, len(d.Changes))
+	var keys []string
+	for _, c := range d.Changes {
+		changes[c.Key] = c
+		keys = append(keys, c.Key)
+	}
+	for k := range d.OnlyInM {
+		keys = append(keys, k)
+	}
+	for k := range d.OnlyInN {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	bw := bufio.NewWriter(w)
+	for _, k := range keys {
+		qk := tableCell(k)
+		if c, ok := changes[k]; ok {
+			bw.WriteString(paint(ansiYellow, "~ "+qk) + "=" + paint(ansiRed, tableCell(c.MValue)) + " -> " + paint(ansiGreen, tableCell(c.NValue)) + "\n")
+		} else if v, ok := d.OnlyInM[k]; ok {
+			bw.WriteString(paint(ansiRed, "- "+qk+"="+tableCell(v)) + "\n")
+		} else {
+			bw.WriteString(paint(ansiGreen, "+ "+qk+"="+tableCell(d.OnlyInN[k])) + "\n")
+		}
+	}
+	return bw.Flush()
+}
+
+// isTerminal reports whether w is a terminal, as far as can be told
+// without system-specific calls: whether it is a character device.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,107 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env_test
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"acln.ro/env"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestColorized(t *testing.T) {
+	m := env.Map{"DEBUG": "1", "IMAGE": "app:1.4", "SAME": "x"}
+	n := env.Map{"IMAGE": "app:1.5", "REPLICAS": "3", "SAME": "x"}
+	d := m.Diff(n)
+	tests := []struct {
+		color bool
+		want  string
+	}{
+		{
+			color: false,
+			want: "- DEBUG=1\n" +
+				"~ IMAGE=app:1.4 -> app:1.5\n" +
+				"+ REPLICAS=3\n",
+		},
+		{
+			color: true,
+			want: "\x1b[31m- DEBUG=1\x1b[0m\n" +
+				"\x1b[33m~ IMAGE\x1b[0m=\x1b[31mapp:1.4\x1b[0m -> \x1b[32mapp:1.5\x1b[0m\n" +
+				"\x1b[32m+ REPLICAS=3\x1b[0m\n",
+		},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		if err := env.Colorized(d, &buf, tt.color); err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(buf.String(), tt.want); diff != "" {
+			t.Errorf("color %t: %s", tt.color, diff)
+		}
+	}
+}
+
+func TestColorizedQuoting(t *testing.T) {
+	m := env.Map{"PS1": "\x1b[31m$ ", "MOTD": "hi"}
+	n := env.Map{"MOTD": "hi\nthere", "SPACE": " x"}
+	var buf bytes.Buffer
+	if err := env.Colorized(m.Diff(n), &buf, false); err != nil {
+		t.Fatal(err)
+	}
+	want := "~ MOTD=hi -> \"hi\\nthere\"\n" +
+		"- PS1=\"\\x1b[31m$ \"\n" +
+		"+ SPACE=\" x\"\n"
+	if diff := cmp.Diff(buf.String(), want); diff != "" {
+		t.Errorf("Colorized: %s", diff)
+	}
+}
+
+func TestColorizedNoColor(t *testing.T) {
+	defer restoreEnv(env.Variables())
+	os.Setenv("NO_COLOR", "1")
+	os.Setenv("CLICOLOR_FORCE", "1")
+	d := env.Map{}.Diff(env.Map{"A": "1"})
+	var buf bytes.Buffer
+	if err := d.Colorized(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := buf.String(), "+ A=1\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	os.Unsetenv("NO_COLOR")
+	buf.Reset()
+	if err := d.Colorized(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := buf.String(), "\x1b[32m+ A=1\x1b[0m\n"; got != want {
+		t.Errorf("with CLICOLOR_FORCE: got %q, want %q", got, want)
+	}
+}
+
+func TestColorizedIn(t *testing.T) {
+	defer restoreEnv(env.Variables())
+	os.Setenv("NO_COLOR", "1")
+	d := env.Map{}.Diff(env.Map{"A": "1"})
+	var buf bytes.Buffer
+	if err := d.ColorizedIn(&buf, env.Fake(env.Map{"CLICOLOR_FORCE": "1"})); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := buf.String(), "\x1b[32m+ A=1\x1b[0m\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env
+
+import "sort"
+
+// Compare compares several named environments, such as those of a number
+// of deployments. The result holds a row for each key set in at least one
+// of the environments.
+func Compare(maps map[string]Map) Comparison {
+	c := Comparison{Names: make([]string, 0, len(maps))}
+	rows := make(map[string]*KeyComparison)
+	for name, m := range maps {
+		c.Names = append(c.Names, name)
+		for k, v := range m {
+			row, ok := rows[k]
+			if !ok {
+				row = &KeyComparison{Key: k, Values: make(map[string]string)}
+				rows[k] = row
+			}
+			row.Values[name] = v
+		}
+	}
+	sort.Strings(c.Names)
+	for _, row := range rows {
+		for _, name := range c.Names {
+			if _, ok := row.Values[name]; !ok {
+				row.Missing = append(row.Missing, name)
+			}
+		}
+		c.Keys = append(c.Keys, *row)
+	}
+	sort.Slice(c.Keys, func(i, j int) bool {
+		return c.Keys[i].Key < c.Keys[j].Key
+	})
+	return c
+}
+
+// Comparison is a per-key matrix of the values of several environments.
+type Comparison struct {
+	// Names holds the names of the environments, sorted.
+	Names []string
+
+	// Keys holds the comparison for each key, sorted by key.
+	Keys []KeyComparison
+}
+
+// Differing returns the comparisons for keys which are either missing
+// from some environments, or have different values in some environments.
+func (c Comparison) Differing() []KeyComparison {
+	var diffs []KeyComparison
+	for _, kc := range c.Keys {
+		if !kc.Consistent() {
+			diffs = append(diffs, kc)
+		}
+	}
+	return diffs
+}
+
+// KeyComparison compares the value of a variable across environments.
+type KeyComparison struct {
+	Key string
+
+	// Values maps the names of the environments in which the variable
+	// is set to its value.
+	Values map[string]string
+
+	// Missing holds the names of the environments in which the variable
+	// is not set, sorted.
+	Missing []string
+}
+
+// Consistent reports whether the variable is set to the same value in all
+// environments.
+func (kc KeyComparison) Consistent() bool {
+	if len(kc.Missing) > 0 {
+		return false
+	}
+	first := true
+	var val string
+	for _, v := range kc.Values {
+		if first {
+			val, first = v, false
+		} else if v != val {
+			return false
+		}
+	}
+	return true
+}
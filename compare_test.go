@@ -0,0 +1,70 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env_test
+
+import (
+	"testing"
+
+	"acln.ro/env"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCompare(t *testing.T) {
+	c := env.Compare(map[string]env.Map{
+		"prod":    {"LOG_LEVEL": "info", "REPLICAS": "5", "REGION": "eu"},
+		"staging": {"LOG_LEVEL": "info", "REPLICAS": "2", "REGION": "eu"},
+		"dev":     {"LOG_LEVEL": "info", "REPLICAS": "1", "DEBUG": "1", "REGION": "eu"},
+	})
+	want := env.Comparison{
+		Names: []string{"dev", "prod", "staging"},
+		Keys: []env.KeyComparison{
+			{
+				Key:     "DEBUG",
+				Values:  map[string]string{"dev": "1"},
+				Missing: []string{"prod", "staging"},
+			},
+			{
+				Key:    "LOG_LEVEL",
+				Values: map[string]string{"dev": "info", "prod": "info", "staging": "info"},
+			},
+			{
+				Key:    "REGION",
+				Values: map[string]string{"dev": "eu", "prod": "eu", "staging": "eu"},
+			},
+			{
+				Key:    "REPLICAS",
+				Values: map[string]string{"dev": "1", "prod": "5", "staging": "2"},
+			},
+		},
+	}
+	if diff := cmp.Diff(c, want); diff != "" {
+		t.Errorf("Compare: got %+v, want %+v: %s", c, want, diff)
+	}
+	var differing []string
+	for _, kc := range c.Differing() {
+		differing = append(differing, kc.Key)
+	}
+	if diff := cmp.Diff(differing, []string{"DEBUG", "REPLICAS"}); diff != "" {
+		t.Errorf("Differing() keys = %q: %s", differing, diff)
+	}
+}
+
+func TestCompareEmpty(t *testing.T) {
+	c := env.Compare(nil)
+	if len(c.Names) != 0 || len(c.Keys) != 0 {
+		t.Errorf("Compare(nil) = %+v, want empty comparison", c)
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env
+
+import (
+	"errors"
+	"strings"
+)
+
+// A Condition is a condition on the value of a variable, written as
+// "KEY:value", which holds if KEY is set to value, or as "KEY", which
+// holds if KEY is set to any value.
+type Condition struct {
+	Key   string
+	Value string
+	Any   bool // holds if Key is set, regardless of Value
+}
+
+// ParseCondition parses a condition written as "KEY:value" or "KEY".
+func ParseCondition(s string) (Condition, error) {
+	key, value := s, ""
+	colon := strings.IndexByte(s, ':')
+	if colon >= 0 {
+		key, value = s[:colon], s[colon+1:]
+	}
+	if key == "" {
+		return Condition{}, errors.New("condition " + s + " has no key")
+	}
+	return Condition{Key: key, Value: value, Any: colon < 0}, nil
+}
+
+// String returns the condition in the form accepted by ParseCondition.
+func (c Condition) String() string {
+	if c.Any {
+		return c.Key
+	}
+	return c.Key + ":" + c.Value
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (c Condition) MarshalText() ([]byte, error) {
+	return []byte(c.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (c *Condition) UnmarshalText(text []byte) error {
+	pc, err := ParseCondition(string(text))
+	if err != nil {
+		return err
+	}
+	*c = pc
+	return nil
+}
+
+// holds reports whether c holds in m.
+func (c Condition) holds(m Map) bool {
+	v, ok := m[c.Key]
+	return ok && (c.Any || v == c.Value)
+}
+
+// describe describes c, for use in error messages.
+func (c Condition) describe() string {
+	if c.Any {
+		return c.Key + " is set"
+	}
+	return c.Key + " is " + quoteIfNeeded(c.Value)
+}
+
+// quoteIfNeeded quotes s if it is empty, or contains spaces.
+func quoteIfNeeded(s string) string {
+	if s == "" || strings.ContainsAny(s, " \t") {
+		return `"` + s + `"`
+	}
+	return s
+}
+
+// parseConditions parses a comma-separated list of conditions.
+func parseConditions(s string) ([]Condition, error) {
+	var conds []Condition
+	for _, part := range strings.Split(s, ",") {
+		c, err := ParseCondition(part)
+		if err != nil {
+			return nil, err
+		}
+		conds = append(conds, c)
+	}
+	return conds, nil
+}
+
+// notSet returns the error to report if v is not set in m, or nil if v
+// is not required in m.
+func (v *Var) notSet(m Map) error {
+	if v.Required {
+		return notSet(v.Key)
+	}
+	for _, c := range v.RequiredIf {
+		if c.holds(m) {
+			return &ValidationError{
+				Key:    v.Key,
+				Keys:   []string{v.Key, c.Key},
+				Reason: "variable is required when " + c.describe() + ", but is not set",
+				Err:    ErrNotFound,
+			}
+		}
+	}
+	if len(v.RequiredUnless) == 0 {
+		return nil
+	}
+	keys := []string{v.Key}
+	descs := make([]string, 0, len(v.RequiredUnless))
+	for _, c := range v.RequiredUnless {
+		if c.holds(m) {
+			return nil
+		}
+		keys = append(keys, c.Key)
+		descs = append(descs, c.describe())
+	}
+	return &ValidationError{
+		Key:    v.Key,
+		Keys:   keys,
+		Reason: "variable is required unless " + strings.Join(descs, " or ") + ", but is not set",
+		Err:    ErrNotFound,
+	}
+}
@@ -0,0 +1,164 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"acln.ro/env"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseCondition(t *testing.T) {
+	tests := []struct {
+		in   string
+		want env.Condition
+		err  bool
+	}{
+		{in: "AUTH_MODE:oauth", want: env.Condition{Key: "AUTH_MODE", Value: "oauth"}},
+		{in: "AUTH_MODE:", want: env.Condition{Key: "AUTH_MODE"}},
+		{in: "URL:http://x", want: env.Condition{Key: "URL", Value: "http://x"}},
+		{in: "TLS_CERT", want: env.Condition{Key: "TLS_CERT", Any: true}},
+		{in: ":oauth", err: true},
+		{in: "", err: true},
+	}
+	for _, tt := range tests {
+		got, err := env.ParseCondition(tt.in)
+		if (err != nil) != tt.err {
+			t.Errorf("ParseCondition(%q): got error %v, want error: %t", tt.in, err, tt.err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseCondition(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+		if !tt.err && got.String() != tt.in {
+			t.Errorf("String() = %q, want %q", got.String(), tt.in)
+		}
+	}
+}
+
+const conditionalSchema = `{"vars": [
+	{"key": "AUTH_MODE", "default": "none"},
+	{"key": "OAUTH_CLIENT_ID", "required_if": ["AUTH_MODE:oauth"]},
+	{"key": "TLS_KEY", "required_if": ["TLS_CERT"]},
+	{"key": "DATABASE_URL", "required_unless": ["DATABASE_HOST", "STORAGE:memory"]}
+]}`
+
+func TestRequiredIf(t *testing.T) {
+	var schema env.Schema
+	if err := json.Unmarshal([]byte(conditionalSchema), &schema); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		m    env.Map
+		want []string
+	}{
+		{m: env.Map{"STORAGE": "memory"}},
+		{m: env.Map{"DATABASE_HOST": "db", "AUTH_MODE": "oauth", "OAUTH_CLIENT_ID": "id"}},
+		{
+			m: env.Map{"AUTH_MODE": "oauth", "TLS_CERT": "c", "STORAGE": "disk"},
+			want: []string{
+				"env: OAUTH_CLIENT_ID, AUTH_MODE: variable is required when AUTH_MODE is oauth, but is not set",
+				"env: TLS_KEY, TLS_CERT: variable is required when TLS_CERT is set, but is not set",
+				"env: DATABASE_URL, DATABASE_HOST, STORAGE: variable is required unless DATABASE_HOST is set or STORAGE is memory, but is not set",
+			},
+		},
+	}
+	for _, tt := range tests {
+		err := schema.Validate(tt.m)
+		var errs env.ErrorList
+		if err != nil && !errors.As(err, &errs) {
+			t.Fatalf("%v: got %v, want ErrorList", tt.m, err)
+		}
+		var got []string
+		for _, err := range errs {
+			got = append(got, err.Error())
+			if !errors.Is(err, env.ErrNotFound) {
+				t.Errorf("%v does not wrap ErrNotFound", err)
+			}
+		}
+		if diff := cmp.Diff(tt.want, got); diff != "" {
+			t.Errorf("%v: (-want +got)\n%s", tt.m, diff)
+		}
+	}
+
+	data, err := json.Marshal(schema.Vars[3])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"key":"DATABASE_URL","required_unless":["DATABASE_HOST","STORAGE:memory"]}`; string(data) != want {
+		t.Errorf("Marshal = %s, want %s", data, want)
+	}
+}
+
+func TestDecodeRequiredIf(t *testing.T) {
+	var cfg struct {
+		Mode     string `env:"AUTH_MODE"`
+		ClientID string `env:"OAUTH_CLIENT_ID" validate:"required_if=AUTH_MODE:oauth,AUTH_MODE:oidc"`
+	}
+	if err := env.Decode(env.Map{"AUTH_MODE": "basic"}, &cfg); err != nil {
+		t.Errorf("Decode: %v", err)
+	}
+	err := env.Decode(env.Map{"AUTH_MODE": "oidc"}, &cfg)
+	var verr *env.ValidationError
+	if !errors.As(err, &verr) || verr.Key != "OAUTH_CLIENT_ID" || !errors.Is(err, env.ErrNotFound) {
+		t.Errorf("Decode: got %v, want OAUTH_CLIENT_ID not set", err)
+	}
+}
+
+func TestJSONSchemaConditional(t *testing.T) {
+	var schema env.Schema
+	if err := json.Unmarshal([]byte(conditionalSchema), &schema); err != nil {
+		t.Fatal(err)
+	}
+	data, err := schema.JSONSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		AllOf []json.RawMessage `json:"allOf"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, sub := range doc.AllOf {
+		got = append(got, string(compactJSON(t, sub)))
+	}
+	want := []string{
+		`{"if":{"properties":{"AUTH_MODE":{"const":"oauth"}},"required":["AUTH_MODE"]},"then":{"required":["OAUTH_CLIENT_ID"]}}`,
+		`{"if":{"required":["TLS_CERT"]},"then":{"required":["TLS_KEY"]}}`,
+		`{"else":{"required":["DATABASE_URL"]},"if":{"anyOf":[{"required":["DATABASE_HOST"]},{"properties":{"STORAGE":{"const":"memory"}},"required":["STORAGE"]}]}}`,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("(-want +got)\n%s", diff)
+	}
+}
+
+func compactJSON(t *testing.T, data []byte) []byte {
+	t.Helper()
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		t.Fatal(err)
+	}
+	out, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
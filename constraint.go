@@ -0,0 +1,260 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// UnmarshalJSON implements json.Unmarshaler. It reports invalid
+// constraints, such as malformed patterns, when the Schema is decoded,
+// rather than when it is first used.
+func (v *Var) UnmarshalJSON(data []byte) error {
+	type plain Var
+	if err := json.Unmarshal(data, (*plain)(v)); err != nil {
+		return err
+	}
+	if err := v.compile(); err != nil {
+		return fmt.Errorf("env: %s: %v", v.Key, err)
+	}
+	return nil
+}
+
+// varRules holds the compiled constraints on a Var.
+type varRules struct {
+	src     ruleSource
+	pattern *regexp.Regexp
+	inRange func(string) error
+}
+
+// ruleSource holds the fields of a Var from which its rules are compiled.
+type ruleSource struct {
+	typ                     Type
+	pattern, min, max, step string
+	secret                  bool
+}
+
+func (v *Var) ruleSource() ruleSource {
+	return ruleSource{
+		typ:     v.Type,
+		pattern: v.Pattern,
+		min:     v.Min,
+		max:     v.Max,
+		step:    v.Step,
+		secret:  v.Secret,
+	}
+}
+
+// compile checks that the constraints on v are well-formed, and keeps
+// them in compiled form, such that checking values does not compile them
+// again.
+func (v *Var) compile() error {
+	r, err := v.compileRules()
+	if err != nil {
+		return err
+	}
+	v.rules = r
+	return nil
+}
+
+// compiled returns the compiled constraints on v. If v was not compiled,
+// or its constraints changed since, they are compiled anew, but not kept,
+// so that compiled is safe to call concurrently.
+func (v *Var) compiled() (*varRules, error) {
+	if v.rules != nil && v.rules.src == v.ruleSource() {
+		return v.rules, nil
+	}
+	return v.compileRules()
+}
+
+func (v *Var) compileRules() (*varRules, error) {
+	r := &varRules{src: v.ruleSource()}
+	if v.Pattern != "" {
+		re, err := compilePattern(v.Pattern)
+		if err != nil {
+			return nil, err
+		}
+		r.pattern = re
+	}
+	if v.hasRange() {
+		inRange, err := v.parseRange()
+		if err != nil {
+			return nil, err
+		}
+		r.inRange = inRange
+	}
+	return r, nil
+}
+
+// parseRules parses the rules in a "validate" struct tag, and records
+// them as constraints on v. Rules are separated by whitespace. Each rule
+// is a name, optionally followed by '=' and an argument.
+func (v *Var) parseRules(tag string) error {
+	for _, rule := range strings.Fields(tag) {
+		name, arg := rule, ""
+		if eq := strings.IndexByte(rule, '='); eq >= 0 {
+			name, arg = rule[:eq], rule[eq+1:]
+		}
+		if err := v.parseRule(name, arg); err != nil {
+			return fmt.Errorf("rule %q: %v", rule, err)
+		}
+	}
+	return nil
+}
+
+// parseRule records the rule name=arg as a constraint on v.
+func (v *Var) parseRule(name, arg string) error {
+	noArg := func(set func()) error {
+		if arg != "" {
+			return fmt.Errorf("%s takes no argument", name)
+		}
+		set()
+		return nil
+	}
+	path := func() *PathConstraint {
+		if v.Path == nil {
+			v.Path = new(PathConstraint)
+		}
+		return v.Path
+	}
+	u := func() *URLConstraint {
+		if v.URL == nil {
+			v.URL = new(URLConstraint)
+		}
+		return v.URL
+	}
+	switch name {
+	case "required_if", "required_unless":
+		if arg == "" {
+			return fmt.Errorf("%s requires a list of conditions", name)
+		}
+		conds, err := parseConditions(arg)
+		if err != nil {
+			return err
+		}
+		if name == "required_if" {
+			v.RequiredIf = append(v.RequiredIf, conds...)
+		} else {
+			v.RequiredUnless = append(v.RequiredUnless, conds...)
+		}
+		return nil
+	case "one_of":
+		if arg == "" {
+			return errors.New("one_of requires a list of values")
+		}
+		v.Enum = strings.Split(arg, ",")
+		return nil
+	case "pattern":
+		if arg == "" {
+			return errors.New("pattern requires a regular expression")
+		}
+		v.Pattern = arg
+		return nil
+	case "min", "max", "step":
+		if arg == "" {
+			return fmt.Errorf("%s requires a value", name)
+		}
+		switch name {
+		case "min":
+			v.Min = arg
+		case "max":
+			v.Max = arg
+		case "step":
+			v.Step = arg
+		}
+		return nil
+	case "hostport":
+		return noArg(func() { v.Type = HostPortType })
+	case "listen":
+		return noArg(func() { v.Type = ListenAddr })
+	case "abs":
+		return noArg(func() { path().Absolute = true })
+	case "exists":
+		return noArg(func() { path().Exists = true })
+	case "dir":
+		return noArg(func() { path().Dir = true })
+	case "writable":
+		return noArg(func() { path().Writable = true })
+	case "scheme":
+		if arg == "" {
+			return errors.New("scheme requires a list of schemes")
+		}
+		u().Schemes = strings.Split(arg, ",")
+		return nil
+	case "host":
+		return noArg(func() { u().RequireHost = true })
+	case "no_userinfo":
+		return noArg(func() { u().NoUserinfo = true })
+	case "normalize":
+		return noArg(func() { u().Normalize = true })
+	default:
+		return fmt.Errorf("unknown rule %s", name)
+	}
+}
+
+// check checks that s is a valid value of the type of v, and satisfies
+// the constraints on v.
+func (v *Var) check(s string) error {
+	r, err := v.compiled()
+	if err != nil {
+		return err
+	}
+	return v.checkWith(r, s)
+}
+
+// checkWith is like check, but uses the compiled constraints r.
+func (v *Var) checkWith(r *varRules, s string) error {
+	if err := v.Type.check(s); err != nil {
+		return err
+	}
+	if len(v.Enum) > 0 {
+		if err := checkEnum(s, v.Enum, v.Secret); err != nil {
+			return err
+		}
+	}
+	if r.pattern != nil {
+		if err := checkPattern(s, r.pattern, v.Pattern, v.Secret); err != nil {
+			return err
+		}
+	}
+	if r.inRange != nil {
+		if err := r.inRange(s); err != nil {
+			return err
+		}
+	}
+	if v.Path != nil {
+		if err := v.Path.check(s); err != nil {
+			return err
+		}
+	}
+	if v.URL != nil {
+		if err := v.URL.check(s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// normalize returns the normal form of s, a valid value for v.
+func (v *Var) normalize(s string) string {
+	if v.URL != nil {
+		s = v.URL.normalize(s)
+	}
+	return s
+}
@@ -0,0 +1,102 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env
+
+import (
+	"context"
+	"os"
+)
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx which carries m as an overlay on top of
+// the process environment. If ctx already carries an overlay, the
+// variables in m take precedence over it.
+func NewContext(ctx context.Context, m Map) context.Context {
+	if prev, ok := FromContext(ctx); ok {
+		m = Merge(prev, m)
+	} else {
+		m = Merge(m)
+	}
+	return context.WithValue(ctx, contextKey{}, m)
+}
+
+// FromContext returns the overlay carried by ctx, if any. The caller must
+// not modify the returned Map.
+func FromContext(ctx context.Context) (Map, bool) {
+	m, ok := ctx.Value(contextKey{}).(Map)
+	return m, ok
+}
+
+// LookupContext returns the value of the variable named by key, and
+// whether it is set. The overlay carried by ctx is consulted first. If it
+// does not set the variable, LookupContext falls back to the process
+// environment, or to the Source standing in for it, as installed by
+// WithProcess.
+func LookupContext(ctx context.Context, key string) (string, bool) {
+	if m, ok := FromContext(ctx); ok {
+		if v, ok := m[key]; ok {
+			return v, true
+		}
+	}
+	if src, ok := ctx.Value(processKey{}).(Source); ok {
+		return lookupSource(ctx, src, key)
+	}
+	return os.LookupEnv(key)
+}
+
+// GetContext is like LookupContext, but returns only the value.
+func GetContext(ctx context.Context, key string) string {
+	v, _ := LookupContext(ctx, key)
+	return v
+}
+
+type processKey struct{}
+
+// WithProcess returns a copy of ctx in which src stands in for the
+// environment of the current process, as far as LookupContext, GetContext
+// and ProcessFrom are concerned. Together with Fake, it lets tests control
+// the environment observed by the code under test without modifying the
+// real one, which would interfere with tests running in parallel.
+func WithProcess(ctx context.Context, src Source) context.Context {
+	return context.WithValue(ctx, processKey{}, src)
+}
+
+// ProcessFrom returns the Source standing in for the environment of the
+// current process in ctx, as installed by WithProcess, or Process if there
+// is none. Code which reads the whole environment should load it from
+// ProcessFrom(ctx) rather than Process, so that tests can replace it.
+func ProcessFrom(ctx context.Context) Source {
+	if src, ok := ctx.Value(processKey{}).(Source); ok {
+		return src
+	}
+	return Process
+}
+
+// lookupSource looks up key in src, without copying its variables if
+// src supports looking up a single variable, as Store does.
+func lookupSource(ctx context.Context, src Source, key string) (string, bool) {
+	if l, ok := src.(interface {
+		Lookup(key string) (string, bool)
+	}); ok {
+		return l.Lookup(key)
+	}
+	m, err := src.Environ(ctx)
+	if err != nil {
+		return "", false
+	}
+	v, ok := m[key]
+	return v, ok
+}
@@ -0,0 +1,58 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"acln.ro/env"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestContext(t *testing.T) {
+	os.Setenv("ENV_TEST_CONTEXT", "process")
+	defer os.Unsetenv("ENV_TEST_CONTEXT")
+
+	ctx := context.Background()
+	if _, ok := env.FromContext(ctx); ok {
+		t.Error("FromContext reported an overlay on a bare context")
+	}
+	if got := env.GetContext(ctx, "ENV_TEST_CONTEXT"); got != "process" {
+		t.Errorf("GetContext without overlay = %q, want %q", got, "process")
+	}
+
+	job := env.Map{"JOB_ID": "42", "ENV_TEST_CONTEXT": "job"}
+	ctx = env.NewContext(ctx, job)
+	job["JOB_ID"] = "modified"
+	step := env.NewContext(ctx, env.Map{"STEP": "build"})
+
+	if got := env.GetContext(ctx, "ENV_TEST_CONTEXT"); got != "job" {
+		t.Errorf("GetContext(ENV_TEST_CONTEXT) = %q, want %q", got, "job")
+	}
+	if _, ok := env.LookupContext(ctx, "STEP"); ok {
+		t.Error("inner overlay visible in outer context")
+	}
+	if _, ok := env.LookupContext(step, "ENV_TEST_UNSET"); ok {
+		t.Error("LookupContext reported unset variable as set")
+	}
+	got, _ := env.FromContext(step)
+	want := env.Map{"JOB_ID": "42", "ENV_TEST_CONTEXT": "job", "STEP": "build"}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("FromContext(step) = %v, want %v: %s", got, want, diff)
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteCSV writes m to w as CSV, sorted by key, with a header row followed
+// by one row per variable, holding the key and the value:
+//
+//	key,value
+//	GREETING,"hello, world"
+//	PEM,"-----BEGIN CERTIFICATE-----
+//	...
+//	-----END CERTIFICATE-----
+//	"
+//
+// Fields are quoted as required by RFC 4180.
+//
+// Spreadsheet applications interpret cells starting with '=', '+', '-'
+// or '@' as formulas, which may run commands or leak data when the file
+// is opened. Values are written as they are, such that ReadCSV can read
+// them back. To write files meant to be opened in spreadsheets, use the
+// EscapeFormulas option.
+func (m Map) WriteCSV(w io.Writer, opts ...CSVOption) error {
+	return m.writeDelimited(w, ',', opts)
+}
+
+// WriteTSV is like WriteCSV, but separates fields by tabs.
+func (m Map) WriteTSV(w io.Writer, opts ...CSVOption) error {
+	return m.writeDelimited(w, '\t', opts)
+}
+
+// A CSVOption configures the writing of CSV and TSV files.
+type CSVOption func(*csvConfig)
+
+type csvConfig struct {
+	escapeFormulas bool
+}
+
+// EscapeFormulas causes WriteCSV and WriteTSV to prefix cells which
+// spreadsheet applications would interpret as formulas with a single
+// quote, such that they are displayed as text. Cells starting with '=',
+// '+', '-', '@', a tab or a carriage return are escaped. The escaped
+// values are not restored by ReadCSV and ReadTSV.
+func EscapeFormulas() CSVOption {
+	return func(cfg *csvConfig) {
+		cfg.escapeFormulas = true
+	}
+}
+
+func (m Map) writeDelimited(w io.Writer, comma rune, opts []CSVOption) error {
+	var cfg csvConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	cell := func(s string) string {
+		if cfg.escapeFormulas && s != "" && strings.IndexByte("=+-@\t\r", s[0]) >= 0 {
+			return "'" + s
+		}
+		return s
+	}
+	cw := csv.NewWriter(w)
+	cw.Comma = comma
+	cw.Write([]string{"key", "value"})
+	for _, k := range m.keys() {
+		cw.Write([]string{cell(k), cell(m[k])})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// ReadCSV reads variables from CSV input, such as that written by
+// WriteCSV: rows of two fields, holding the key and the value. A first row
+// holding "key" and "value", in any case, is a header, and is skipped.
+// Later rows for a key override earlier ones. Malformed rows are reported
+// together, as an ErrorList of *ParseError.
+func ReadCSV(r io.Reader) (Map, error) {
+	return readDelimited(r, ',')
+}
+
+// ReadTSV is like ReadCSV, but reads input whose fields are separated by
+// tabs.
+func ReadTSV(r io.Reader) (Map, error) {
+	return readDelimited(r, '\t')
+}
+
+func readDelimited(r io.Reader, comma rune) (Map, error) {
+	cr := csv.NewReader(r)
+	cr.Comma = comma
+	cr.FieldsPerRecord = -1
+	m := make(Map)
+	var errs ErrorList
+	for first := true; ; first = false {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var perr *csv.ParseError
+			if !errors.As(err, &perr) {
+				return nil, err
+			}
+			errs = append(errs, &ParseError{Line: perr.StartLine, Err: perr.Err})
+			continue
+		}
+		line, _ := cr.FieldPos(0)
+		switch {
+		case len(rec) != 2:
+			errs = append(errs, &ParseError{
+				Line:  line,
+				Input: strings.Join(rec, string(comma)),
+				Err:   errors.New("want 2 fields: key and value"),
+			})
+		case first && strings.EqualFold(rec[0], "key") && strings.EqualFold(rec[1], "value"):
+			// Header.
+		case rec[0] == "" || strings.ContainsAny(rec[0], "=\x00"):
+			errs = append(errs, &ParseError{
+				Line:  line,
+				Input: strings.Join(rec, string(comma)),
+				Err:   fmt.Errorf("invalid variable name %q", rec[0]),
+			})
+		default:
+			m[rec[0]] = rec[1]
+		}
+	}
+	if err := errs.err(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
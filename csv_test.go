@@ -0,0 +1,157 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"acln.ro/env"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWriteCSV(t *testing.T) {
+	m := env.Map{
+		"GREETING": "hello, world",
+		"PEM":      "-----BEGIN-----\nabc\n",
+		"QUOTE":    `say "hi"`,
+		"EMPTY":    "",
+	}
+	var buf bytes.Buffer
+	if err := m.WriteCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := `key,value
+EMPTY,
+GREETING,"hello, world"
+PEM,"-----BEGIN-----
+abc
+"
+QUOTE,"say ""hi"""
+`
+	if diff := cmp.Diff(buf.String(), want); diff != "" {
+		t.Errorf("WriteCSV: %s", diff)
+	}
+	got, err := env.ReadCSV(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(got, m); diff != "" {
+		t.Errorf("ReadCSV(WriteCSV(m)): %s", diff)
+	}
+}
+
+func TestWriteTSV(t *testing.T) {
+	m := env.Map{"A": "x y", "B": "a\tb"}
+	var buf bytes.Buffer
+	if err := m.WriteTSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := buf.String(), "key\tvalue\nA\tx y\nB\t\"a\tb\"\n"; got != want {
+		t.Errorf("WriteTSV: got %q, want %q", got, want)
+	}
+	got, err := env.ReadTSV(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(got, m); diff != "" {
+		t.Errorf("ReadTSV(WriteTSV(m)): %s", diff)
+	}
+}
+
+func TestWriteCSVEscapeFormulas(t *testing.T) {
+	m := env.Map{
+		"CMD":   `=HYPERLINK("http://evil","x")`,
+		"FLAGS": "-Xmx1g",
+		"PLAIN": "a=b",
+		"USER":  "@admin",
+	}
+	var buf bytes.Buffer
+	if err := m.WriteCSV(&buf, env.EscapeFormulas()); err != nil {
+		t.Fatal(err)
+	}
+	want := `key,value
+CMD,"'=HYPERLINK(""http://evil"",""x"")"
+FLAGS,'-Xmx1g
+PLAIN,a=b
+USER,'@admin
+`
+	if diff := cmp.Diff(buf.String(), want); diff != "" {
+		t.Errorf("WriteCSV: %s", diff)
+	}
+}
+
+func TestReadCSV(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  env.Map
+	}{
+		{
+			name:  "no header",
+			input: "A,1\nB,2\n",
+			want:  env.Map{"A": "1", "B": "2"},
+		},
+		{
+			name:  "header in other case",
+			input: "Key,Value\r\nA,1\r\n",
+			want:  env.Map{"A": "1"},
+		},
+		{
+			name:  "later rows override",
+			input: "A,1\nA,2\n",
+			want:  env.Map{"A": "2"},
+		},
+		{
+			name:  "header-like row after data",
+			input: "A,1\nkey,value\n",
+			want:  env.Map{"A": "1", "key": "value"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := env.ReadCSV(strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Errorf("ReadCSV: %s", diff)
+			}
+		})
+	}
+}
+
+func TestReadCSVErrors(t *testing.T) {
+	input := "key,value\nA,1\nB\nC,3,extra\n,4\nD=E,5\nF,\"unterminated\n"
+	_, err := env.ReadCSV(strings.NewReader(input))
+	var errs env.ErrorList
+	if !errors.As(err, &errs) {
+		t.Fatalf("got %v, want ErrorList", err)
+	}
+	var lines []int
+	for _, err := range errs {
+		var perr *env.ParseError
+		if !errors.As(err, &perr) {
+			t.Fatalf("got %T, want *ParseError", err)
+		}
+		lines = append(lines, perr.Line)
+	}
+	if diff := cmp.Diff(lines, []int{3, 4, 5, 6, 7}); diff != "" {
+		t.Errorf("error lines: %s\n%v", diff, err)
+	}
+}
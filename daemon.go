@@ -0,0 +1,57 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env
+
+// daemonScrubbed lists the patterns, in the syntax of AcceptEnv, matching
+// the variables ForDaemon removes. They describe the terminal, the login
+// session and the interactive shell a daemon was started from, none of
+// which outlive the re-exec.
+var daemonScrubbed = []string{
+	// Terminal.
+	"TERM", "TERMCAP", "COLORTERM", "COLUMNS", "LINES",
+	"TMUX", "TMUX_PANE", "STY", "WINDOW",
+
+	// Login session.
+	"SSH_*", "XDG_SESSION_*", "XDG_SEAT", "XDG_VTNR",
+	"DISPLAY", "WAYLAND_DISPLAY", "DBUS_SESSION_BUS_ADDRESS",
+
+	// Interactive shell state.
+	"PWD", "OLDPWD", "SHLVL", "_",
+}
+
+// ForDaemon returns a copy of m suitable for re-executing a process as a
+// long-running daemon. Variables describing the controlling terminal
+// (TERM, COLUMNS, TMUX, ...), the login session (SSH_*, XDG_SESSION_*,
+// DISPLAY, ...) and the state of the interactive shell (PWD, OLDPWD,
+// SHLVL and _) are removed, since a daemon outlives all of them, and
+// acting on them later is a mistake: the terminal is gone, the agent
+// socket is stale, and the working directory was changed.
+//
+// Variables matching one of the keep patterns, in the syntax of AcceptEnv,
+// are preserved even if they would otherwise be removed.
+func (m Map) ForDaemon(keep ...string) Map {
+	kept := m.AcceptEnv(keep...)
+	scrubbed := m.AcceptEnv(daemonScrubbed...)
+	d := make(Map, len(m))
+	for k, v := range m {
+		if _, ok := scrubbed[k]; ok {
+			if _, ok := kept[k]; !ok {
+				continue
+			}
+		}
+		d[k] = v
+	}
+	return d
+}
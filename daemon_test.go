@@ -0,0 +1,88 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env_test
+
+import (
+	"testing"
+
+	"acln.ro/env"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestForDaemon(t *testing.T) {
+	m := env.Map{
+		"PATH":                "/usr/bin:/bin",
+		"HOME":                "/home/u",
+		"TERM":                "xterm-256color",
+		"COLUMNS":             "80",
+		"SSH_AUTH_SOCK":       "/tmp/ssh-x/agent.1",
+		"SSH_CONNECTION":      "10.0.0.1 1 10.0.0.2 22",
+		"XDG_SESSION_ID":      "3",
+		"XDG_SESSION_TYPE":    "tty",
+		"XDG_RUNTIME_DIR":     "/run/user/1000",
+		"DISPLAY":             ":0",
+		"PWD":                 "/home/u/src",
+		"SHLVL":               "2",
+		"_":                   "/usr/bin/app",
+		"APP_CONFIG":          "/etc/app.conf",
+		"TERMINAL_EMULATOR":   "x",
+		"MY_SSH_PORT":         "2222",
+		"XDG_SESSION_DESKTOP": "d",
+	}
+	tests := []struct {
+		name string
+		keep []string
+		want env.Map
+	}{
+		{
+			name: "default",
+			want: env.Map{
+				"PATH":              "/usr/bin:/bin",
+				"HOME":              "/home/u",
+				"XDG_RUNTIME_DIR":   "/run/user/1000",
+				"APP_CONFIG":        "/etc/app.conf",
+				"TERMINAL_EMULATOR": "x",
+				"MY_SSH_PORT":       "2222",
+			},
+		},
+		{
+			name: "keep",
+			keep: []string{"SSH_AUTH_SOCK DISPLAY", "XDG_SESSION_T*"},
+			want: env.Map{
+				"PATH":              "/usr/bin:/bin",
+				"HOME":              "/home/u",
+				"XDG_RUNTIME_DIR":   "/run/user/1000",
+				"APP_CONFIG":        "/etc/app.conf",
+				"TERMINAL_EMULATOR": "x",
+				"MY_SSH_PORT":       "2222",
+				"SSH_AUTH_SOCK":     "/tmp/ssh-x/agent.1",
+				"DISPLAY":           ":0",
+				"XDG_SESSION_TYPE":  "tty",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := m.ForDaemon(tt.keep...)
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Errorf("ForDaemon: %s", diff)
+			}
+		})
+	}
+	if m["TERM"] == "" {
+		t.Error("ForDaemon modified its receiver")
+	}
+}
@@ -0,0 +1,246 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Decode decodes variables from m into the struct pointed to by v.
+//
+// Fields are mapped to variables using the "env" struct tag, which holds
+// the name of the variable, optionally followed by comma-separated
+// options. The "required" option causes Decode to fail if the variable is
+// not set. The "home" option expands a leading "~" in the value, as
+// described by Map.ExpandHome. The "default" struct tag specifies a value
+// to use if the variable is not set. Fields without an "env" tag are
+// ignored, with the exception of embedded structs, which are decoded
+// recursively.
+//
+//	type Config struct {
+//		Addr    string        `env:"LISTEN_ADDR" default:":8080"`
+//		DB      string        `env:"DATABASE_URL,required"`
+//		Timeout time.Duration `env:"TIMEOUT" default:"30s"`
+//	}
+//
+// The "validate" struct tag lists constraints on the value, separated by
+// spaces, as in `validate:"abs dir"`. The constraints are those of Var:
+//
+//	required_if=K:v      the variable is required if K is set to v in m
+//	required_unless=K:v  the variable is required unless K is set to v in m
+//	one_of=a,b           the value must be one of a and b
+//	pattern=re           the value must match the regular expression re
+//	min=n                the value must be at least n
+//	max=n                the value must be at most n
+//	step=n               the value must be a multiple of n from min, or 0
+//	hostport             the value must be a host:port pair
+//	listen               the value must be an address to listen on
+//	abs                  the path must be absolute
+//	exists               the path must exist
+//	dir                  the path must be a directory, if it exists
+//	writable             the path must be writable, or creatable
+//	scheme=a,b           the URL scheme must be one of a and b
+//	host                 the URL must name a host
+//	no_userinfo          the URL must not contain user information
+//	normalize            the URL is normalized before decoding
+//
+// Conditions in required_if and required_unless are separated by commas.
+// A condition written as "K" alone holds if K is set to any value. Since
+// rules are separated by spaces, patterns must match spaces using \s or
+// \x20.
+//
+// Supported field types are string, bool (with the spellings accepted by
+// ParseBool), integer and floating point types, time.Duration, []string
+// (from comma-separated values), and types implementing
+// encoding.TextUnmarshaler.
+//
+// Decode does not stop at the first invalid variable. Errors concerning
+// individual variables are collected in an ErrorList of *ValidationError.
+// If a required variable is not set, its error wraps ErrNotFound.
+func Decode(m Map, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return errors.New("env: Decode requires a non-nil pointer to a struct")
+	}
+	var errs ErrorList
+	if err := decodeStruct(m, rv.Elem(), &errs); err != nil {
+		return err
+	}
+	return errs.err()
+}
+
+// MustDecode is like Decode, but panics if decoding fails. It is intended
+// for use during program initialization.
+func MustDecode(m Map, v interface{}) {
+	if err := Decode(m, v); err != nil {
+		panic(err)
+	}
+}
+
+// decodeStruct decodes m into sv. Errors concerning variables are added
+// to errs. Other errors are returned immediately.
+func decodeStruct(m Map, sv reflect.Value, errs *ErrorList) error {
+	st := sv.Type()
+	for i := 0; i < st.NumField(); i++ {
+		sf := st.Field(i)
+		fv := sv.Field(i)
+		tag, ok := sf.Tag.Lookup("env")
+		if !ok {
+			if sf.Anonymous && fv.Kind() == reflect.Struct {
+				if err := decodeStruct(m, fv, errs); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if sf.PkgPath != "" {
+			return fmt.Errorf("env: field %s is not exported", sf.Name)
+		}
+		key, opts := parseTag(tag)
+		cv := Var{Key: key, Required: opts["required"]}
+		if err := cv.parseRules(sf.Tag.Get("validate")); err != nil {
+			return fmt.Errorf("env: field %s: %v", sf.Name, err)
+		}
+		if cv.hasRange() && cv.Type == "" {
+			cv.Type = numericType(fv.Type())
+		}
+		if err := cv.compile(); err != nil {
+			return fmt.Errorf("env: field %s: %v", sf.Name, err)
+		}
+		val, ok := m[key]
+		if !ok {
+			if def, hasDefault := sf.Tag.Lookup("default"); hasDefault {
+				val, ok = def, true
+			}
+		}
+		if !ok {
+			if err := cv.notSet(m); err != nil {
+				*errs = append(*errs, err)
+			}
+			continue
+		}
+		if opts["home"] {
+			expanded, err := m.ExpandHome(val)
+			if err != nil {
+				*errs = append(*errs, invalid(key, err))
+				continue
+			}
+			val = expanded
+		}
+		if err := cv.check(val); err != nil {
+			*errs = append(*errs, invalid(key, err))
+			continue
+		}
+		if err := decodeValue(fv, cv.normalize(val)); err != nil {
+			*errs = append(*errs, invalid(key, err))
+		}
+	}
+	return nil
+}
+
+// numericType returns the Type of the values of the numeric Go type t,
+// or the empty Type, if t is not numeric.
+func numericType(t reflect.Type) Type {
+	if t == durationType {
+		return Duration
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return Int
+	case reflect.Float32, reflect.Float64:
+		return Float
+	}
+	return ""
+}
+
+// parseTag parses an "env" struct tag.
+func parseTag(tag string) (key string, opts map[string]bool) {
+	parts := strings.Split(tag, ",")
+	opts = make(map[string]bool)
+	for _, opt := range parts[1:] {
+		opts[strings.TrimSpace(opt)] = true
+	}
+	return parts[0], opts
+}
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// decodeValue decodes s into v.
+func decodeValue(v reflect.Value, s string) error {
+	if v.CanAddr() && v.Addr().Type().Implements(textUnmarshalerType) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	}
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported type %v", v.Type())
+		}
+		var items []string
+		if s != "" {
+			items = strings.Split(s, ",")
+			for i := range items {
+				items[i] = strings.TrimSpace(items[i])
+			}
+		}
+		v.Set(reflect.ValueOf(items).Convert(v.Type()))
+	default:
+		return fmt.Errorf("unsupported type %v", v.Type())
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"acln.ro/env"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type decodeConfig struct {
+	Addr    string        `env:"LISTEN_ADDR" default:":8080"`
+	DB      string        `env:"DATABASE_URL,required"`
+	Timeout time.Duration `env:"TIMEOUT" default:"30s"`
+	Debug   bool          `env:"DEBUG"`
+	Workers int           `env:"WORKERS"`
+	Ratio   float64       `env:"RATIO"`
+	Hosts   []string      `env:"HOSTS"`
+	Ignored string
+}
+
+func TestDecode(t *testing.T) {
+	m := env.Map{
+		"DATABASE_URL": "postgres://db",
+		"DEBUG":        "true",
+		"WORKERS":      "08",
+		"RATIO":        "0.5",
+		"HOSTS":        "a, b,c",
+		"Ignored":      "x",
+	}
+	var got decodeConfig
+	if err := env.Decode(m, &got); err != nil {
+		t.Fatal(err)
+	}
+	want := decodeConfig{
+		Addr:    ":8080",
+		DB:      "postgres://db",
+		Timeout: 30 * time.Second,
+		Debug:   true,
+		Workers: 8,
+		Ratio:   0.5,
+		Hosts:   []string{"a", "b", "c"},
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("Decode: %s", diff)
+	}
+}
+
+func TestDecodeBool(t *testing.T) {
+	var got struct {
+		Debug   bool `env:"DEBUG"`
+		Verbose bool `env:"VERBOSE" default:"on"`
+		Trace   bool `env:"TRACE"`
+	}
+	m := env.Map{"DEBUG": "yes", "VERBOSE": "off", "TRACE": " On "}
+	if err := env.Decode(m, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !got.Debug || got.Verbose || !got.Trace {
+		t.Errorf("Decode(%v) = %+v, want Debug and Trace only", m, got)
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		m    env.Map
+		want string
+	}{
+		{
+			name: "Required",
+			m:    env.Map{},
+			want: "DATABASE_URL",
+		},
+		{
+			name: "BadInt",
+			m:    env.Map{"DATABASE_URL": "x", "WORKERS": "four"},
+			want: "WORKERS",
+		},
+		{
+			name: "BadDuration",
+			m:    env.Map{"DATABASE_URL": "x", "TIMEOUT": "soon"},
+			want: "TIMEOUT",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cfg decodeConfig
+			err := env.Decode(tt.m, &cfg)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got error %v, want error naming %s", err, tt.want)
+			}
+		})
+	}
+	var cfg decodeConfig
+	if err := env.Decode(env.Map{}, cfg); err == nil {
+		t.Error("Decode into non-pointer succeeded")
+	}
+}
+
+func TestMustDecode(t *testing.T) {
+	defer func() {
+		r := recover()
+		err, ok := r.(error)
+		if !ok || !strings.Contains(err.Error(), "DATABASE_URL") {
+			t.Errorf("recovered %v, want error naming DATABASE_URL", r)
+		}
+	}()
+	var cfg decodeConfig
+	env.MustDecode(env.Map{}, &cfg)
+}
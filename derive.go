@@ -0,0 +1,122 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env
+
+// Derive returns a copy-on-write environment derived from m. Changes to
+// the derived environment are recorded separately, and never copy or
+// modify m, which makes deriving many environments from one large base
+// cheap. m must not be modified while derived environments are in use.
+func (m Map) Derive() *Derived {
+	return &Derived{base: m}
+}
+
+// Derived is a copy-on-write environment derived from a base Map. The
+// zero Derived is an empty environment.
+type Derived struct {
+	base  Map
+	set   Map
+	unset map[string]bool
+}
+
+// Set sets key to value.
+func (d *Derived) Set(key, value string) {
+	if d.set == nil {
+		d.set = make(Map)
+	}
+	d.set[key] = value
+	delete(d.unset, key)
+}
+
+// Unset unsets key.
+func (d *Derived) Unset(key string) {
+	delete(d.set, key)
+	if _, ok := d.base[key]; ok {
+		if d.unset == nil {
+			d.unset = make(map[string]bool)
+		}
+		d.unset[key] = true
+	}
+}
+
+// Lookup returns the value of the variable named by key, and whether it
+// is set.
+func (d *Derived) Lookup(key string) (string, bool) {
+	if v, ok := d.set[key]; ok {
+		return v, true
+	}
+	if d.unset[key] {
+		return "", false
+	}
+	v, ok := d.base[key]
+	return v, ok
+}
+
+// Get returns the value of the variable named by key, or the empty string
+// if the variable is not set.
+func (d *Derived) Get(key string) string {
+	v, _ := d.Lookup(key)
+	return v
+}
+
+// Len returns the number of variables in d.
+func (d *Derived) Len() int {
+	n := len(d.base) - len(d.unset)
+	for k := range d.set {
+		if _, ok := d.base[k]; !ok {
+			n++
+		}
+	}
+	return n
+}
+
+// Derive returns a new environment derived from d. It shares the base
+// of d, and copies only the changes recorded in d.
+func (d *Derived) Derive() *Derived {
+	dd := &Derived{base: d.base}
+	if len(d.set) > 0 {
+		dd.set = Merge(d.set)
+	}
+	if len(d.unset) > 0 {
+		dd.unset = make(map[string]bool, len(d.unset))
+		for k := range d.unset {
+			dd.unset[k] = true
+		}
+	}
+	return dd
+}
+
+// Map materializes d into a new Map.
+func (d *Derived) Map() Map {
+	m := make(Map, d.Len())
+	for k, v := range d.base {
+		if !d.unset[k] {
+			m[k] = v
+		}
+	}
+	for k, v := range d.set {
+		m[k] = v
+	}
+	return m
+}
+
+// Encode encodes d as a slice of "key=value" pairs, like Map.Encode.
+func (d *Derived) Encode(opts ...EncodeOption) []string {
+	return d.Map().Encode(opts...)
+}
+
+// String encodes d like Map.String.
+func (d *Derived) String() string {
+	return d.Map().String()
+}
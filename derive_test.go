@@ -0,0 +1,86 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env_test
+
+import (
+	"testing"
+
+	"acln.ro/env"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDerive(t *testing.T) {
+	base := env.Map{"PATH": "/bin", "HOME": "/root", "TASK": "none"}
+	d := base.Derive()
+	d.Set("TASK", "1")
+	d.Set("WORKER", "a")
+	d.Unset("HOME")
+	d.Unset("NEVER_SET")
+
+	want := env.Map{"PATH": "/bin", "TASK": "1", "WORKER": "a"}
+	if diff := cmp.Diff(d.Map(), want); diff != "" {
+		t.Errorf("Map() = %v, want %v: %s", d.Map(), want, diff)
+	}
+	if d.Len() != len(want) {
+		t.Errorf("Len() = %d, want %d", d.Len(), len(want))
+	}
+	if diff := cmp.Diff(d.Encode(), want.Encode()); diff != "" {
+		t.Errorf("Encode() = %q: %s", d.Encode(), diff)
+	}
+	if _, ok := d.Lookup("HOME"); ok {
+		t.Error("Lookup(HOME) reported unset variable as set")
+	}
+	if got := d.Get("PATH"); got != "/bin" {
+		t.Errorf("Get(PATH) = %q, want %q", got, "/bin")
+	}
+	if diff := cmp.Diff(base, env.Map{"PATH": "/bin", "HOME": "/root", "TASK": "none"}); diff != "" {
+		t.Errorf("base modified: %s", diff)
+	}
+
+	dd := d.Derive()
+	dd.Set("HOME", "/home/task")
+	dd.Unset("WORKER")
+	if _, ok := d.Lookup("WORKER"); !ok {
+		t.Error("change to derived environment affected its parent")
+	}
+	want = env.Map{"PATH": "/bin", "HOME": "/home/task", "TASK": "1"}
+	if diff := cmp.Diff(dd.Map(), want); diff != "" {
+		t.Errorf("dd.Map() = %v, want %v: %s", dd.Map(), want, diff)
+	}
+	if dd.Len() != len(want) {
+		t.Errorf("dd.Len() = %d, want %d", dd.Len(), len(want))
+	}
+}
+
+func TestDerivedZero(t *testing.T) {
+	var d env.Derived
+	d.Set("K", "v")
+	d.Unset("X")
+	if diff := cmp.Diff(d.Map(), env.Map{"K": "v"}); diff != "" {
+		t.Errorf("Map() = %v: %s", d.Map(), diff)
+	}
+}
+
+func BenchmarkDerive(b *testing.B) {
+	base := benchmarkMap(5000)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		d := base.Derive()
+		d.Set("TASK_ID", "1")
+		d.Set("VARIABLE_00001", "x")
+		d.Unset("VARIABLE_00002")
+	}
+}
@@ -0,0 +1,56 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// dockerInspect is the part of the output of "docker inspect" for a
+// container or an image which FromDockerInspect uses.
+type dockerInspect struct {
+	Config *struct {
+		Env []string
+	}
+}
+
+// FromDockerInspect returns the environment a container was configured
+// with, as recorded in the Config.Env field of the output of "docker
+// inspect" for the container, which is also present for images. data may
+// hold a single JSON object, or an array of exactly one, as printed by
+// "docker inspect <container>".
+func FromDockerInspect(data []byte) (Map, error) {
+	var objs []dockerInspect
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		objs = make([]dockerInspect, 1)
+		if err := json.Unmarshal(trimmed, &objs[0]); err != nil {
+			return nil, fmt.Errorf("env: docker inspect: %v", err)
+		}
+	} else if err := json.Unmarshal(data, &objs); err != nil {
+		return nil, fmt.Errorf("env: docker inspect: %v", err)
+	}
+	switch {
+	case len(objs) == 0:
+		return nil, errors.New("env: docker inspect: no objects")
+	case len(objs) > 1:
+		return nil, fmt.Errorf("env: docker inspect: %d objects, want 1", len(objs))
+	case objs[0].Config == nil:
+		return nil, errors.New("env: docker inspect: missing Config")
+	}
+	return Parse(objs[0].Config.Env...), nil
+}
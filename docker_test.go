@@ -0,0 +1,74 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env_test
+
+import (
+	"strings"
+	"testing"
+
+	"acln.ro/env"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const dockerContainer = `{
+	"Id": "4fa6e0f0c678",
+	"Name": "/web",
+	"Config": {
+		"Hostname": "4fa6e0f0c678",
+		"Env": [
+			"PATH=/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin",
+			"DATABASE_URL=postgres://db/app?sslmode=disable",
+			"EMPTY="
+		],
+		"Image": "web:1.2"
+	}
+}`
+
+func TestFromDockerInspect(t *testing.T) {
+	want := env.Map{
+		"PATH":         "/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin",
+		"DATABASE_URL": "postgres://db/app?sslmode=disable",
+		"EMPTY":        "",
+	}
+	for _, input := range []string{dockerContainer, "[" + dockerContainer + "]\n"} {
+		got, err := env.FromDockerInspect([]byte(input))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(got, want); diff != "" {
+			t.Errorf("FromDockerInspect: %s", diff)
+		}
+	}
+}
+
+func TestFromDockerInspectErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"[]", "no objects"},
+		{"[" + dockerContainer + "," + dockerContainer + "]", "2 objects"},
+		{`{"Id": "x"}`, "missing Config"},
+		{`{"Config": `, "unexpected end"},
+		{"not json", "invalid character"},
+	}
+	for _, tt := range tests {
+		_, err := env.FromDockerInspect([]byte(tt.input))
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("FromDockerInspect(%.20q): got error %v, want one containing %q", tt.input, err, tt.want)
+		}
+	}
+}
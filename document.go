@@ -0,0 +1,283 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// A Document is a dotenv file, held as text, which can be edited without
+// disturbing its layout. Comments, blank lines, the order of variables,
+// and the way values are quoted, are preserved, except on lines which
+// are edited.
+//
+// A Document does not follow #include directives. It keeps them as they
+// are.
+type Document struct {
+	entries []dotenvEntry
+}
+
+// ParseDocument parses data, in the dotenv format understood by
+// ReadDotenv. Malformed lines are reported together, as an ErrorList of
+// *ParseError.
+func ParseDocument(data []byte) (*Document, error) {
+	d := &Document{entries: scanDotenv(string(data))}
+	var errs ErrorList
+	for _, e := range d.entries {
+		if e.err != nil {
+			errs = append(errs, &ParseError{Line: e.line, Input: e.input(), Err: e.err})
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return d, nil
+}
+
+// Lookup returns the value of the variable named by key, and whether it
+// is set. If the variable is assigned several times, the last assignment
+// wins, as it does for ReadDotenv.
+func (d *Document) Lookup(key string) (string, bool) {
+	if i := d.last(key); i != -1 {
+		return d.entries[i].value, true
+	}
+	return "", false
+}
+
+// Map returns the variables set by d.
+func (d *Document) Map() Map {
+	m := make(Map)
+	for _, e := range d.entries {
+		if e.key != "" {
+			m[e.key] = e.value
+		}
+	}
+	return m
+}
+
+// Set sets the variable named by key to value. If the variable is already
+// assigned, Set rewrites its last assignment in place, keeping any
+// indentation, "export" prefix and trailing comment. Otherwise, it
+// appends an assignment to the end of the document.
+func (d *Document) Set(key, value string) {
+	text := key + "=" + dotenvQuote(value)
+	if i := d.last(key); i != -1 {
+		e := &d.entries[i]
+		e.raw = assignmentPrefix(e.raw) + text + trailingComment(e.raw) + eolOf(e.raw)
+		e.value = value
+		return
+	}
+	eol := d.eol()
+	if n := len(d.entries); n > 0 && eolOf(d.entries[n-1].raw) == "" {
+		d.entries[n-1].raw += eol
+	}
+	d.entries = append(d.entries, dotenvEntry{raw: text + eol, key: key, value: value})
+}
+
+// Unset removes all assignments of the variable named by key. It reports
+// whether the variable was assigned.
+func (d *Document) Unset(key string) bool {
+	kept := d.entries[:0]
+	for _, e := range d.entries {
+		if e.key != key {
+			kept = append(kept, e)
+		}
+	}
+	found := len(kept) < len(d.entries)
+	d.entries = kept
+	return found
+}
+
+// Comment comments out all assignments of the variable named by key, by
+// prefixing each of their lines with "# ". It reports whether the
+// variable was assigned.
+func (d *Document) Comment(key string) bool {
+	found := false
+	for i := range d.entries {
+		e := &d.entries[i]
+		if e.key != key {
+			continue
+		}
+		lines := strings.SplitAfter(e.raw, "\n")
+		for j, l := range lines {
+			if l != "" {
+				lines[j] = "# " + l
+			}
+		}
+		*e = dotenvEntry{line: e.line, raw: strings.Join(lines, "")}
+		found = true
+	}
+	return found
+}
+
+// Uncomment restores the last single-line assignment of the variable
+// named by key which was commented out, as by Comment. It reports whether
+// such an assignment was found.
+func (d *Document) Uncomment(key string) bool {
+	for i := len(d.entries) - 1; i >= 0; i-- {
+		e := &d.entries[i]
+		s := strings.TrimLeft(e.raw, " \t")
+		if e.key != "" || !strings.HasPrefix(s, "#") || strings.Count(e.raw, "\n") > 1 {
+			continue
+		}
+		text := strings.TrimLeft(trimEOL(s[1:]), " \t")
+		k, v, heredoc, err := parseDotenvLine(text)
+		if err != nil || heredoc || k != key {
+			continue
+		}
+		e.raw = text + eolOf(e.raw)
+		e.key, e.value = k, v
+		return true
+	}
+	return false
+}
+
+// Bytes returns the text of the document.
+func (d *Document) Bytes() []byte {
+	var buf bytes.Buffer
+	for _, e := range d.entries {
+		buf.WriteString(e.raw)
+	}
+	return buf.Bytes()
+}
+
+// WriteTo writes the text of the document to w.
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write(d.Bytes())
+	return int64(n), err
+}
+
+// last returns the index of the last assignment of key, or -1.
+func (d *Document) last(key string) int {
+	for i := len(d.entries) - 1; i >= 0; i-- {
+		if d.entries[i].key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// eol returns the line terminator used by the document.
+func (d *Document) eol() string {
+	for _, e := range d.entries {
+		if eol := eolOf(e.raw); eol != "" {
+			return eol
+		}
+	}
+	return "\n"
+}
+
+// assignmentPrefix returns the indentation and "export" prefix which
+// precede the key in the assignment raw.
+func assignmentPrefix(raw string) string {
+	rest := strings.TrimLeft(raw, " \t")
+	if strings.HasPrefix(rest, "export ") || strings.HasPrefix(rest, "export\t") {
+		rest = strings.TrimLeft(rest[len("export"):], " \t")
+	}
+	return raw[:len(raw)-len(rest)]
+}
+
+// trailingComment returns the comment which follows the value in the
+// single-line assignment raw, including the space which precedes it.
+func trailingComment(raw string) string {
+	line := trimEOL(raw)
+	if strings.Contains(line, "\n") {
+		return ""
+	}
+	eq := strings.IndexByte(line, '=')
+	value := strings.TrimLeft(line[eq+1:], " \t")
+	if strings.HasPrefix(value, "<<") {
+		return ""
+	}
+	rest := value
+	if !strings.HasPrefix(value, "#") || len(value) == len(line[eq+1:]) {
+		var err error
+		if _, rest, err = parseDotenvValue(value); err != nil {
+			return ""
+		}
+	}
+	comment := strings.TrimLeft(rest, " \t")
+	if !strings.HasPrefix(comment, "#") {
+		return ""
+	}
+	before := line[:len(line)-len(comment)]
+	return before[len(strings.TrimRight(before, " \t")):] + comment
+}
+
+// eolOf returns the line terminator at the end of raw: "\n", "\r\n", or
+// the empty string if raw does not end in a newline.
+func eolOf(raw string) string {
+	switch {
+	case strings.HasSuffix(raw, "\r\n"):
+		return "\r\n"
+	case strings.HasSuffix(raw, "\n"):
+		return "\n"
+	default:
+		return ""
+	}
+}
+
+// EditFile edits the dotenv file at path. It parses the file as a
+// Document, calls edit, and, if edit succeeds and changed the document,
+// writes the document back to the file. Lines which were not edited are
+// written back exactly as they were.
+//
+// The file is replaced atomically: the document is written to a
+// temporary file in the same directory, which is then renamed over the
+// original, so that a crash never leaves a partially written file behind.
+// The mode of the original file is preserved, and so is its ownership,
+// where the process is allowed to set it. Of the options, only Sync
+// applies.
+func EditFile(path string, edit func(*Document) error, opts ...DotenvOption) error {
+	var cfg dotenvConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	doc, err := ParseDocument(data)
+	if err != nil {
+		return withPath(err, path)
+	}
+	if err := edit(doc); err != nil {
+		return err
+	}
+	out := doc.Bytes()
+	if bytes.Equal(out, data) {
+		return nil
+	}
+	return writeFileAtomic(path, func(f *os.File) error {
+		_, err := f.Write(out)
+		return err
+	}, cfg.sync)
+}
+
+// withPath records path in the parse errors in err.
+func withPath(err error, path string) error {
+	if errs, ok := err.(ErrorList); ok {
+		for _, e := range errs {
+			if perr, ok := e.(*ParseError); ok {
+				perr.Path = path
+			}
+		}
+	}
+	return err
+}
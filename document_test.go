@@ -0,0 +1,197 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"acln.ro/env"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const testDocument = `# Service configuration.
+
+export   VERSION=1.2.3   # bumped by release tooling
+  PORT=8080
+CERT=<<EOF
+line 1
+EOF
+
+# DEBUG=true
+NAME="my service"
+`
+
+func TestDocumentSet(t *testing.T) {
+	doc, err := env.ParseDocument([]byte(testDocument))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := doc.Lookup("VERSION"); v != "1.2.3" || !ok {
+		t.Errorf("Lookup(VERSION) = %q, %t", v, ok)
+	}
+	doc.Set("VERSION", "1.3.0")
+	doc.Set("PORT", "9090")
+	doc.Set("NEW", "a b")
+	want := `# Service configuration.
+
+export   VERSION=1.3.0   # bumped by release tooling
+  PORT=9090
+CERT=<<EOF
+line 1
+EOF
+
+# DEBUG=true
+NAME="my service"
+NEW="a b"
+`
+	if diff := cmp.Diff(string(doc.Bytes()), want); diff != "" {
+		t.Errorf("Set: %s", diff)
+	}
+	wantMap := env.Map{
+		"VERSION": "1.3.0",
+		"PORT":    "9090",
+		"CERT":    "line 1\n",
+		"NAME":    "my service",
+		"NEW":     "a b",
+	}
+	if diff := cmp.Diff(doc.Map(), wantMap); diff != "" {
+		t.Errorf("Map: %s", diff)
+	}
+}
+
+func TestDocumentUnsetComment(t *testing.T) {
+	doc, err := env.ParseDocument([]byte(testDocument))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !doc.Unset("NAME") || doc.Unset("MISSING") {
+		t.Error("Unset reported wrong result")
+	}
+	if !doc.Comment("CERT") {
+		t.Error("Comment(CERT) = false")
+	}
+	if !doc.Uncomment("DEBUG") || doc.Uncomment("PORT") {
+		t.Error("Uncomment reported wrong result")
+	}
+	want := `# Service configuration.
+
+export   VERSION=1.2.3   # bumped by release tooling
+  PORT=8080
+# CERT=<<EOF
+# line 1
+# EOF
+
+DEBUG=true
+`
+	if diff := cmp.Diff(string(doc.Bytes()), want); diff != "" {
+		t.Errorf("edited document: %s", diff)
+	}
+	if _, ok := doc.Lookup("CERT"); ok {
+		t.Error("commented-out variable still set")
+	}
+	if v, _ := doc.Lookup("DEBUG"); v != "true" {
+		t.Errorf("uncommented DEBUG = %q, want %q", v, "true")
+	}
+}
+
+func TestDocumentLineEndings(t *testing.T) {
+	doc, err := env.ParseDocument([]byte("A=1\r\nB=2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc.Set("A", "3")
+	doc.Set("C", "4")
+	if got, want := string(doc.Bytes()), "A=3\r\nB=2\r\nC=4\r\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestParseDocumentErrors(t *testing.T) {
+	_, err := env.ParseDocument([]byte("A=1\nBROKEN\n"))
+	var perr *env.ParseError
+	if !errors.As(err, &perr) || perr.Line != 2 {
+		t.Errorf("got %v, want *ParseError on line 2", err)
+	}
+}
+
+func TestEditFile(t *testing.T) {
+	dir := writeFiles(t, map[string]string{".env": testDocument})
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, ".env")
+	if err := os.Chmod(path, 0640); err != nil {
+		t.Fatal(err)
+	}
+	err := env.EditFile(path, func(doc *env.Document) error {
+		doc.Set("VERSION", "2.0.0")
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := env.ReadDotenvFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m["VERSION"] != "2.0.0" || m["PORT"] != "8080" {
+		t.Errorf("after EditFile: %v", m)
+	}
+	if string(data) != strings.Replace(testDocument, "1.2.3", "2.0.0", 1) {
+		t.Errorf("EditFile changed more than the edited line:\n%s", data)
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Mode().Perm() != 0640 {
+		t.Errorf("mode = %v, want 0640", fi.Mode().Perm())
+	}
+
+	errAbort := errors.New("abort")
+	err = env.EditFile(path, func(doc *env.Document) error {
+		doc.Set("VERSION", "3.0.0")
+		return errAbort
+	})
+	if err != errAbort {
+		t.Errorf("got %v, want %v", err, errAbort)
+	}
+	if m, _ := env.ReadDotenvFile(path); m["VERSION"] != "2.0.0" {
+		t.Error("failed edit was written")
+	}
+}
+
+func TestDocumentSetKeepsComments(t *testing.T) {
+	input := "A=#notcomment\nB= # empty\nC='q' # quoted\n"
+	doc, err := env.ParseDocument([]byte(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc.Set("A", "1")
+	doc.Set("B", "2")
+	doc.Set("C", "3")
+	want := "A=1\nB=2 # empty\nC=3 # quoted\n"
+	if got := string(doc.Bytes()); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
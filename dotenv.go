@@ -0,0 +1,504 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// WriteDotenv writes the Map to w in dotenv format: one "key=value" pair
+// per line, sorted lexicographically by key. Values which contain
+// whitespace, quotes, or other characters special to dotenv parsers or
+// shells are double-quoted, with '\\', '"', '$' and newlines escaped.
+func (m Map) WriteDotenv(w io.Writer, opts ...DotenvOption) error {
+	var cfg dotenvConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	bw := bufio.NewWriter(w)
+	for _, k := range m.keys() {
+		bw.WriteString(k)
+		bw.WriteByte('=')
+		if delim, ok := heredocDelim(m[k]); ok && cfg.heredocs {
+			bw.WriteString("<<" + delim + "\n" + m[k] + delim)
+		} else {
+			bw.WriteString(dotenvQuote(m[k]))
+		}
+		bw.WriteByte('\n')
+	}
+	return bw.Flush()
+}
+
+// A DotenvOption configures the writing of dotenv files.
+type DotenvOption func(*dotenvConfig)
+
+type dotenvConfig struct {
+	heredocs bool
+	sync     bool
+}
+
+// Heredocs causes multi-line values which end in a newline, such as PEM
+// certificates, to be written as heredocs, which keep the value readable:
+//
+//	TLS_CERT=<<EOF
+//	-----BEGIN CERTIFICATE-----
+//	...
+//	-----END CERTIFICATE-----
+//	EOF
+//
+// Values which contain carriage returns are quoted as usual.
+func Heredocs() DotenvOption {
+	return func(cfg *dotenvConfig) {
+		cfg.heredocs = true
+	}
+}
+
+// heredocDelim reports whether v can be written as a heredoc, and if so,
+// returns a delimiter which does not appear in v as a line of its own.
+func heredocDelim(v string) (string, bool) {
+	if !strings.HasSuffix(v, "\n") || strings.ContainsRune(v, '\r') {
+		return "", false
+	}
+	lines := make(map[string]bool)
+	for _, l := range strings.Split(v, "\n") {
+		lines[strings.TrimSpace(l)] = true
+	}
+	delim := "EOF"
+	for i := 1; lines[delim]; i++ {
+		delim = "EOF" + strconv.Itoa(i)
+	}
+	return delim, true
+}
+
+// dotenvQuote quotes v for use as a dotenv value, if necessary. Values
+// starting with "<<" are quoted, so that they are not read back as the
+// start of a heredoc.
+func dotenvQuote(v string) string {
+	if !strings.ContainsAny(v, " \t\r\n\"'`\\$#=") && !strings.HasPrefix(v, "<<") {
+		return v
+	}
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for _, r := range v {
+		switch r {
+		case '\\', '"', '$', '`':
+			sb.WriteByte('\\')
+			sb.WriteRune(r)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\r':
+			sb.WriteString(`\r`)
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
+
+// ReadDotenv reads variables in dotenv format from r. Each line holds a
+// "key=value" pair, optionally preceded by "export". Empty lines and
+// lines starting with '#' are ignored. Values may be quoted:
+//
+//	KEY=value            # unquoted; trailing comments are stripped
+//	KEY='literal $value' # single-quoted; no escapes are interpreted
+//	KEY="a\nb \$HOME"    # double-quoted; \n, \r, \t, \\, \", \$ and \` are interpreted
+//
+// Later definitions of a key override earlier ones. Variable references
+// are not expanded. Malformed lines are reported together, as an
+// ErrorList of *ParseError.
+//
+// Quoted values may span several lines. Values may also be written as
+// heredocs, which consist of the lines following "KEY=<<DELIM", up to a
+// line consisting of DELIM. Each line of a heredoc, including the last,
+// ends in a newline, and no escapes are interpreted:
+//
+//	TLS_CERT=<<EOF
+//	-----BEGIN CERTIFICATE-----
+//	MIIB...
+//	-----END CERTIFICATE-----
+//	EOF
+//
+// Lines of the form "#include path" are comments, unless the
+// FollowIncludes option is specified.
+func ReadDotenv(r io.Reader, opts ...ReadOption) (Map, error) {
+	return readDotenv(r, "", opts)
+}
+
+// ReadDotenvFile is like ReadDotenv, but reads from the named file.
+// Errors name the file.
+func ReadDotenvFile(path string, opts ...ReadOption) (Map, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return readDotenv(f, path, opts)
+}
+
+// A ReadOption configures the reading of dotenv files.
+type ReadOption func(*readConfig)
+
+type readConfig struct {
+	includes bool
+}
+
+// FollowIncludes causes lines of the form "#include path" to read the
+// dotenv file at path, as if its contents appeared in place of the
+// directive. The path may be double-quoted, using Go syntax. Relative
+// paths are resolved against the directory of the including file, or,
+// for input which ReadDotenv reads from r, against the working
+// directory. Include cycles, and includes nested more than 16 deep, are
+// errors.
+//
+// Includes read arbitrary files, so FollowIncludes should only be used
+// for trusted input.
+func FollowIncludes() ReadOption {
+	return func(cfg *readConfig) {
+		cfg.includes = true
+	}
+}
+
+func readDotenv(r io.Reader, path string, opts []ReadOption) (Map, error) {
+	var cfg readConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	d := &dotenvReader{m: make(Map), includes: cfg.includes}
+	if path != "" {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return nil, err
+		}
+		d.stack = []string{abs}
+	}
+	if err := d.read(r, path); err != nil {
+		return nil, err
+	}
+	if len(d.errs) > 0 {
+		return nil, d.errs
+	}
+	return d.m, nil
+}
+
+// maxIncludeDepth is the maximum nesting depth of #include directives.
+const maxIncludeDepth = 16
+
+// dotenvReader reads dotenv input, optionally following #include
+// directives.
+type dotenvReader struct {
+	m        Map
+	errs     ErrorList
+	includes bool     // follow #include directives
+	stack    []string // absolute paths of the files being read
+}
+
+// read reads dotenv input from r, which was read from path, if path is
+// not empty. Parse errors are recorded in d.errs. I/O errors on r are
+// returned.
+func (d *dotenvReader) read(r io.Reader, path string) error {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	for _, e := range scanDotenv(string(data)) {
+		switch {
+		case e.include != "":
+			if !d.includes {
+				continue
+			}
+			if err := d.include(path, e.include); err != nil {
+				d.errs = append(d.errs, &ParseError{Path: path, Line: e.line, Input: e.input(), Err: err})
+			}
+		case e.err != nil:
+			d.errs = append(d.errs, &ParseError{Path: path, Line: e.line, Input: e.input(), Err: e.err})
+		case e.key != "":
+			d.m[e.key] = e.value
+		}
+	}
+	return nil
+}
+
+// A dotenvEntry is a logical line of dotenv input: a blank line, a
+// comment, an #include directive, or an assignment, which may span
+// several physical lines.
+type dotenvEntry struct {
+	line    int    // number of the first physical line
+	raw     string // the physical lines, with their terminators
+	key     string // the variable assigned, for valid assignments
+	value   string // the value assigned
+	include string // the target, for #include directives
+	err     error  // the parse error, for malformed assignments
+}
+
+// input returns the first physical line of e, without surrounding space.
+func (e *dotenvEntry) input() string {
+	first := e.raw
+	if i := strings.IndexByte(first, '\n'); i != -1 {
+		first = first[:i]
+	}
+	return strings.TrimSpace(first)
+}
+
+// scanDotenv splits dotenv input into entries.
+func scanDotenv(data string) []dotenvEntry {
+	lines := strings.SplitAfter(data, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	var entries []dotenvEntry
+	for i := 0; i < len(lines); {
+		e := dotenvEntry{line: i + 1, raw: lines[i]}
+		first := trimEOL(lines[i])
+		s := strings.TrimSpace(first)
+		i++
+		switch {
+		case strings.HasPrefix(s, "#include ") || strings.HasPrefix(s, "#include\t"):
+			e.include = strings.TrimSpace(s[len("#include"):])
+		case s == "" || strings.HasPrefix(s, "#"):
+		default:
+			text := strings.TrimLeft(first, " \t")
+			k, v, heredoc, err := parseDotenvLine(text)
+			for err == errUnterminated && i < len(lines) {
+				text += "\n" + trimEOL(lines[i])
+				e.raw += lines[i]
+				i++
+				k, v, heredoc, err = parseDotenvLine(text)
+			}
+			if err == nil && heredoc {
+				delim := v
+				var sb strings.Builder
+				err = fmt.Errorf("unterminated heredoc: missing %s", delim)
+				for i < len(lines) {
+					l := trimEOL(lines[i])
+					e.raw += lines[i]
+					i++
+					if strings.TrimSpace(l) == delim {
+						err = nil
+						break
+					}
+					sb.WriteString(l)
+					sb.WriteByte('\n')
+				}
+				v = sb.String()
+			}
+			if err != nil {
+				e.err = err
+			} else {
+				e.key, e.value = k, v
+			}
+		}
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+// trimEOL removes the line terminator from line.
+func trimEOL(line string) string {
+	line = strings.TrimSuffix(line, "\n")
+	return strings.TrimSuffix(line, "\r")
+}
+
+// errUnterminated is returned by parseDotenvLine if a quoted value does
+// not end on the same line.
+var errUnterminated = errors.New("unterminated quoted value")
+
+// include reads the file named by target, relative to the directory of
+// the file at path, or to the working directory if path is empty.
+func (d *dotenvReader) include(path, target string) error {
+	if strings.HasPrefix(target, `"`) {
+		unquoted, err := strconv.Unquote(target)
+		if err != nil {
+			return fmt.Errorf("malformed include path %s", target)
+		}
+		target = unquoted
+	}
+	if target == "" {
+		return errors.New("missing include path")
+	}
+	if !filepath.IsAbs(target) && path != "" {
+		target = filepath.Join(filepath.Dir(path), target)
+	}
+	abs, err := filepath.Abs(target)
+	if err != nil {
+		return err
+	}
+	for i, p := range d.stack {
+		if p == abs {
+			cycle := append(append([]string(nil), d.stack[i:]...), abs)
+			return fmt.Errorf("include cycle: %s", strings.Join(cycle, " -> "))
+		}
+	}
+	if len(d.stack) >= maxIncludeDepth {
+		return fmt.Errorf("includes nested more than %d deep", maxIncludeDepth)
+	}
+	f, err := os.Open(target)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	d.stack = append(d.stack, abs)
+	defer func() { d.stack = d.stack[:len(d.stack)-1] }()
+	return d.read(f, target)
+}
+
+// parseDotenvLine parses a non-empty, non-comment line of dotenv input.
+// If the value is quoted, s may span several lines. If the value is the
+// start of a heredoc, parseDotenvLine returns the delimiter as the value,
+// and sets heredoc.
+func parseDotenvLine(s string) (key, value string, heredoc bool, err error) {
+	if strings.HasPrefix(s, "export ") || strings.HasPrefix(s, "export\t") {
+		s = strings.TrimLeft(s[len("export"):], " \t")
+	}
+	eq := strings.IndexByte(s, '=')
+	if eq == -1 {
+		return "", "", false, errors.New("missing '='")
+	}
+	key = strings.TrimSpace(s[:eq])
+	if key == "" || strings.ContainsAny(key, " \t\"'") {
+		return "", "", false, fmt.Errorf("invalid variable name %q", key)
+	}
+	raw := s[eq+1:]
+	trimmed := strings.TrimLeft(raw, " \t")
+	if strings.HasPrefix(trimmed, "#") && len(trimmed) < len(raw) {
+		return key, "", false, nil // empty value followed by a comment
+	}
+	if strings.HasPrefix(trimmed, "<<") {
+		delim := strings.TrimSpace(trimmed[2:])
+		if !isHeredocDelim(delim) {
+			return "", "", false, fmt.Errorf("invalid heredoc delimiter %q", delim)
+		}
+		return key, delim, true, nil
+	}
+	value, rest, err := parseDotenvValue(trimmed)
+	if err != nil {
+		return "", "", false, err
+	}
+	if rest = strings.TrimSpace(rest); rest != "" && !strings.HasPrefix(rest, "#") {
+		return "", "", false, fmt.Errorf("trailing data %q after value", rest)
+	}
+	return key, value, false, nil
+}
+
+// isHeredocDelim reports whether s is a valid heredoc delimiter: a
+// non-empty sequence of letters, digits and underscores.
+func isHeredocDelim(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+// parseDotenvValue parses a dotenv value at the start of s, and returns
+// the value and the remainder of s.
+func parseDotenvValue(s string) (value, rest string, err error) {
+	if s == "" {
+		return "", "", nil
+	}
+	switch s[0] {
+	case '\'':
+		end := strings.IndexByte(s[1:], '\'')
+		if end == -1 {
+			return "", "", errUnterminated
+		}
+		return s[1 : end+1], s[end+2:], nil
+	case '"':
+		var sb strings.Builder
+		for i := 1; i < len(s); i++ {
+			switch c := s[i]; c {
+			case '"':
+				return sb.String(), s[i+1:], nil
+			case '\\':
+				if i+1 == len(s) {
+					return "", "", errUnterminated
+				}
+				i++
+				sb.WriteString(dotenvUnescape(s[i]))
+			default:
+				sb.WriteByte(c)
+			}
+		}
+		return "", "", errUnterminated
+	default:
+		for i := 0; i < len(s); i++ {
+			if s[i] == '#' && i > 0 && (s[i-1] == ' ' || s[i-1] == '\t') {
+				return strings.TrimRight(s[:i], " \t"), s[i:], nil
+			}
+		}
+		return strings.TrimRight(s, " \t"), "", nil
+	}
+}
+
+// dotenvUnescape returns the character represented by the escape
+// sequence '\\' c in a double-quoted dotenv value. Unknown escape
+// sequences are preserved.
+func dotenvUnescape(c byte) string {
+	switch c {
+	case 'n':
+		return "\n"
+	case 'r':
+		return "\r"
+	case 't':
+		return "\t"
+	case '\\', '"', '$', '`':
+		return string(c)
+	case '\n':
+		return "" // line continuation
+	default:
+		return "\\" + string(c)
+	}
+}
+
+// DotenvFile is a Source which reads variables from a file in dotenv
+// format, as ReadDotenvFile does.
+type DotenvFile struct {
+	Path string
+
+	// Optional, if true, causes a missing file to provide no variables,
+	// rather than to fail.
+	Optional bool
+
+	// FollowIncludes, if true, causes #include directives to be
+	// followed, as by the FollowIncludes option.
+	FollowIncludes bool
+}
+
+// Environ implements Source.
+func (f DotenvFile) Environ(ctx context.Context) (Map, error) {
+	var opts []ReadOption
+	if f.FollowIncludes {
+		opts = append(opts, FollowIncludes())
+	}
+	m, err := ReadDotenvFile(f.Path, opts...)
+	if os.IsNotExist(err) && f.Optional {
+		return Map{}, nil
+	}
+	return m, err
+}
@@ -0,0 +1,293 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"acln.ro/env"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWriteDotenv(t *testing.T) {
+	m := env.Map{
+		"PLAIN":   "value",
+		"EMPTY":   "",
+		"SPACES":  "hello world",
+		"QUOTES":  `say "hi"`,
+		"DOLLAR":  "$HOME",
+		"NEWLINE": "a\nb",
+	}
+	sb := new(strings.Builder)
+	if err := m.WriteDotenv(sb); err != nil {
+		t.Fatal(err)
+	}
+	want := `DOLLAR="\$HOME"
+EMPTY=
+NEWLINE="a\nb"
+PLAIN=value
+QUOTES="say \"hi\""
+SPACES="hello world"
+`
+	if diff := cmp.Diff(sb.String(), want); diff != "" {
+		t.Errorf("WriteDotenv: got %q, want %q: %s", sb.String(), want, diff)
+	}
+}
+
+func TestReadDotenv(t *testing.T) {
+	input := `# comment
+PLAIN=value
+export EXPORTED=yes
+SPACED = padded value   # trailing comment
+HASH=a#b
+EMPTY=
+COMMENTED= # nothing
+SINGLE='literal \n $HOME'
+DOUBLE="a\nb \"q\" \$HOME"
+DOUBLE_COMMENT="x" # comment
+PLAIN=override
+`
+	got, err := env.ReadDotenv(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := env.Map{
+		"PLAIN":          "override",
+		"EXPORTED":       "yes",
+		"SPACED":         "padded value",
+		"HASH":           "a#b",
+		"EMPTY":          "",
+		"COMMENTED":      "",
+		"SINGLE":         `literal \n $HOME`,
+		"DOUBLE":         "a\nb \"q\" $HOME",
+		"DOUBLE_COMMENT": "x",
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("ReadDotenv: %s", diff)
+	}
+}
+
+func TestReadDotenvErrors(t *testing.T) {
+	input := "OK=1\nNOEQUALS\nBAD KEY=1\nTRAILING=\"a\" b\nOPEN=\"abc\n"
+	_, err := env.ReadDotenv(strings.NewReader(input))
+	var errs env.ErrorList
+	if !errors.As(err, &errs) {
+		t.Fatalf("got %v, want ErrorList", err)
+	}
+	var lines []int
+	for _, err := range errs {
+		var perr *env.ParseError
+		if !errors.As(err, &perr) {
+			t.Fatalf("got %v, want *ParseError", err)
+		}
+		lines = append(lines, perr.Line)
+	}
+	if diff := cmp.Diff(lines, []int{2, 3, 4, 5}); diff != "" {
+		t.Errorf("error lines: %s", diff)
+	}
+}
+
+func TestDotenvRoundTrip(t *testing.T) {
+	m := env.Map{
+		"PLAIN":   "value",
+		"EMPTY":   "",
+		"SPACES":  "  hello world  ",
+		"QUOTES":  `say "hi" and 'bye'`,
+		"DOLLAR":  "$HOME and `cmd`",
+		"NEWLINE": "a\nb\r\n",
+		"HASH":    "x # y",
+		"BACKSL":  `C:\path\n`,
+		"HEREDOC": "<<EOF",
+		"SHIFT":   "<<",
+		"AFTER":   "x",
+	}
+	for _, opts := range [][]env.DotenvOption{nil, {env.Heredocs()}} {
+		sb := new(strings.Builder)
+		if err := m.WriteDotenv(sb, opts...); err != nil {
+			t.Fatal(err)
+		}
+		got, err := env.ReadDotenv(strings.NewReader(sb.String()))
+		if err != nil {
+			t.Fatalf("reading %q: %v", sb.String(), err)
+		}
+		if diff := cmp.Diff(got, m); diff != "" {
+			t.Errorf("round trip through %q: %s", sb.String(), diff)
+		}
+	}
+}
+
+func TestReadDotenvFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "env-dotenv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, ".env")
+	if err := ioutil.WriteFile(path, []byte("A=1\nB\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	_, err = env.ReadDotenvFile(path)
+	if err == nil || !strings.Contains(err.Error(), path+":2") {
+		t.Errorf("got %v, want error naming %s:2", err, path)
+	}
+}
+
+func TestDotenvFileOptional(t *testing.T) {
+	missing := filepath.Join(os.TempDir(), "env-definitely-missing.env")
+	m, err := env.DotenvFile{Path: missing, Optional: true}.Environ(context.Background())
+	if err != nil || len(m) != 0 {
+		t.Errorf("optional missing file: got %v, %v, want empty Map", m, err)
+	}
+	if _, err := (env.DotenvFile{Path: missing}).Environ(context.Background()); err == nil {
+		t.Error("required missing file: got nil error")
+	}
+}
+
+func TestReadDotenvInclude(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"main.env":    "A=main\n#include shared.env\nC=main\n",
+		"shared.env":  "A=shared\nB=shared\nC=shared\n#include \"sub dir.env\"\n",
+		"sub dir.env": "D=sub\n",
+		"cycle1.env":  "X=1\n#include cycle2.env\n",
+		"cycle2.env":  "#include cycle1.env\n",
+		"self.env":    "#include self.env\n",
+		"missing.env": "#include nope.env\n",
+	})
+	defer os.RemoveAll(dir)
+
+	got, err := env.ReadDotenvFile(filepath.Join(dir, "main.env"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(got, env.Map{"A": "main", "C": "main"}); diff != "" {
+		t.Errorf("without FollowIncludes: %s", diff)
+	}
+
+	got, err = env.ReadDotenvFile(filepath.Join(dir, "main.env"), env.FollowIncludes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := env.Map{"A": "shared", "B": "shared", "C": "main", "D": "sub"}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("include: %s", diff)
+	}
+	got, err = env.DotenvFile{Path: filepath.Join(dir, "main.env"), FollowIncludes: true}.Environ(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("DotenvFile include: %s", diff)
+	}
+
+	for _, name := range []string{"cycle1.env", "self.env", "missing.env"} {
+		_, err := env.ReadDotenvFile(filepath.Join(dir, name), env.FollowIncludes())
+		var perr *env.ParseError
+		if !errors.As(err, &perr) {
+			t.Errorf("%s: got %v, want *ParseError", name, err)
+		}
+	}
+	_, err = env.ReadDotenvFile(filepath.Join(dir, "cycle1.env"), env.FollowIncludes())
+	if err == nil || !strings.Contains(err.Error(), "include cycle") {
+		t.Errorf("cycle: got %v, want include cycle error", err)
+	}
+}
+
+func TestReadDotenvIncludeDepth(t *testing.T) {
+	files := make(map[string]string)
+	for i := 0; i < 20; i++ {
+		files[fmt.Sprintf("%d.env", i)] = fmt.Sprintf("V%d=1\n#include %d.env\n", i, i+1)
+	}
+	files["20.env"] = "LAST=1\n"
+	dir := writeFiles(t, files)
+	defer os.RemoveAll(dir)
+	_, err := env.ReadDotenvFile(filepath.Join(dir, "0.env"), env.FollowIncludes())
+	if err == nil || !strings.Contains(err.Error(), "nested") {
+		t.Errorf("got %v, want nesting depth error", err)
+	}
+}
+
+const testPEM = `-----BEGIN CERTIFICATE-----
+MIIBszCCAVmgAwIBAgIUQ
+  indented $NOT_EXPANDED "quotes" \n
+-----END CERTIFICATE-----
+`
+
+func TestReadDotenvMultiline(t *testing.T) {
+	input := "CERT=<<PEM\n" + testPEM + "PEM\n" +
+		"JSON='{\n  \"a\": 1\n}'\n" +
+		"DOUBLE=\"line 1\nline 2 \\\"q\\\"\"  # comment\n" +
+		"CONT=\"abc\\\ndef\"\n" +
+		"AFTER=1\n"
+	got, err := env.ReadDotenv(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := env.Map{
+		"CERT":   testPEM,
+		"JSON":   "{\n  \"a\": 1\n}",
+		"DOUBLE": "line 1\nline 2 \"q\"",
+		"CONT":   "abcdef",
+		"AFTER":  "1",
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("ReadDotenv: %s", diff)
+	}
+}
+
+func TestReadDotenvHeredocErrors(t *testing.T) {
+	for _, input := range []string{
+		"CERT=<<EOF\nabc\n",
+		"CERT=<<\nabc\n",
+		"CERT=<<E-O-F\nabc\nE-O-F\n",
+	} {
+		if _, err := env.ReadDotenv(strings.NewReader(input)); err == nil {
+			t.Errorf("ReadDotenv(%q) succeeded", input)
+		}
+	}
+}
+
+func TestDotenvHeredocRoundTrip(t *testing.T) {
+	m := env.Map{
+		"CERT":     testPEM,
+		"TRICKY":   "EOF\nEOF1\n",
+		"NOTRAIL":  "a\nb",
+		"CRLF":     "a\r\nb\r\n",
+		"PLAIN":    "value",
+		"QUOTED":   "a b",
+		"JSONBLOB": "{\n\t\"key\": \"value\"\n}\n",
+	}
+	sb := new(strings.Builder)
+	if err := m.WriteDotenv(sb, env.Heredocs()); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(sb.String(), "CERT=<<EOF\n-----BEGIN CERTIFICATE-----\n") {
+		t.Errorf("CERT not written as heredoc:\n%s", sb.String())
+	}
+	got, err := env.ReadDotenv(strings.NewReader(sb.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(got, m); diff != "" {
+		t.Errorf("round trip through\n%s: %s", sb.String(), diff)
+	}
+}
@@ -0,0 +1,186 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+)
+
+// Encrypted values are of the form "enc:v1:<base64>", and encrypted files
+// start with "env-encrypted-v1:", followed by base64. In both cases, the
+// base64 payload is a random nonce followed by the AES-256-GCM sealed
+// plaintext. Values are sealed with the name of their variable as
+// additional data, such that an encrypted value cannot be moved to a
+// different variable. Files are sealed with their prefix as additional
+// data.
+const encFilePrefix = "env-encrypted-v1:"
+
+// EncryptedValuePrefix is the prefix of values produced by
+// Key.EncryptValue. Tools can use it to recognize encrypted values
+// without decrypting them.
+const EncryptedValuePrefix = "enc:v1:"
+
+// A Key is a symmetric key for encrypting environment values and files.
+type Key [32]byte
+
+// GenerateKey generates a new random Key.
+func GenerateKey() (*Key, error) {
+	k := new(Key)
+	if _, err := io.ReadFull(rand.Reader, k[:]); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// ParseKey parses a Key in the format produced by Key.String.
+func ParseKey(s string) (*Key, error) {
+	b, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return nil, fmt.Errorf("env: malformed key: %v", err)
+	}
+	k := new(Key)
+	if len(b) != len(k) {
+		return nil, fmt.Errorf("env: key is %d bytes long, want %d", len(b), len(k))
+	}
+	copy(k[:], b)
+	return k, nil
+}
+
+// String returns the base64 encoding of k.
+func (k *Key) String() string {
+	return base64.StdEncoding.EncodeToString(k[:])
+}
+
+// EncryptValue encrypts v, the value of the variable named by key,
+// returning a value suitable for storing in an environment file.
+func (k *Key) EncryptValue(key, v string) (string, error) {
+	sealed, err := k.seal([]byte(v), []byte(key))
+	if err != nil {
+		return "", err
+	}
+	return EncryptedValuePrefix + sealed, nil
+}
+
+// DecryptValue decrypts a value produced by EncryptValue for the
+// variable named by key. Decryption fails if the value was encrypted for
+// a different variable.
+func (k *Key) DecryptValue(key, v string) (string, error) {
+	if !strings.HasPrefix(v, EncryptedValuePrefix) {
+		return "", errors.New("env: value is not encrypted")
+	}
+	b, err := k.open(v[len(EncryptedValuePrefix):], []byte(key))
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// EncryptFile encrypts the contents of an environment file as a whole,
+// returning the encrypted contents, which can be read by LoadEncrypted.
+func (k *Key) EncryptFile(data []byte) ([]byte, error) {
+	sealed, err := k.seal(data, []byte(encFilePrefix))
+	if err != nil {
+		return nil, err
+	}
+	return []byte(encFilePrefix + sealed + "\n"), nil
+}
+
+// LoadEncrypted loads the environment file at path, decrypting it using
+// identity.
+//
+// The file may be encrypted as a whole, as produced by Key.EncryptFile.
+// Otherwise, it is read as a plain environment file, in which each value
+// produced by Key.EncryptValue is decrypted, and the other values are
+// used as they are.
+//
+// The plain contents are read in dotenv format, as by ReadDotenvFile.
+func LoadEncrypted(path string, identity *Key) (Map, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.HasPrefix(data, []byte(encFilePrefix)) {
+		sealed := strings.TrimSpace(string(data[len(encFilePrefix):]))
+		data, err = identity.open(sealed, []byte(encFilePrefix))
+		if err != nil {
+			return nil, fmt.Errorf("env: %s: %v", path, err)
+		}
+	}
+	m, err := readDotenv(bytes.NewReader(data), path, nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range m {
+		if !strings.HasPrefix(v, EncryptedValuePrefix) {
+			continue
+		}
+		dv, err := identity.DecryptValue(k, v)
+		if err != nil {
+			return nil, fmt.Errorf("env: %s: %s: %v", path, k, err)
+		}
+		m[k] = dv
+	}
+	return m, nil
+}
+
+func (k *Key) aead() (cipher.AEAD, error) {
+	block, err := aes.NewCipher(k[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func (k *Key) seal(plaintext, ad []byte) (string, error) {
+	aead, err := k.aead()
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nonce, nonce, plaintext, ad)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func (k *Key) open(s string, ad []byte) ([]byte, error) {
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("malformed ciphertext: %v", err)
+	}
+	aead, err := k.aead()
+	if err != nil {
+		return nil, err
+	}
+	if len(b) < aead.NonceSize() {
+		return nil, errors.New("malformed ciphertext: too short")
+	}
+	nonce, ciphertext := b[:aead.NonceSize()], b[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, ad)
+	if err != nil {
+		return nil, errors.New("decryption failed: wrong key or corrupted data")
+	}
+	return plaintext, nil
+}
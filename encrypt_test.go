@@ -0,0 +1,112 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"acln.ro/env"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestKeyString(t *testing.T) {
+	k, err := env.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := env.ParseKey(k.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *parsed != *k {
+		t.Errorf("ParseKey(%q) = %v, want %v", k, parsed, k)
+	}
+	for _, s := range []string{"", "not base64!", "c2hvcnQ="} {
+		if _, err := env.ParseKey(s); err == nil {
+			t.Errorf("ParseKey(%q) succeeded", s)
+		}
+	}
+}
+
+func TestLoadEncrypted(t *testing.T) {
+	k, err := env.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := env.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	encpass, err := k.EncryptValue("DB_PASS", "hunter2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir, err := ioutil.TempDir("", "env-encrypt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	plain := "# database settings\nDB_USER=app\n\nDB_PASS=" + encpass + "\n"
+	want := env.Map{"DB_USER": "app", "DB_PASS": "hunter2"}
+
+	valuesPath := filepath.Join(dir, "values.env")
+	if err := ioutil.WriteFile(valuesPath, []byte(plain), 0600); err != nil {
+		t.Fatal(err)
+	}
+	whole, err := k.EncryptFile([]byte(plain))
+	if err != nil {
+		t.Fatal(err)
+	}
+	wholePath := filepath.Join(dir, "whole.env")
+	if err := ioutil.WriteFile(wholePath, whole, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range []string{valuesPath, wholePath} {
+		got, err := env.LoadEncrypted(path, k)
+		if err != nil {
+			t.Errorf("LoadEncrypted(%s): %v", path, err)
+			continue
+		}
+		if diff := cmp.Diff(got, want); diff != "" {
+			t.Errorf("LoadEncrypted(%s) = %v, want %v: %s", path, got, want, diff)
+		}
+		if _, err := env.LoadEncrypted(path, other); err == nil {
+			t.Errorf("LoadEncrypted(%s) succeeded with the wrong key", path)
+		}
+	}
+}
+
+func TestDecryptValueKey(t *testing.T) {
+	k, err := env.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := k.EncryptValue("DB_PASS", "hunter2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := k.DecryptValue("DB_PASS", v); err != nil || got != "hunter2" {
+		t.Errorf("DecryptValue(DB_PASS) = %q, %v, want %q", got, err, "hunter2")
+	}
+	if _, err := k.DecryptValue("API_TOKEN", v); err == nil {
+		t.Error("DecryptValue succeeded for a value moved to a different variable")
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"acln.ro/env"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestEnum(t *testing.T) {
+	levels := []string{"debug", "info", "warn", "error"}
+	tests := []struct {
+		value  string
+		secret bool
+		reason string
+	}{
+		{value: "info"},
+		{value: "inf", reason: `value "inf" is not one of debug, info, warn, error; did you mean info?`},
+		{value: "WARN", reason: `value "WARN" is not one of debug, info, warn, error; did you mean warn?`},
+		{value: "eror", reason: `value "eror" is not one of debug, info, warn, error; did you mean error?`},
+		{value: "verbose", reason: `value "verbose" is not one of debug, info, warn, error`},
+		{value: "", reason: `value "" is not one of debug, info, warn, error`},
+		{value: "inf", secret: true, reason: `value is not one of debug, info, warn, error`},
+	}
+	for _, tt := range tests {
+		schema := &env.Schema{Vars: []env.Var{{Key: "LOG_LEVEL", Enum: levels, Secret: tt.secret}}}
+		err := schema.Validate(env.Map{"LOG_LEVEL": tt.value})
+		if tt.reason == "" {
+			if err != nil {
+				t.Errorf("%q: %v", tt.value, err)
+			}
+			continue
+		}
+		var verr *env.ValidationError
+		if !errors.As(err, &verr) {
+			t.Errorf("%q: got %v, want ValidationError", tt.value, err)
+			continue
+		}
+		if verr.Reason != tt.reason {
+			t.Errorf("%q: got reason %q, want %q", tt.value, verr.Reason, tt.reason)
+		}
+	}
+}
+
+func TestDecodeOneOf(t *testing.T) {
+	var cfg struct {
+		Level string `env:"LOG_LEVEL" default:"info" validate:"one_of=debug,info,warn,error"`
+	}
+	if err := env.Decode(env.Map{}, &cfg); err != nil || cfg.Level != "info" {
+		t.Errorf("Decode = %q, %v", cfg.Level, err)
+	}
+	if err := env.Decode(env.Map{"LOG_LEVEL": "trace"}, &cfg); err == nil {
+		t.Error("Decode with value not in enum succeeded")
+	}
+}
+
+func TestJSONSchemaEnum(t *testing.T) {
+	schema := &env.Schema{Vars: []env.Var{{Key: "LOG_LEVEL", Enum: []string{"debug", "info"}}}}
+	data, err := schema.JSONSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		Properties map[string]struct {
+			Enum []string `json:"enum"`
+		} `json:"properties"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"debug", "info"}, doc.Properties["LOG_LEVEL"].Enum); diff != "" {
+		t.Errorf("(-want +got)\n%s", diff)
+	}
+}
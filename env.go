@@ -21,7 +21,9 @@ import (
 	"io"
 	"os"
 	"sort"
+	"strconv"
 	"strings"
+	"unicode/utf8"
 )
 
 // Map is a convenient representation of a set of environment variables.
@@ -31,38 +33,142 @@ type Map map[string]string
 // lexicographically by key.
 func (m Map) String() string {
 	sb := new(strings.Builder)
-	m.print(sb, ' ')
+	sb.Grow(m.encodedSize())
+	m.print(sb, ' ', m.keys())
 	return sb.String()
 }
 
 // Format implements fmt.Formatter for Map as follows:
 //
-// If the verb is anything but 'v', Format produces no output.
+// The 'v' verb emits "key=value" pairs. If the '+' flag is specified, the
+// pairs are separated by newlines. Otherwise, they are separated by
+// spaces. If the '#' flag is specified, Format emits a Go literal for the
+// Map instead, such as env.Map{"BAR":"y", "FOO":"x"}.
 //
-// If the '+' flag is specified, Format emits newline separated "key=value"
-// pairs. Otherwise, it emits space-separated "key=value" pairs.
+// The 'q' verb emits "key"="value" pairs, where both the key and the
+// value are quoted using Go syntax, such that spaces and control
+// characters are visible. The '+' flag selects newline separated pairs,
+// like for 'v'.
+//
+// The 'r' verb is like 'v', but redacts the values of variables marked
+// as secret by DefaultPolicy. It is a safer choice for logging.
+//
+// For the 'v', 'q' and 'r' verbs, a precision elides values longer than
+// the precision, in bytes, as Elided does: %.64v prints at most 64 bytes of
+// each value.
+//
+// For any other verb, Format produces no output.
 //
 // Values are sorted lexicographically by key.
 func (m Map) Format(s fmt.State, verb rune) {
-	if verb != 'v' {
-		return
-	}
+	sep := byte(' ')
 	if s.Flag('+') {
-		m.print(s, '\n')
-	} else {
-		m.print(s, ' ')
+		sep = '\n'
+	}
+	if n, ok := s.Precision(); ok && !s.Flag('#') {
+		m = m.Elided(n)
+	}
+	switch {
+	case verb == 'v' && s.Flag('#'):
+		io.WriteString(s, m.GoString())
+	case verb == 'v':
+		m.print(s, sep, m.keys())
+	case verb == 'q':
+		m.printQuoted(s, sep, m.keys())
+	case verb == 'r':
+		rm := m.Redact(DefaultPolicy)
+		rm.print(s, sep, rm.keys())
 	}
 }
 
-func (m Map) print(w io.Writer, sep rune) {
-	i := 0
-	for _, k := range m.keys() {
-		fmt.Fprintf(w, "%s=%s", k, m[k])
-		if i < len(m)-1 {
-			fmt.Fprintf(w, "%c", sep)
+func (m Map) printQuoted(w io.Writer, sep byte, keys []string) {
+	seps := string(sep)
+	for i, k := range keys {
+		if i > 0 {
+			io.WriteString(w, seps)
+		}
+		io.WriteString(w, strconv.Quote(k))
+		io.WriteString(w, "=")
+		io.WriteString(w, strconv.Quote(m[k]))
+	}
+}
+
+// GoString returns a Go literal for m, sorted lexicographically by key.
+func (m Map) GoString() string {
+	sb := new(strings.Builder)
+	sb.WriteString("env.Map{")
+	for i, k := range m.keys() {
+		if i > 0 {
+			sb.WriteString(", ")
 		}
-		i++
+		sb.WriteString(strconv.Quote(k))
+		sb.WriteByte(':')
+		sb.WriteString(strconv.Quote(m[k]))
 	}
+	sb.WriteByte('}')
+	return sb.String()
+}
+
+// Quoted returns a copy of m in which every value is quoted using Go
+// syntax, such that trailing spaces, tabs, and other invisible characters
+// show up when the copy is printed. Unlike the 'q' verb of Format, Quoted
+// leaves keys as they are, and composes with other ways of printing a Map:
+//
+//	log.Printf("child env: %+v", m.Quoted().Redact(env.DefaultPolicy))
+func (m Map) Quoted() Map {
+	qm := make(Map, len(m))
+	for k, v := range m {
+		qm[k] = strconv.Quote(v)
+	}
+	return qm
+}
+
+// Elided returns a copy of m in which values longer than n bytes are
+// truncated to at most n bytes, followed by an ellipsis and the length of
+// the full value, as in "-----BEGIN CERTIFICATE-----...[1190 bytes]".
+// Values are truncated at a UTF-8 character boundary. Elided is intended
+// for logging environments which hold huge values, such as JSON documents
+// or certificates.
+func (m Map) Elided(n int) Map {
+	em := make(Map, len(m))
+	for k, v := range m {
+		em[k] = elide(v, n)
+	}
+	return em
+}
+
+// elide truncates v to at most n bytes, as described by Map.Elided.
+func elide(v string, n int) string {
+	if len(v) <= n {
+		return v
+	}
+	i := n
+	for i > 0 && !utf8.RuneStart(v[i]) {
+		i--
+	}
+	return v[:i] + "...[" + strconv.Itoa(len(v)) + " bytes]"
+}
+
+func (m Map) print(w io.Writer, sep byte, keys []string) {
+	seps := string(sep)
+	for i, k := range keys {
+		if i > 0 {
+			io.WriteString(w, seps)
+		}
+		io.WriteString(w, k)
+		io.WriteString(w, "=")
+		io.WriteString(w, m[k])
+	}
+}
+
+// encodedSize returns the size of the "key=value" encodings of all pairs
+// in m, plus one separator byte for each pair.
+func (m Map) encodedSize() int {
+	size := 0
+	for k, v := range m {
+		size += len(k) + 1 + len(v) + 1
+	}
+	return size
 }
 
 func (m Map) keys() []string {
@@ -75,16 +181,75 @@ func (m Map) keys() []string {
 }
 
 // Encode encodes the Map as a slice of "key=value" pairs, suitable for use
-// with the os/exec package.
-func (m Map) Encode() []string {
-	kvs := make([]string, 0, len(m))
-	for _, k := range m.keys() {
-		kv := fmt.Sprintf("%s=%s", k, m[k])
-		kvs = append(kvs, kv)
+// with the os/exec package. By default, pairs are sorted lexicographically
+// by key.
+func (m Map) Encode(opts ...EncodeOption) []string {
+	keys := m.orderedKeys(opts)
+	// Write all pairs to a single buffer, and slice the pairs out of it,
+	// so that there is one allocation for the contents of all pairs,
+	// rather than one for each of them.
+	sb := new(strings.Builder)
+	sb.Grow(m.encodedSize())
+	for _, k := range keys {
+		sb.WriteString(k)
+		sb.WriteByte('=')
+		sb.WriteString(m[k])
+	}
+	buf := sb.String()
+	kvs := make([]string, len(keys))
+	off := 0
+	for i, k := range keys {
+		n := len(k) + 1 + len(m[k])
+		kvs[i] = buf[off : off+n]
+		off += n
 	}
 	return kvs
 }
 
+// EncodeExec encodes the Map as a contiguous block of NUL-terminated
+// "key=value" pairs, followed by an additional NUL byte, which is the
+// layout execve-style interfaces build their environment from. The block
+// for an empty Map is a single NUL byte. Pairs are ordered as by Encode.
+// Keys and values must not contain NUL bytes, which would truncate them.
+func (m Map) EncodeExec(opts ...EncodeOption) []byte {
+	keys := m.orderedKeys(opts)
+	buf := make([]byte, 0, m.encodedSize()+1)
+	for _, k := range keys {
+		buf = append(buf, k...)
+		buf = append(buf, '=')
+		buf = append(buf, m[k]...)
+		buf = append(buf, 0)
+	}
+	return append(buf, 0)
+}
+
+// ExecPointers returns pointers to the start of each pair in block, as
+// returned by EncodeExec, followed by a nil pointer. The result is the
+// envp array expected by execve and posix_spawn, and points into block,
+// which must be kept alive while it is in use. Callers passing it to C
+// code through cgo must copy block and the pointers to C memory first.
+func ExecPointers(block []byte) []*byte {
+	var ptrs []*byte
+	for i := 0; i < len(block) && block[i] != 0; i++ {
+		ptrs = append(ptrs, &block[i])
+		for i < len(block) && block[i] != 0 {
+			i++
+		}
+	}
+	return append(ptrs, nil)
+}
+
+// MustGet returns the value of the variable named by key. It panics with
+// a message naming the variable if it is not set. MustGet is intended for
+// use during program initialization.
+func (m Map) MustGet(key string) string {
+	v, ok := m[key]
+	if !ok {
+		panic(notSet(key))
+	}
+	return v
+}
+
 // Diff computes differences between m and n.
 func (m Map) Diff(n Map) Diff {
 	d := Diff{}
@@ -123,6 +288,52 @@ type Diff struct {
 	OnlyInN Map
 }
 
+// Empty reports whether d describes no differences.
+func (d Diff) Empty() bool {
+	return len(d.OnlyInM) == 0 && len(d.Changes) == 0 && len(d.OnlyInN) == 0
+}
+
+// Summary returns counts of the differences described by d, considering
+// N to be the newer environment.
+func (d Diff) Summary() DiffSummary {
+	return DiffSummary{
+		Added:   len(d.OnlyInN),
+		Removed: len(d.OnlyInM),
+		Changed: len(d.Changes),
+	}
+}
+
+// DiffSummary counts differences between two environments.
+type DiffSummary struct {
+	Added   int // variables only in N
+	Removed int // variables only in M
+	Changed int // variables with different values
+}
+
+// String returns a short summary, such as "2 added, 1 removed, 0 changed".
+func (s DiffSummary) String() string {
+	return fmt.Sprintf("%d added, %d removed, %d changed", s.Added, s.Removed, s.Changed)
+}
+
+// GoString returns a Go literal for d. Nil fields are omitted.
+func (d Diff) GoString() string {
+	var fields []string
+	if d.OnlyInM != nil {
+		fields = append(fields, "OnlyInM:"+d.OnlyInM.GoString())
+	}
+	if d.Changes != nil {
+		changes := make([]string, len(d.Changes))
+		for i, c := range d.Changes {
+			changes[i] = c.GoString()
+		}
+		fields = append(fields, "Changes:[]env.Change{"+strings.Join(changes, ", ")+"}")
+	}
+	if d.OnlyInN != nil {
+		fields = append(fields, "OnlyInN:"+d.OnlyInN.GoString())
+	}
+	return "env.Diff{" + strings.Join(fields, ", ") + "}"
+}
+
 // Change describes a change in a value in the environment.
 type Change struct {
 	Key    string
@@ -134,6 +345,11 @@ func (c Change) String() string {
 	return fmt.Sprintf("%s: %s -> %s", c.Key, c.MValue, c.NValue)
 }
 
+// GoString returns a Go literal for c.
+func (c Change) GoString() string {
+	return fmt.Sprintf("env.Change{Key:%q, MValue:%q, NValue:%q}", c.Key, c.MValue, c.NValue)
+}
+
 // Variables returns a Map of the process environment.
 func Variables() Map {
 	return Parse(os.Environ()...)
@@ -141,16 +357,16 @@ func Variables() Map {
 
 // Parse parses a list of environment variables in "key=value" format.
 // Values not in "key=value" format are ignored.
+//
+// The keys and values in the returned Map share memory with kvs.
 func Parse(kvs ...string) Map {
-	m := make(Map)
+	m := make(Map, len(kvs))
 	for _, kv := range kvs {
-		i := strings.IndexRune(kv, '=')
+		i := strings.IndexByte(kv, '=')
 		if i == -1 {
 			continue
 		}
-		k := kv[:i]
-		v := kv[i+1:]
-		m[k] = v
+		m[kv[:i]] = kv[i+1:]
 	}
 	return m
 }
@@ -166,3 +382,28 @@ func Merge(maps ...Map) Map {
 	}
 	return merged
 }
+
+// CaseCollisions returns groups of keys in m which are identical modulo
+// case, such as PATH, Path and path. Such keys name distinct variables on
+// Unix systems, but collide on Windows, where variable names are case
+// insensitive.
+//
+// Each group is sorted lexicographically, and groups are sorted by their
+// first key. If there are no collisions, CaseCollisions returns nil.
+func (m Map) CaseCollisions() [][]string {
+	folded := make(map[string][]string)
+	for _, k := range m.keys() {
+		fk := strings.ToUpper(k)
+		folded[fk] = append(folded[fk], k)
+	}
+	var groups [][]string
+	for _, keys := range folded {
+		if len(keys) > 1 {
+			groups = append(groups, keys)
+		}
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i][0] < groups[j][0]
+	})
+	return groups
+}
@@ -16,7 +16,9 @@ package env_test
 
 import (
 	"fmt"
+	"io/ioutil"
 	"os"
+	"strings"
 	"testing"
 
 	"acln.ro/env"
@@ -60,8 +62,7 @@ func TestString(t *testing.T) {
 
 func stringErrorf(t *testing.T, m env.Map, got, want, diff string) {
 	t.Helper()
-	basicm := map[string]string(m)
-	t.Errorf("%#v.Encode() = %q, want %q: %s", basicm, got, want, diff)
+	t.Errorf("%#v.String() = %q, want %q: %s", m, got, want, diff)
 }
 
 func TestFormat(t *testing.T) {
@@ -120,6 +121,61 @@ func TestFormat(t *testing.T) {
 			format: "%+v",
 			want:   "BAR=\nBAZ=z\nFOO=x",
 		},
+		{
+			m:      env.Map{},
+			format: "%q",
+			want:   "",
+		},
+		{
+			m:      env.Map{"FOO": "x y", "BAR": "\t"},
+			format: "%q",
+			want:   `"BAR"="\t" "FOO"="x y"`,
+		},
+		{
+			m:      env.Map{"FOO": "x\n", "BAR": ""},
+			format: "%+q",
+			want:   "\"BAR\"=\"\"\n\"FOO\"=\"x\\n\"",
+		},
+		{
+			m:      env.Map{},
+			format: "%#v",
+			want:   "env.Map{}",
+		},
+		{
+			m:      env.Map{"FOO": "x", "BAR": "\"y\""},
+			format: "%#v",
+			want:   `env.Map{"BAR":"\"y\"", "FOO":"x"}`,
+		},
+		{
+			m:      env.Map{"USER": "app", "DB_PASSWORD": "hunter2"},
+			format: "%r",
+			want:   "DB_PASSWORD=xxxxx USER=app",
+		},
+		{
+			m:      env.Map{"USER": "app", "GITHUB_TOKEN": "t0ken"},
+			format: "%+r",
+			want:   "GITHUB_TOKEN=xxxxx\nUSER=app",
+		},
+		{
+			m:      env.Map{"PEM": "-----BEGIN-----\nabc\n", "S": "short"},
+			format: "%.5v",
+			want:   "PEM=-----...[20 bytes] S=short",
+		},
+		{
+			m:      env.Map{"PEM": "-----BEGIN-----\nabc\n", "S": "short"},
+			format: "%.5q",
+			want:   `"PEM"="-----...[20 bytes]" "S"="short"`,
+		},
+		{
+			m:      env.Map{"API_TOKEN": "0123456789", "J": "0123456789"},
+			format: "%+.4r",
+			want:   "API_TOKEN=xxxxx\nJ=0123...[10 bytes]",
+		},
+		{
+			m:      env.Map{"J": "0123456789"},
+			format: "%#.4v",
+			want:   `env.Map{"J":"0123456789"}`,
+		},
 		{
 			m:      env.Map{"FOO": "x", "BAR": "y"},
 			format: "%d",
@@ -141,8 +197,41 @@ func TestFormat(t *testing.T) {
 
 func formatErrorf(t *testing.T, m env.Map, format, got, want, diff string) {
 	t.Helper()
-	basicm := map[string]string(m)
-	t.Errorf("formatting %#v with %v: got %q, want %q: %s", basicm, format, got, want, diff)
+	t.Errorf("formatting %#v with %v: got %q, want %q: %s", m, format, got, want, diff)
+}
+
+func TestQuoted(t *testing.T) {
+	m := env.Map{"A": "x ", "B": "\ty\x00", "C": "", "D": `"z"`}
+	want := env.Map{"A": `"x "`, "B": `"\ty\x00"`, "C": `""`, "D": `"\"z\""`}
+	if diff := cmp.Diff(m.Quoted(), want); diff != "" {
+		t.Errorf("Quoted: %s", diff)
+	}
+	if got, want := m.Quoted().String(), `A="x " B="\ty\x00" C="" D="\"z\""`; got != want {
+		t.Errorf("Quoted().String() = %s, want %s", got, want)
+	}
+}
+
+func TestElided(t *testing.T) {
+	m := env.Map{
+		"SHORT": "abc",
+		"EXACT": "abcd",
+		"LONG":  "abcdefgh",
+		"UTF8":  "abcé",
+		"EMPTY": "",
+	}
+	want := env.Map{
+		"SHORT": "abc",
+		"EXACT": "abcd",
+		"LONG":  "abcd...[8 bytes]",
+		"UTF8":  "abc...[5 bytes]",
+		"EMPTY": "",
+	}
+	if diff := cmp.Diff(m.Elided(4), want); diff != "" {
+		t.Errorf("Elided: %s", diff)
+	}
+	if got := (env.Map{"K": "v"}).Elided(0); got["K"] != "...[1 bytes]" {
+		t.Errorf("Elided(0) = %q", got["K"])
+	}
 }
 
 func TestEncode(t *testing.T) {
@@ -181,8 +270,56 @@ func TestEncode(t *testing.T) {
 
 func encodeErrorf(t *testing.T, m env.Map, got, want []string, diff string) {
 	t.Helper()
-	basicm := map[string]string(m)
-	t.Errorf("%#v.Encode() = %v, want %v: %s", basicm, got, want, diff)
+	t.Errorf("%#v.Encode() = %v, want %v: %s", m, got, want, diff)
+}
+
+func TestEncodeExec(t *testing.T) {
+	tests := []struct {
+		m    env.Map
+		want string
+	}{
+		{
+			m:    env.Map{},
+			want: "\x00",
+		},
+		{
+			m:    env.Map{"k": ""},
+			want: "k=\x00\x00",
+		},
+		{
+			m:    env.Map{"FOO": "x", "BAR": "y=z"},
+			want: "BAR=y=z\x00FOO=x\x00\x00",
+		},
+	}
+	for _, tt := range tests {
+		got := tt.m.EncodeExec()
+		if string(got) != tt.want {
+			t.Errorf("%#v.EncodeExec() = %q, want %q", tt.m, got, tt.want)
+		}
+		ptrs := env.ExecPointers(got)
+		if len(ptrs) != len(tt.m)+1 || ptrs[len(ptrs)-1] != nil {
+			t.Fatalf("ExecPointers: got %d pointers, want %d, nil-terminated", len(ptrs), len(tt.m)+1)
+		}
+		for i, kv := range tt.m.Encode() {
+			off := indexOf(got, ptrs[i])
+			if off == -1 {
+				t.Fatalf("ExecPointers: pointer %d does not point into block", i)
+			}
+			if s := string(got[off : off+len(kv)+1]); s != kv+"\x00" {
+				t.Errorf("ExecPointers: pointer %d points at %q, want %q", i, s, kv)
+			}
+		}
+	}
+}
+
+// indexOf returns the offset of the byte p points to in b, or -1.
+func indexOf(b []byte, p *byte) int {
+	for i := range b {
+		if &b[i] == p {
+			return i
+		}
+	}
+	return -1
 }
 
 func TestParse(t *testing.T) {
@@ -221,9 +358,7 @@ func TestParse(t *testing.T) {
 
 func parseErrorf(t *testing.T, kvs []string, got, want env.Map, diff string) {
 	t.Helper()
-	basicgot := map[string]string(got)
-	basicwant := map[string]string(want)
-	t.Errorf("Parse(%v) = %#v, want %#v: %s", kvs, basicgot, basicwant, diff)
+	t.Errorf("Parse(%v) = %#v, want %#v: %s", kvs, got, want, diff)
 }
 
 func TestMerge(t *testing.T) {
@@ -340,6 +475,41 @@ func diffErrorf(t *testing.T, x, y env.Map, got, want env.Diff, diff string) {
 	t.Errorf("Diff(%v, %v) = %#v, want %#v: %s", x, y, got, want, diff)
 }
 
+func TestGoString(t *testing.T) {
+	tests := []struct {
+		v    interface{}
+		want string
+	}{
+		{
+			v:    env.Map{"FOO": "x", "BAR": "y"},
+			want: `env.Map{"BAR":"y", "FOO":"x"}`,
+		},
+		{
+			v:    env.Change{Key: "FOO", MValue: "a", NValue: "b"},
+			want: `env.Change{Key:"FOO", MValue:"a", NValue:"b"}`,
+		},
+		{
+			v:    env.Diff{},
+			want: `env.Diff{}`,
+		},
+		{
+			v: env.Diff{
+				OnlyInM: env.Map{"FOO": "x"},
+				Changes: []env.Change{{Key: "BAR", MValue: "a", NValue: "b"}},
+				OnlyInN: env.Map{"BAZ": "z"},
+			},
+			want: `env.Diff{OnlyInM:env.Map{"FOO":"x"}, ` +
+				`Changes:[]env.Change{env.Change{Key:"BAR", MValue:"a", NValue:"b"}}, ` +
+				`OnlyInN:env.Map{"BAZ":"z"}}`,
+		},
+	}
+	for _, tt := range tests {
+		if got := fmt.Sprintf("%#v", tt.v); got != tt.want {
+			t.Errorf("%%#v of %v: got %s, want %s", tt.v, got, tt.want)
+		}
+	}
+}
+
 func TestChangeString(t *testing.T) {
 	ch := env.Change{
 		Key:    "FOO",
@@ -352,3 +522,144 @@ func TestChangeString(t *testing.T) {
 		t.Errorf("%#v.String() = %q, want %q", ch, got, want)
 	}
 }
+
+func TestMustGet(t *testing.T) {
+	m := env.Map{"FOO": "bar"}
+	if got := m.MustGet("FOO"); got != "bar" {
+		t.Errorf("MustGet(FOO) = %q, want %q", got, "bar")
+	}
+	defer func() {
+		if r := recover(); !strings.Contains(fmt.Sprint(r), "MISSING") {
+			t.Errorf("recovered %v, want message naming MISSING", r)
+		}
+	}()
+	m.MustGet("MISSING")
+}
+
+func TestCaseCollisions(t *testing.T) {
+	tests := []struct {
+		m    env.Map
+		want [][]string
+	}{
+		{
+			m:    env.Map{},
+			want: nil,
+		},
+		{
+			m:    env.Map{"PATH": "/bin", "HOME": "/root"},
+			want: nil,
+		},
+		{
+			m:    env.Map{"PATH": "/bin", "Path": "/usr/bin", "path": ""},
+			want: [][]string{{"PATH", "Path", "path"}},
+		},
+		{
+			m: env.Map{
+				"path": "/bin",
+				"PATH": "/bin",
+				"tmp":  "/tmp",
+				"Tmp":  "/tmp",
+				"HOME": "/root",
+			},
+			want: [][]string{{"PATH", "path"}, {"Tmp", "tmp"}},
+		},
+	}
+	for _, tt := range tests {
+		got := tt.m.CaseCollisions()
+		if diff := cmp.Diff(got, tt.want); diff != "" {
+			t.Errorf("%v.CaseCollisions() = %q, want %q: %s", tt.m, got, tt.want, diff)
+		}
+	}
+}
+
+func benchmarkMap(n int) env.Map {
+	m := make(env.Map, n)
+	for i := 0; i < n; i++ {
+		m[fmt.Sprintf("VARIABLE_%05d", i)] = fmt.Sprintf("/some/value/of/moderate/length/%d", i)
+	}
+	return m
+}
+
+func BenchmarkEncode(b *testing.B) {
+	for _, n := range []int{10, 100, 5000} {
+		m := benchmarkMap(n)
+		b.Run(fmt.Sprint(n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				m.Encode()
+			}
+		})
+	}
+}
+
+func BenchmarkString(b *testing.B) {
+	for _, n := range []int{10, 100, 5000} {
+		m := benchmarkMap(n)
+		b.Run(fmt.Sprint(n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_ = m.String()
+			}
+		})
+	}
+}
+
+func BenchmarkFormat(b *testing.B) {
+	for _, n := range []int{10, 100, 5000} {
+		m := benchmarkMap(n)
+		b.Run(fmt.Sprint(n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				fmt.Fprintf(ioutil.Discard, "%+v", m)
+			}
+		})
+	}
+}
+
+func BenchmarkParse(b *testing.B) {
+	for _, n := range []int{10, 100, 5000} {
+		kvs := benchmarkMap(n).Encode()
+		b.Run(fmt.Sprint(n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				env.Parse(kvs...)
+			}
+		})
+	}
+}
+
+func TestDiffSummary(t *testing.T) {
+	tests := []struct {
+		x, y  env.Map
+		empty bool
+		want  env.DiffSummary
+		str   string
+	}{
+		{
+			x:     env.Map{"FOO": "x"},
+			y:     env.Map{"FOO": "x"},
+			empty: true,
+			want:  env.DiffSummary{},
+			str:   "0 added, 0 removed, 0 changed",
+		},
+		{
+			x:    env.Map{"FOO": "x", "BAR": "a", "OLD": "o"},
+			y:    env.Map{"FOO": "x", "BAR": "b", "NEW": "n", "NEWER": "m"},
+			want: env.DiffSummary{Added: 2, Removed: 1, Changed: 1},
+			str:  "2 added, 1 removed, 1 changed",
+		},
+	}
+	for _, tt := range tests {
+		d := tt.x.Diff(tt.y)
+		if got := d.Empty(); got != tt.empty {
+			t.Errorf("%#v.Empty() = %t, want %t", d, got, tt.empty)
+		}
+		got := d.Summary()
+		if diff := cmp.Diff(got, tt.want); diff != "" {
+			t.Errorf("%#v.Summary() = %+v, want %+v: %s", d, got, tt.want, diff)
+		}
+		if s := got.String(); s != tt.str {
+			t.Errorf("%+v.String() = %q, want %q", got, s, tt.str)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env
+
+import (
+	"context"
+	"os"
+)
+
+// An Environment is a mutable set of environment variables. It lets
+// libraries read and modify the environment without depending on the
+// environment of the current process, such that they can be tested, or
+// pointed at an environment other than their own.
+//
+// OS is the environment of the current process. A *Store, as returned by
+// NewStore or Fake, is an Environment backed by a Map. Both are also
+// Sources.
+//
+// Functions which read the environment of the current process, such as
+// Build, Baseline and LoginShell, have variants taking an Environment,
+// named by the suffix In, as in BuildIn.
+type Environment interface {
+	// Lookup returns the value of the variable named by key, and
+	// whether it is set.
+	Lookup(key string) (string, bool)
+
+	// Set sets key to value.
+	Set(key, value string) error
+
+	// Unset unsets key.
+	Unset(key string) error
+
+	// All returns a copy of the variables in the Environment.
+	All() Map
+}
+
+// OS is the Environment of the current process. Its Set and Unset methods
+// go through Setenv and Unsetenv, so they keep Cached up to date.
+var OS Environment = osEnvironment{}
+
+type osEnvironment struct{}
+
+func (osEnvironment) Lookup(key string) (string, bool) { return os.LookupEnv(key) }
+func (osEnvironment) Set(key, value string) error      { return Setenv(key, value) }
+func (osEnvironment) Unset(key string) error           { return Unsetenv(key) }
+func (osEnvironment) All() Map                         { return Variables() }
+
+func (osEnvironment) Environ(ctx context.Context) (Map, error) {
+	return Variables(), nil
+}
+
+// All returns a copy of the variables in the Store, like Snapshot.
+func (s *Store) All() Map {
+	return s.Snapshot()
+}
+
+// ApplyTo applies the operations in c to e, in order. It stops at the
+// first operation which fails, and returns its error.
+func (c Changeset) ApplyTo(e Environment) error {
+	for _, op := range c {
+		var err error
+		if op.Unset {
+			err = e.Unset(op.Key)
+		} else {
+			err = e.Set(op.Key, op.Value)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Reset changes e such that it holds exactly the variables in m, by
+// unsetting the variables which are not in m, and setting those whose
+// values differ. It is useful for restoring an environment to a snapshot
+// taken earlier, using All.
+func Reset(e Environment, m Map) error {
+	d := e.All().Diff(m)
+	var c Changeset
+	for _, k := range d.Unsets() {
+		c.Unset(k)
+	}
+	patch := d.Patch()
+	for _, k := range patch.keys() {
+		if patch[k] != Tombstone {
+			c.Set(k, patch[k])
+		}
+	}
+	return c.ApplyTo(e)
+}
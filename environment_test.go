@@ -0,0 +1,107 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"acln.ro/env"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// configure is a library function which depends on an Environment rather
+// than on the environment of the current process.
+func configure(e env.Environment) (string, error) {
+	level, ok := e.Lookup("LOG_LEVEL")
+	if !ok {
+		level = "info"
+		if err := e.Set("LOG_LEVEL", level); err != nil {
+			return "", err
+		}
+	}
+	return level, e.Unset("LOG_DEBUG")
+}
+
+func TestEnvironmentStore(t *testing.T) {
+	store := env.NewStore(env.Map{"LOG_DEBUG": "1"})
+	level, err := configure(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if level != "info" {
+		t.Errorf("level = %q, want %q", level, "info")
+	}
+	if diff := cmp.Diff(store.All(), env.Map{"LOG_LEVEL": "info"}); diff != "" {
+		t.Errorf("All: %s", diff)
+	}
+}
+
+func TestEnvironmentOS(t *testing.T) {
+	defer restoreEnv(env.Variables())
+	os.Setenv("LOG_LEVEL", "warn")
+	os.Setenv("LOG_DEBUG", "1")
+	level, err := configure(env.OS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if level != "warn" {
+		t.Errorf("level = %q, want %q", level, "warn")
+	}
+	if _, ok := os.LookupEnv("LOG_DEBUG"); ok {
+		t.Error("LOG_DEBUG still set")
+	}
+	if _, ok := env.Cached()["LOG_DEBUG"]; ok {
+		t.Error("Cached not invalidated by OS.Unset")
+	}
+	if v, _ := env.LookupContext(env.WithProcess(context.Background(), env.OS.(env.Source)), "LOG_LEVEL"); v != "warn" {
+		t.Errorf("OS as a Source: LOG_LEVEL = %q, want %q", v, "warn")
+	}
+}
+
+func TestReset(t *testing.T) {
+	store := env.NewStore(env.Map{"A": "1", "B": "2", "C": "3"})
+	snapshot := store.All()
+	store.Set("A", "changed")
+	store.Unset("B")
+	store.Set("D", "new")
+	if err := env.Reset(store, snapshot); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(store.All(), snapshot); diff != "" {
+		t.Errorf("after Reset: %s", diff)
+	}
+}
+
+func TestChangesetApplyToError(t *testing.T) {
+	store := env.NewStore(nil)
+	errReadOnly := errors.New("read-only")
+	store.BeforeChange(func(op env.Op) error {
+		if op.Key == "RO" {
+			return errReadOnly
+		}
+		return nil
+	})
+	c := env.Changeset{{Key: "A", Value: "1"}, {Key: "RO", Value: "x"}, {Key: "B", Value: "2"}}
+	if err := c.ApplyTo(store); err != errReadOnly {
+		t.Fatalf("got %v, want %v", err, errReadOnly)
+	}
+	if diff := cmp.Diff(store.All(), env.Map{"A": "1"}); diff != "" {
+		t.Errorf("after failed ApplyTo: %s", diff)
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+// Package envtest provides helpers for testing code which works with
+// environments.
+package envtest
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"acln.ro/env"
+)
+
+// AssertSet reports an error through t unless the variable named by key is
+// set in m to want. It reports whether the assertion held.
+func AssertSet(t testing.TB, m env.Map, key, want string) bool {
+	t.Helper()
+	got, ok := m[key]
+	switch {
+	case !ok:
+		t.Errorf("%s is not set, want %q", key, want)
+		return false
+	case got != want:
+		t.Errorf("%s = %q, want %q", key, got, want)
+		return false
+	}
+	return true
+}
+
+// AssertUnset reports an error through t if the variable named by key is
+// set in m. It reports whether the assertion held.
+func AssertUnset(t testing.TB, m env.Map, key string) bool {
+	t.Helper()
+	if got, ok := m[key]; ok {
+		t.Errorf("%s = %q, want unset", key, got)
+		return false
+	}
+	return true
+}
+
+// AssertEqual reports an error through t, showing the differences as
+// formatted by Diff, unless got and want hold the same variables. It
+// reports whether the assertion held.
+func AssertEqual(t testing.TB, got, want env.Map) bool {
+	t.Helper()
+	if d := Diff(got, want); d != "" {
+		t.Errorf("environment mismatch:\n%s", d)
+		return false
+	}
+	return true
+}
+
+// AssertSubset reports an error through t unless every variable in want
+// is set in got, to the same value. Other variables in got are ignored.
+// It reports whether the assertion held.
+func AssertSubset(t testing.TB, got, want env.Map) bool {
+	t.Helper()
+	sub := make(env.Map, len(want))
+	for k := range want {
+		if v, ok := got[k]; ok {
+			sub[k] = v
+		}
+	}
+	if d := Diff(sub, want); d != "" {
+		t.Errorf("environment does not include the wanted variables:\n%s", d)
+		return false
+	}
+	return true
+}
+
+// Diff returns a description of the differences between got and want,
+// one variable per line, sorted by key, or the empty string if there are
+// none. Values are quoted using Go syntax, so that invisible characters
+// show up:
+//
+//	EXTRA: got "1", want unset
+//	MISSING: got unset, want "x"
+//	PATH: got "/bin", want "/usr/bin:/bin"
+func Diff(got, want env.Map) string {
+	d := got.Diff(want)
+	if d.Empty() {
+		return ""
+	}
+	lines := make(map[string]string, len(d.OnlyInM)+len(d.Changes)+len(d.OnlyInN))
+	for k, v := range d.OnlyInM {
+		lines[k] = fmt.Sprintf("%s: got %q, want unset", k, v)
+	}
+	for k, v := range d.OnlyInN {
+		lines[k] = fmt.Sprintf("%s: got unset, want %q", k, v)
+	}
+	for _, c := range d.Changes {
+		lines[c.Key] = fmt.Sprintf("%s: got %q, want %q", c.Key, c.MValue, c.NValue)
+	}
+	keys := make([]string, 0, len(lines))
+	for k := range lines {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var sb strings.Builder
+	for i, k := range keys {
+		if i > 0 {
+			sb.WriteByte('\n')
+		}
+		sb.WriteString(lines[k])
+	}
+	return sb.String()
+}
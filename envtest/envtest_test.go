@@ -0,0 +1,130 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package envtest_test
+
+import (
+	"fmt"
+	"testing"
+
+	"acln.ro/env"
+	"acln.ro/env/envtest"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// recorder is a testing.TB which records errors instead of reporting them.
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestAssertions(t *testing.T) {
+	m := env.Map{"PATH": "/bin", "EMPTY": "", "TRAILING": "x "}
+	tests := []struct {
+		name   string
+		assert func(t testing.TB) bool
+		want   []string
+	}{
+		{
+			name:   "set",
+			assert: func(t testing.TB) bool { return envtest.AssertSet(t, m, "PATH", "/bin") },
+		},
+		{
+			name:   "set empty",
+			assert: func(t testing.TB) bool { return envtest.AssertSet(t, m, "EMPTY", "") },
+		},
+		{
+			name:   "set wrong value",
+			assert: func(t testing.TB) bool { return envtest.AssertSet(t, m, "TRAILING", "x") },
+			want:   []string{`TRAILING = "x ", want "x"`},
+		},
+		{
+			name:   "set but unset",
+			assert: func(t testing.TB) bool { return envtest.AssertSet(t, m, "HOME", "/root") },
+			want:   []string{`HOME is not set, want "/root"`},
+		},
+		{
+			name:   "unset",
+			assert: func(t testing.TB) bool { return envtest.AssertUnset(t, m, "HOME") },
+		},
+		{
+			name:   "unset but set",
+			assert: func(t testing.TB) bool { return envtest.AssertUnset(t, m, "EMPTY") },
+			want:   []string{`EMPTY = "", want unset`},
+		},
+		{
+			name: "subset",
+			assert: func(t testing.TB) bool {
+				return envtest.AssertSubset(t, m, env.Map{"PATH": "/bin", "EMPTY": ""})
+			},
+		},
+		{
+			name: "not a subset",
+			assert: func(t testing.TB) bool {
+				return envtest.AssertSubset(t, m, env.Map{"PATH": "/usr/bin", "HOME": "/root"})
+			},
+			want: []string{"environment does not include the wanted variables:\n" +
+				`HOME: got unset, want "/root"` + "\n" +
+				`PATH: got "/bin", want "/usr/bin"`},
+		},
+		{
+			name: "equal",
+			assert: func(t testing.TB) bool {
+				return envtest.AssertEqual(t, m, env.Map{"PATH": "/bin", "EMPTY": "", "TRAILING": "x "})
+			},
+		},
+		{
+			name: "not equal",
+			assert: func(t testing.TB) bool {
+				return envtest.AssertEqual(t, m, env.Map{"PATH": "/bin"})
+			},
+			want: []string{"environment mismatch:\n" +
+				`EMPTY: got "", want unset` + "\n" +
+				`TRAILING: got "x ", want unset`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &recorder{TB: t}
+			ok := tt.assert(r)
+			if ok != (len(tt.want) == 0) {
+				t.Errorf("assertion returned %t with errors %q", ok, r.errors)
+			}
+			if diff := cmp.Diff(r.errors, tt.want); diff != "" {
+				t.Errorf("errors: %s", diff)
+			}
+		})
+	}
+}
+
+func TestDiff(t *testing.T) {
+	if d := envtest.Diff(env.Map{"A": "1"}, env.Map{"A": "1"}); d != "" {
+		t.Errorf("Diff of equal maps = %q, want empty", d)
+	}
+	got := env.Map{"EXTRA": "1", "PATH": "/bin", "TAB": "\t"}
+	want := env.Map{"MISSING": "x", "PATH": "/usr/bin:/bin", "TAB": "\t"}
+	wantDiff := `EXTRA: got "1", want unset
+MISSING: got unset, want "x"
+PATH: got "/bin", want "/usr/bin:/bin"`
+	if d := envtest.Diff(got, want); d != wantDiff {
+		t.Errorf("Diff:\n%s\nwant:\n%s", d, wantDiff)
+	}
+}
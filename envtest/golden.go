@@ -0,0 +1,96 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package envtest
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"acln.ro/env"
+)
+
+var update = flag.Bool("update", false, "update golden environment files")
+
+// A GoldenOption configures Golden.
+type GoldenOption func(*goldenConfig)
+
+type goldenConfig struct {
+	ignore []string
+}
+
+// Ignore causes variables whose names match at least one of the
+// patterns, in the syntax of env.Map.AcceptEnv, to be left out of the
+// comparison and of the golden file. It is intended for volatile
+// variables, such as those holding timestamps or temporary paths.
+func Ignore(patterns ...string) GoldenOption {
+	return func(cfg *goldenConfig) {
+		cfg.ignore = append(cfg.ignore, patterns...)
+	}
+}
+
+// Golden compares m against the golden file testdata/<name>.env, in
+// dotenv format, and reports the differences through t, as formatted by
+// Diff. If the test binary is run with the -update flag, as in
+// "go test -update", Golden writes m to the golden file instead.
+//
+// Golden registers the -update flag, so test packages using it must not
+// register a flag of the same name. It reports whether m matched.
+func Golden(t testing.TB, name string, m env.Map, opts ...GoldenOption) bool {
+	t.Helper()
+	return goldenFile(t, filepath.Join("testdata", name+".env"), m, *update, opts...)
+}
+
+func goldenFile(t testing.TB, path string, m env.Map, update bool, opts ...GoldenOption) bool {
+	t.Helper()
+	var cfg goldenConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if len(cfg.ignore) > 0 {
+		m = env.InheritExcept(cfg.ignore...).Filter(m)
+	}
+	if update {
+		if err := writeGolden(path, m); err != nil {
+			t.Errorf("updating golden file: %v", err)
+			return false
+		}
+		return true
+	}
+	want, err := env.ReadDotenvFile(path)
+	if err != nil {
+		t.Errorf("reading golden file: %v (run with -update to create it)", err)
+		return false
+	}
+	if len(cfg.ignore) > 0 {
+		want = env.InheritExcept(cfg.ignore...).Filter(want)
+	}
+	if d := Diff(m, want); d != "" {
+		t.Errorf("environment does not match %s (run with -update to update it):\n%s", path, d)
+		return false
+	}
+	return true
+}
+
+// writeGolden writes m to the golden file at path, replacing it
+// atomically, such that an interrupted update does not leave a truncated
+// golden file behind.
+func writeGolden(path string, m env.Map) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return m.WriteDotenvFile(path, env.Heredocs())
+}
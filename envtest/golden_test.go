@@ -0,0 +1,79 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package envtest_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"acln.ro/env"
+	"acln.ro/env/envtest"
+)
+
+func TestGolden(t *testing.T) {
+	dir, err := ioutil.TempDir("", "envtest-golden")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "testdata", "build.env")
+
+	built := env.Map{
+		"CC":         "clang",
+		"CERT":       "-----BEGIN-----\nabc\n-----END-----\n",
+		"BUILD_TIME": "2026-10-17T10:00:00Z",
+		"TMPDIR":     "/tmp/build123",
+	}
+	ignore := envtest.Ignore("BUILD_*", "TMPDIR")
+
+	r := &recorder{TB: t}
+	if envtest.GoldenFile(r, path, built, false, ignore) || len(r.errors) != 1 {
+		t.Fatalf("missing golden file: got errors %q, want 1", r.errors)
+	}
+	if !strings.Contains(r.errors[0], "-update") {
+		t.Errorf("error %q does not mention -update", r.errors[0])
+	}
+
+	r = &recorder{TB: t}
+	if !envtest.GoldenFile(r, path, built, true, ignore) {
+		t.Fatalf("update: %q", r.errors)
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "BUILD_TIME") || strings.Contains(string(data), "TMPDIR") {
+		t.Errorf("golden file contains ignored variables:\n%s", data)
+	}
+
+	built["BUILD_TIME"] = "2026-10-18T09:00:00Z"
+	built["TMPDIR"] = "/tmp/build456"
+	r = &recorder{TB: t}
+	if !envtest.GoldenFile(r, path, built, false, ignore) {
+		t.Errorf("volatile changes reported: %q", r.errors)
+	}
+
+	built["CC"] = "gcc"
+	r = &recorder{TB: t}
+	if envtest.GoldenFile(r, path, built, false, ignore) || len(r.errors) != 1 {
+		t.Fatalf("changed environment: got errors %q, want 1", r.errors)
+	}
+	if !strings.Contains(r.errors[0], `CC: got "gcc", want "clang"`) {
+		t.Errorf("error %q does not describe the change", r.errors[0])
+	}
+}
@@ -0,0 +1,142 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrNotFound is reported, possibly wrapped, when a variable which must be
+// set is not. Use errors.Is to test for it.
+var ErrNotFound = errors.New("env: variable not found")
+
+// A ParseError records a failure to parse textual input.
+type ParseError struct {
+	Path  string // file name, if the input was read from a file
+	Line  int    // line number, starting at 1
+	Input string // the offending input
+	Err   error  // the underlying error, if any
+}
+
+func (e *ParseError) Error() string {
+	pos := fmt.Sprintf("line %d", e.Line)
+	if e.Path != "" {
+		pos = fmt.Sprintf("%s:%d", e.Path, e.Line)
+	}
+	if e.Err == nil {
+		return fmt.Sprintf("env: %s: malformed input %q", pos, e.Input)
+	}
+	return fmt.Sprintf("env: %s: %v", pos, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *ParseError) Unwrap() error { return e.Err }
+
+// A ValidationError records a variable whose value, or absence, does not
+// satisfy a requirement.
+type ValidationError struct {
+	Key    string // name of the variable
+	Reason string // why the variable is not valid
+	Err    error  // the underlying error, if any
+
+	// Keys lists all the variables involved, starting with Key, if the
+	// error concerns the relationship between several variables.
+	Keys []string
+}
+
+func (e *ValidationError) Error() string {
+	if len(e.Keys) > 1 {
+		return "env: " + strings.Join(e.Keys, ", ") + ": " + e.Reason
+	}
+	return "env: " + e.Key + ": " + e.Reason
+}
+
+// Unwrap returns the underlying error.
+func (e *ValidationError) Unwrap() error { return e.Err }
+
+// notSet returns a *ValidationError wrapping ErrNotFound for key.
+func notSet(key string) *ValidationError {
+	return &ValidationError{
+		Key:    key,
+		Reason: "required variable is not set",
+		Err:    ErrNotFound,
+	}
+}
+
+// invalid returns a *ValidationError for key, wrapping err.
+func invalid(key string, err error) *ValidationError {
+	return &ValidationError{Key: key, Reason: err.Error(), Err: err}
+}
+
+// An ErrorList is a list of errors, reported together so that all the
+// problems with an environment can be fixed at once. Validating functions
+// which return an ErrorList always return it as the error interface, and
+// never return an empty ErrorList.
+//
+// ErrorList supports errors.Is and errors.As, which match any error in
+// the list.
+type ErrorList []error
+
+func (l ErrorList) Error() string {
+	if len(l) == 1 {
+		return l[0].Error()
+	}
+	msgs := make([]string, len(l))
+	for i, err := range l {
+		msgs[i] = strings.TrimPrefix(err.Error(), "env: ")
+	}
+	return "env: " + strings.Join(msgs, "; ")
+}
+
+// Unwrap returns the errors in the list.
+func (l ErrorList) Unwrap() []error { return l }
+
+// Report renders the list as a user-facing report, listing one problem
+// per line.
+func (l ErrorList) Report() string {
+	var sb strings.Builder
+	if len(l) == 1 {
+		sb.WriteString("env: 1 problem:\n")
+	} else {
+		fmt.Fprintf(&sb, "env: %d problems:\n", len(l))
+	}
+	for _, err := range l {
+		sb.WriteString("\t")
+		sb.WriteString(strings.TrimPrefix(err.Error(), "env: "))
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// Report renders err as a user-facing report. If err is or wraps an
+// ErrorList, every error in the list is reported on its own line.
+// Otherwise, the report consists of the error message alone.
+func Report(err error) string {
+	var l ErrorList
+	if errors.As(err, &l) {
+		return l.Report()
+	}
+	return err.Error() + "\n"
+}
+
+// err returns l as an error, or nil if l is empty.
+func (l ErrorList) err() error {
+	if len(l) == 0 {
+		return nil
+	}
+	return l
+}
@@ -0,0 +1,140 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"acln.ro/env"
+)
+
+func TestErrNotFound(t *testing.T) {
+	var cfg struct {
+		DB string `env:"DATABASE_URL,required"`
+	}
+	schema := &env.Schema{Vars: []env.Var{{Key: "DATABASE_URL", Required: true}}}
+	_, urlErr := env.Map{}.URL("DATABASE_URL")
+	_, loadErr := env.Chain{env.Map{}}.Load(context.Background(), env.Require("DATABASE_URL"))
+	errs := map[string]error{
+		"Decode":   env.Decode(env.Map{}, &cfg),
+		"Validate": schema.Validate(env.Map{}),
+		"URL":      urlErr,
+		"Load":     loadErr,
+	}
+	for name, err := range errs {
+		if !errors.Is(err, env.ErrNotFound) {
+			t.Errorf("%s: got %v, want error wrapping ErrNotFound", name, err)
+		}
+		var verr *env.ValidationError
+		if !errors.As(err, &verr) || verr.Key != "DATABASE_URL" {
+			t.Errorf("%s: got %v, want *ValidationError for DATABASE_URL", name, err)
+		}
+	}
+}
+
+func TestValidationError(t *testing.T) {
+	var cfg struct {
+		Port int `env:"PORT"`
+	}
+	err := env.Decode(env.Map{"PORT": "eighty"}, &cfg)
+	var verr *env.ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("got %v, want *ValidationError", err)
+	}
+	if verr.Key != "PORT" {
+		t.Errorf("Key = %q, want %q", verr.Key, "PORT")
+	}
+	if errors.Is(err, env.ErrNotFound) {
+		t.Error("malformed value reported as ErrNotFound")
+	}
+}
+
+func TestParseError(t *testing.T) {
+	var c env.Changeset
+	err := c.UnmarshalText([]byte("set \"A\" \"1\"\n\nfrobnicate \"B\"\n"))
+	var perr *env.ParseError
+	if !errors.As(err, &perr) {
+		t.Fatalf("got %v, want *ParseError", err)
+	}
+	if perr.Line != 3 || perr.Input != `frobnicate "B"` {
+		t.Errorf("got line %d, input %q, want line 3, input %q", perr.Line, perr.Input, `frobnicate "B"`)
+	}
+}
+
+func TestErrorList(t *testing.T) {
+	var cfg struct {
+		DB      string `env:"DATABASE_URL,required"`
+		Port    int    `env:"PORT"`
+		Workers int    `env:"WORKERS"`
+	}
+	err := env.Decode(env.Map{"PORT": "eighty", "WORKERS": "4"}, &cfg)
+	var errs env.ErrorList
+	if !errors.As(err, &errs) {
+		t.Fatalf("got %v, want ErrorList", err)
+	}
+	if len(errs) != 2 {
+		t.Fatalf("got %d errors, want 2: %v", len(errs), errs)
+	}
+	if !errors.Is(err, env.ErrNotFound) {
+		t.Error("ErrorList does not match ErrNotFound")
+	}
+	if cfg.Workers != 4 {
+		t.Errorf("valid field not decoded after error: Workers = %d", cfg.Workers)
+	}
+	report := env.Report(err)
+	for _, want := range []string{"2 problems", "\tDATABASE_URL: required", "\tPORT: "} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report %q does not contain %q", report, want)
+		}
+	}
+}
+
+func TestErrorListAPIs(t *testing.T) {
+	schema := &env.Schema{Vars: []env.Var{
+		{Key: "A", Required: true},
+		{Key: "B", Required: true},
+		{Key: "C", Type: env.Int},
+	}}
+	_, loadErr := env.Chain{env.Map{}}.Load(context.Background(), env.Require("A", "B"))
+	var c env.Changeset
+	errs := map[string]struct {
+		err  error
+		want int
+	}{
+		"Validate":      {schema.Validate(env.Map{"C": "x"}), 3},
+		"Load":          {loadErr, 2},
+		"ValidateSSH":   {env.ValidateSSH(env.Map{"A=B": "", "C": "\x00"}), 2},
+		"UnmarshalText": {c.UnmarshalText([]byte("frob\nset \"A\" \"1\"\nunset")), 2},
+	}
+	for name, tt := range errs {
+		var l env.ErrorList
+		if !errors.As(tt.err, &l) || len(l) != tt.want {
+			t.Errorf("%s: got %v, want ErrorList of length %d", name, tt.err, tt.want)
+		}
+	}
+	if c != nil {
+		t.Error("UnmarshalText modified Changeset despite errors")
+	}
+}
+
+func TestReportSingle(t *testing.T) {
+	err := errors.New("env: something broke")
+	if got, want := env.Report(err), "env: something broke\n"; got != want {
+		t.Errorf("Report = %q, want %q", got, want)
+	}
+}
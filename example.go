@@ -0,0 +1,103 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env
+
+import (
+	"strings"
+)
+
+// An ExampleReport records the differences between the keys of an
+// environment and those of its example, such as between .env and
+// .env.example.
+type ExampleReport struct {
+	// Missing lists the keys which are set in the example, but not in
+	// the environment, sorted.
+	Missing []string
+
+	// Undocumented lists the keys which are set in the environment,
+	// but not in the example, sorted.
+	Undocumented []string
+}
+
+// CheckExample compares the keys of actual against those of example.
+// Values are not compared, since those in examples are placeholders.
+func CheckExample(actual, example Map) ExampleReport {
+	var r ExampleReport
+	for _, k := range example.keys() {
+		if _, ok := actual[k]; !ok {
+			r.Missing = append(r.Missing, k)
+		}
+	}
+	for _, k := range actual.keys() {
+		if _, ok := example[k]; !ok {
+			r.Undocumented = append(r.Undocumented, k)
+		}
+	}
+	return r
+}
+
+// CheckExampleFiles is like CheckExample, but reads the environment and
+// the example from the named dotenv files.
+func CheckExampleFiles(actualPath, examplePath string) (ExampleReport, error) {
+	actual, err := ReadDotenvFile(actualPath)
+	if err != nil {
+		return ExampleReport{}, err
+	}
+	example, err := ReadDotenvFile(examplePath)
+	if err != nil {
+		return ExampleReport{}, err
+	}
+	return CheckExample(actual, example), nil
+}
+
+// OK reports whether the environment and the example have the same keys.
+func (r ExampleReport) OK() bool {
+	return len(r.Missing) == 0 && len(r.Undocumented) == 0
+}
+
+// String renders the report with one problem per line, suitable for the
+// output of a pre-commit hook or CI check. It returns the empty string if
+// the report is OK.
+func (r ExampleReport) String() string {
+	var sb strings.Builder
+	for _, k := range r.Missing {
+		sb.WriteString(k + ": in the example, but not set\n")
+	}
+	for _, k := range r.Undocumented {
+		sb.WriteString(k + ": set, but not in the example\n")
+	}
+	return sb.String()
+}
+
+// Err returns the problems in the report as an ErrorList of
+// *ValidationError, or nil if the report is OK. Errors for missing keys
+// wrap ErrNotFound.
+func (r ExampleReport) Err() error {
+	var errs ErrorList
+	for _, k := range r.Missing {
+		errs = append(errs, &ValidationError{
+			Key:    k,
+			Reason: "variable is in the example, but not set",
+			Err:    ErrNotFound,
+		})
+	}
+	for _, k := range r.Undocumented {
+		errs = append(errs, &ValidationError{
+			Key:    k,
+			Reason: "variable is set, but not in the example",
+		})
+	}
+	return errs.err()
+}
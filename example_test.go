@@ -0,0 +1,77 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"acln.ro/env"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCheckExample(t *testing.T) {
+	actual := env.Map{"DATABASE_URL": "postgres://db/app", "PORT": "8080", "DEBUG_TOKEN": "x"}
+	example := env.Map{"DATABASE_URL": "postgres://localhost/app", "PORT": "", "SENTRY_DSN": "", "API_KEY": ""}
+
+	r := env.CheckExample(actual, example)
+	want := env.ExampleReport{
+		Missing:      []string{"API_KEY", "SENTRY_DSN"},
+		Undocumented: []string{"DEBUG_TOKEN"},
+	}
+	if diff := cmp.Diff(want, r); diff != "" {
+		t.Errorf("(-want +got)\n%s", diff)
+	}
+	if r.OK() {
+		t.Error("OK() = true")
+	}
+	wantString := "API_KEY: in the example, but not set\n" +
+		"SENTRY_DSN: in the example, but not set\n" +
+		"DEBUG_TOKEN: set, but not in the example\n"
+	if s := r.String(); s != wantString {
+		t.Errorf("String() = %q, want %q", s, wantString)
+	}
+	err := r.Err()
+	var errs env.ErrorList
+	if !errors.As(err, &errs) || len(errs) != 3 || !errors.Is(errs[0], env.ErrNotFound) || errors.Is(errs[2], env.ErrNotFound) {
+		t.Errorf("Err() = %v", err)
+	}
+
+	ok := env.CheckExample(actual, env.Map{"DATABASE_URL": "", "PORT": "", "DEBUG_TOKEN": ""})
+	if !ok.OK() || ok.String() != "" || ok.Err() != nil {
+		t.Errorf("got %+v, want OK", ok)
+	}
+}
+
+func TestCheckExampleFiles(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		".env":         "PORT=8080\nDATABASE_URL=postgres://db/app\n",
+		".env.example": "# The port to listen on.\nPORT=\nDATABASE_URL=\nLOG_LEVEL=info\n",
+	})
+	defer os.RemoveAll(dir)
+	r, err := env.CheckExampleFiles(filepath.Join(dir, ".env"), filepath.Join(dir, ".env.example"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(env.ExampleReport{Missing: []string{"LOG_LEVEL"}}, r); diff != "" {
+		t.Errorf("(-want +got)\n%s", diff)
+	}
+	if _, err := env.CheckExampleFiles(filepath.Join(dir, "missing"), filepath.Join(dir, ".env.example")); err == nil {
+		t.Error("CheckExampleFiles with missing file succeeded")
+	}
+}
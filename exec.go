@@ -0,0 +1,220 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// Tombstone is a value which marks a variable for removal. A Map in which
+// a variable is set to Tombstone, when applied to a command by Apply,
+// Command or CommandContext, removes the variable from the environment
+// the command would otherwise inherit. Since it contains a NUL byte,
+// Tombstone cannot be the value of a real environment variable.
+const Tombstone = "\x00"
+
+// Unsets returns the sorted keys of the variables which are set in M, but
+// not in N: the variables which must be removed to turn M into N.
+func (d Diff) Unsets() []string {
+	return d.OnlyInM.keys()
+}
+
+// Patch returns a Map which, when applied to environment M by Apply,
+// Command or CommandContext, turns it into environment N. Variables which
+// must be removed are set to Tombstone.
+func (d Diff) Patch() Map {
+	p := make(Map, len(d.OnlyInM)+len(d.Changes)+len(d.OnlyInN))
+	for k := range d.OnlyInM {
+		p[k] = Tombstone
+	}
+	for _, c := range d.Changes {
+		p[c.Key] = c.NValue
+	}
+	for k, v := range d.OnlyInN {
+		p[k] = v
+	}
+	return p
+}
+
+// Apply sets the environment of cmd to the environment it would otherwise
+// run with, overlaid with m. If cmd.Env is nil, the command would inherit
+// the environment of the current process, so Apply starts from that.
+// Variables set to Tombstone in m are removed. On Windows, where variable
+// names are case-insensitive, a variable in m replaces or removes any
+// variable whose name differs from it only in case.
+func Apply(cmd *exec.Cmd, m Map) {
+	base := cmd.Env
+	if base == nil {
+		base = os.Environ()
+	}
+	cmd.Env = overlay(Parse(base...), m, runtime.GOOS).Encode()
+}
+
+// Command is like exec.Command, but runs the command with the environment
+// of the current process overlaid with m, as described by Apply.
+func Command(m Map, name string, args ...string) *exec.Cmd {
+	cmd := exec.Command(name, args...)
+	Apply(cmd, m)
+	return cmd
+}
+
+// CommandContext is like exec.CommandContext, but runs the command with
+// the environment of the current process overlaid with m, as described
+// by Apply.
+func CommandContext(ctx context.Context, m Map, name string, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, name, args...)
+	Apply(cmd, m)
+	return cmd
+}
+
+// CommandIsolated is like exec.Command, but runs the command with exactly
+// the variables in m, and nothing inherited from the current process, as
+// "env -i" does. Variables set to Tombstone in m are omitted.
+//
+// On Windows, where programs cannot run without it, SystemRoot is copied
+// from the current process if m does not set it. No other variables are
+// added, on any platform.
+//
+// As with exec.Command, if name contains no path separators, it is
+// resolved using the PATH of the current process, not the one in m.
+func CommandIsolated(m Map, name string, args ...string) *exec.Cmd {
+	return CommandIsolatedIn(OS, m, name, args...)
+}
+
+// CommandIsolatedIn is like CommandIsolated, but copies SystemRoot from e,
+// rather than from the current process.
+func CommandIsolatedIn(e Environment, m Map, name string, args ...string) *exec.Cmd {
+	cmd := exec.Command(name, args...)
+	cmd.Env = isolated(m, runtime.GOOS, e.All()).Encode()
+	return cmd
+}
+
+// CommandWith is like exec.Command, but runs the command with the
+// environment returned by Build for policy and m. As with
+// CommandIsolated, SystemRoot is copied from the current process on
+// Windows, if the result does not set it.
+func CommandWith(policy Inherit, m Map, name string, args ...string) *exec.Cmd {
+	return CommandWithIn(OS, policy, m, name, args...)
+}
+
+// CommandWithIn is like CommandWith, but runs the command with the
+// environment returned by BuildIn for e, policy and m, and copies
+// SystemRoot from e.
+func CommandWithIn(e Environment, policy Inherit, m Map, name string, args ...string) *exec.Cmd {
+	cur := e.All()
+	cmd := exec.Command(name, args...)
+	cmd.Env = isolated(build(cur, policy, []Map{m}), runtime.GOOS, cur).Encode()
+	return cmd
+}
+
+// Exec replaces the current process with the program at path, running
+// with the specified arguments, which conventionally start with the name
+// of the program, and with exactly the variables in m. It is typically
+// used by programs which re-execute themselves with a modified
+// environment.
+//
+// Before replacing the process, Exec checks that m can be passed to the
+// program: names must be non-empty and must not contain '=' or NUL
+// bytes, and values must not contain NUL bytes. All problems are
+// reported, as an ErrorList.
+//
+// On success, Exec does not return. On Windows, where processes cannot be
+// replaced, Exec starts the program with the standard streams of the
+// current process, waits for it to exit, and exits with its exit code.
+func Exec(path string, args []string, m Map) error {
+	if err := validateExec(m); err != nil {
+		return err
+	}
+	if err := execve(path, args, m.Encode()); err != nil {
+		return fmt.Errorf("env: exec %s: %v", path, err)
+	}
+	return nil
+}
+
+// validateExec checks that m can be encoded as the environment of a
+// process.
+func validateExec(m Map) error {
+	var errs ErrorList
+	for _, k := range m.keys() {
+		if err := checkEntry(k, m[k]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs.err()
+}
+
+var errEmptyName = errors.New("env: empty variable name")
+
+// checkEntry checks that the variable k=v can be encoded in the
+// environment of a process.
+func checkEntry(k, v string) error {
+	switch {
+	case k == "":
+		return errEmptyName
+	case strings.ContainsAny(k, "=\x00"):
+		return fmt.Errorf("env: invalid variable name %q", k)
+	case strings.ContainsRune(v, 0):
+		return &ValidationError{Key: k, Reason: "value contains NUL byte"}
+	}
+	return nil
+}
+
+// isolated returns the environment of a command isolated from cur,
+// running on goos with the variables in m. On Windows, SystemRoot is
+// copied from cur if m does not set it.
+func isolated(m Map, goos string, cur Map) Map {
+	iso := overlay(make(Map, len(m)+1), m, goos)
+	if goos == "windows" && iso.lookupFold("SystemRoot") == "" {
+		if v := cur.lookupFold("SystemRoot"); v != "" {
+			iso["SystemRoot"] = v
+		}
+	}
+	return iso
+}
+
+// overlay returns base overlaid with m, in place. Variables set to
+// Tombstone in m are removed from base. On windows, keys are compared
+// case-insensitively, such that m["Path"] replaces base["PATH"].
+func overlay(base, m Map, goos string) Map {
+	var folded map[string]string // upper case key -> key in base
+	if goos == "windows" {
+		folded = make(map[string]string, len(base))
+		for k := range base {
+			folded[strings.ToUpper(k)] = k
+		}
+	}
+	for _, k := range m.keys() {
+		v := m[k]
+		if folded != nil {
+			uk := strings.ToUpper(k)
+			if old, ok := folded[uk]; ok {
+				delete(base, old)
+			}
+			folded[uk] = k
+		}
+		if v == Tombstone {
+			delete(base, k)
+		} else {
+			base[k] = v
+		}
+	}
+	return base
+}
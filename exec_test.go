@@ -0,0 +1,153 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"acln.ro/env"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDiffUnsetsPatch(t *testing.T) {
+	m := env.Map{"GONE": "x", "ALSO_GONE": "y", "SAME": "s", "CHANGED": "old"}
+	n := env.Map{"SAME": "s", "CHANGED": "new", "ADDED": "a"}
+	d := m.Diff(n)
+	if diff := cmp.Diff(d.Unsets(), []string{"ALSO_GONE", "GONE"}); diff != "" {
+		t.Errorf("Unsets: %s", diff)
+	}
+	cmd := exec.Command("true")
+	cmd.Env = m.Encode()
+	env.Apply(cmd, d.Patch())
+	if diff := cmp.Diff(env.Parse(cmd.Env...), n); diff != "" {
+		t.Errorf("M with Patch applied: %s", diff)
+	}
+}
+
+func TestCommandTombstone(t *testing.T) {
+	if _, err := exec.LookPath("env"); err != nil {
+		t.Skip("env not available")
+	}
+	defer restoreEnv(env.Variables())
+	os.Setenv("ENV_TEST_INHERITED", "secret")
+	os.Setenv("ENV_TEST_KEPT", "kept")
+	m := env.Map{"ENV_TEST_INHERITED": env.Tombstone, "ENV_TEST_ADDED": "added"}
+	out, err := env.Command(m, "env").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	child := env.Parse(strings.Split(string(out), "\n")...)
+	if _, ok := child["ENV_TEST_INHERITED"]; ok {
+		t.Error("tombstoned variable inherited by child")
+	}
+	if child["ENV_TEST_KEPT"] != "kept" || child["ENV_TEST_ADDED"] != "added" {
+		t.Errorf("child environment missing variables: %v", child)
+	}
+}
+
+func TestCommandIsolated(t *testing.T) {
+	if _, err := exec.LookPath("env"); err != nil {
+		t.Skip("env not available")
+	}
+	m := env.Map{"ONLY": "this", "GONE": env.Tombstone}
+	out, err := env.CommandIsolated(m, "env").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(out), "ONLY=this\n"; got != want {
+		t.Errorf("child environment = %q, want %q", got, want)
+	}
+	out, err = env.CommandIsolated(env.Map{}, "env").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out) != 0 {
+		t.Errorf("empty Map: child environment = %q, want empty", out)
+	}
+}
+
+func TestIsolatedWindows(t *testing.T) {
+	cur := env.Map{"SYSTEMROOT": `C:\Windows`}
+	got := env.Isolated(env.Map{"A": "1"}, "windows", cur)
+	want := env.Map{"A": "1", "SystemRoot": `C:\Windows`}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("windows: %s", diff)
+	}
+	got = env.Isolated(env.Map{"SYSTEMROOT": `D:\Win`}, "windows", cur)
+	if diff := cmp.Diff(got, env.Map{"SYSTEMROOT": `D:\Win`}); diff != "" {
+		t.Errorf("windows, SYSTEMROOT set: %s", diff)
+	}
+	got = env.Isolated(env.Map{"A": "1"}, "linux", cur)
+	if diff := cmp.Diff(got, env.Map{"A": "1"}); diff != "" {
+		t.Errorf("linux: %s", diff)
+	}
+}
+
+func TestOverlayWindows(t *testing.T) {
+	m := env.Map{"Path": `C:\bin`, "TEMP": env.Tombstone}
+	got := env.OverlayOn(env.Map{"PATH": `C:\old`, "Temp": `C:\tmp`, "A": "1"}, m, "windows")
+	want := env.Map{"Path": `C:\bin`, "A": "1"}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("windows: %s", diff)
+	}
+	got = env.OverlayOn(env.Map{"PATH": "/old", "Temp": "/tmp"}, m, "linux")
+	want = env.Map{"PATH": "/old", "Path": `C:\bin`, "Temp": "/tmp"}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("linux: %s", diff)
+	}
+}
+
+func TestExec(t *testing.T) {
+	if os.Getenv("ENV_TEST_EXEC") == "1" {
+		// Running as the helper process: replace ourselves with a
+		// second instance of the test binary, which prints X.
+		err := env.Exec(os.Args[0], []string{os.Args[0], "-test.run=^TestExec$"}, env.Map{
+			"ENV_TEST_EXEC": "2",
+			"X":             "y",
+			"SystemRoot":    os.Getenv("SystemRoot"),
+		})
+		t.Fatal(err)
+	}
+	if os.Getenv("ENV_TEST_EXEC") == "2" {
+		fmt.Printf("X=%s\n", os.Getenv("X"))
+		os.Exit(0)
+	}
+	cmd := env.Command(env.Map{"ENV_TEST_EXEC": "1"}, os.Args[0], "-test.run=^TestExec$")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("%v: %s", err, out)
+	}
+	if got, want := string(out), "X=y\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestExecInvalid(t *testing.T) {
+	m := env.Map{"": "x", "A=B": "y", "NUL": "a\x00b", "OK": "z"}
+	err := env.Exec(os.Args[0], []string{os.Args[0]}, m)
+	var errs env.ErrorList
+	if !errors.As(err, &errs) {
+		t.Fatalf("got %v, want ErrorList", err)
+	}
+	if len(errs) != 3 {
+		t.Errorf("got %d errors, want 3: %v", len(errs), err)
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env
+
+import (
+	"os"
+	"runtime"
+	"strings"
+)
+
+// Syntax is a syntax for variable references in values.
+type Syntax int
+
+// Supported syntaxes.
+const (
+	// UnixSyntax refers to variables as $VAR or ${VAR}, as os.Expand
+	// does. References to unset variables expand to the empty string.
+	UnixSyntax Syntax = iota + 1
+
+	// WindowsSyntax refers to variables as %VAR%, as cmd.exe and
+	// ExpandEnvironmentStrings do. Names are matched ignoring case, and
+	// references to unset or empty variables are left unexpanded.
+	WindowsSyntax
+)
+
+// An ExpandOption configures the expansion of variable references.
+type ExpandOption func(*expandConfig)
+
+type expandConfig struct {
+	syntax  Syntax
+	delayed bool
+}
+
+// WithSyntax selects the syntax of variable references. By default, the
+// syntax is WindowsSyntax on Windows, and UnixSyntax elsewhere.
+func WithSyntax(s Syntax) ExpandOption {
+	return func(cfg *expandConfig) {
+		cfg.syntax = s
+	}
+}
+
+// DelayedExpansion causes !VAR! references to be expanded as well when
+// using WindowsSyntax, as cmd.exe does with delayed expansion enabled.
+// Both kinds of references are expanded in a single pass over the input,
+// so, as with %VAR% references, !VAR! references in the values
+// substituted are left as they are. DelayedExpansion has no effect on
+// other syntaxes.
+func DelayedExpansion() ExpandOption {
+	return func(cfg *expandConfig) {
+		cfg.delayed = true
+	}
+}
+
+// Expand replaces references to variables in s by their values in m.
+// Expansion is not recursive: references in the values substituted
+// are left as they are.
+func (m Map) Expand(s string, opts ...ExpandOption) string {
+	cfg := expandConfig{syntax: defaultSyntax(runtime.GOOS)}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.syntax != WindowsSyntax {
+		return os.Expand(s, func(key string) string { return m[key] })
+	}
+	delims := "%"
+	if cfg.delayed {
+		delims = "%!"
+	}
+	return m.expandDelimited(s, delims)
+}
+
+// defaultSyntax returns the native syntax of goos.
+func defaultSyntax(goos string) Syntax {
+	if goos == "windows" {
+		return WindowsSyntax
+	}
+	return UnixSyntax
+}
+
+// expandDelimited expands references of the form <delim>VAR<delim>,
+// where delim is one of the bytes in delims, following
+// ExpandEnvironmentStrings: if VAR is not set, the opening delimiter is
+// copied, and the closing one may start another reference.
+func (m Map) expandDelimited(s string, delims string) string {
+	if !strings.ContainsAny(s, delims) {
+		return s
+	}
+	f := m.folded()
+	var b strings.Builder
+	for {
+		i := strings.IndexAny(s, delims)
+		if i == -1 {
+			break
+		}
+		j := strings.IndexByte(s[i+1:], s[i])
+		if j == -1 {
+			b.WriteString(s[:i+1])
+			s = s[i+1:]
+			continue
+		}
+		j += i + 1
+		b.WriteString(s[:i])
+		if v := f.lookup(s[i+1 : j]); j > i+1 && v != "" {
+			b.WriteString(v)
+			s = s[j+1:]
+		} else {
+			b.WriteString(s[i:j])
+			s = s[j:]
+		}
+	}
+	b.WriteString(s)
+	return b.String()
+}
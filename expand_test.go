@@ -0,0 +1,80 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env_test
+
+import (
+	"runtime"
+	"testing"
+
+	"acln.ro/env"
+)
+
+func TestExpand(t *testing.T) {
+	m := env.Map{
+		"USERPROFILE": `C:\Users\u`,
+		"Path":        `C:\bin`,
+		"HOME":        "/home/u",
+		"EMPTY":       "",
+		"PCT":         "%HOME%",
+		"BANG":        "!HOME!",
+		"TMP":         `C:\Temp`,
+		"tmp":         `D:\tmp`,
+	}
+	unix := env.WithSyntax(env.UnixSyntax)
+	windows := env.WithSyntax(env.WindowsSyntax)
+	tests := []struct {
+		in   string
+		opts []env.ExpandOption
+		want string
+	}{
+		{"$HOME/bin", []env.ExpandOption{unix}, "/home/u/bin"},
+		{"${HOME}/bin:$MISSING", []env.ExpandOption{unix}, "/home/u/bin:"},
+		{"%HOME%/bin", []env.ExpandOption{unix}, "%HOME%/bin"},
+		{`%USERPROFILE%\bin`, []env.ExpandOption{windows}, `C:\Users\u\bin`},
+		{`%userprofile%\bin;%PATH%`, []env.ExpandOption{windows}, `C:\Users\u\bin;C:\bin`},
+		{"$HOME", []env.ExpandOption{windows}, "$HOME"},
+		{"%MISSING%", []env.ExpandOption{windows}, "%MISSING%"},
+		{"%EMPTY%", []env.ExpandOption{windows}, "%EMPTY%"},
+		{"100%", []env.ExpandOption{windows}, "100%"},
+		{"%%", []env.ExpandOption{windows}, "%%"},
+		{"50%MISSING%HOME%", []env.ExpandOption{windows}, "50%MISSING/home/u"},
+		{"%PCT%", []env.ExpandOption{windows}, "%HOME%"},
+		{"%Tmp%", []env.ExpandOption{windows}, `C:\Temp`},
+		{"!HOME!", []env.ExpandOption{windows}, "!HOME!"},
+		{"!HOME!%HOME%!MISSING!", []env.ExpandOption{windows, env.DelayedExpansion()}, "/home/u/home/u!MISSING!"},
+		{"!HOME!", []env.ExpandOption{unix, env.DelayedExpansion()}, "!HOME!"},
+		{"%BANG%!HOME!", []env.ExpandOption{windows, env.DelayedExpansion()}, "!HOME!/home/u"},
+		{"100% !HOME!", []env.ExpandOption{windows, env.DelayedExpansion()}, "100% /home/u"},
+	}
+	for _, tt := range tests {
+		if got := m.Expand(tt.in, tt.opts...); got != tt.want {
+			t.Errorf("Expand(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestExpandDefaultSyntax(t *testing.T) {
+	m := env.Map{"X": "x"}
+	want := "x"
+	if runtime.GOOS == "windows" {
+		if got := m.Expand("%X%"); got != want {
+			t.Errorf("Expand = %q, want %q", got, want)
+		}
+		return
+	}
+	if got := m.Expand("$X"); got != want {
+		t.Errorf("Expand = %q, want %q", got, want)
+	}
+}
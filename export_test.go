@@ -0,0 +1,47 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env
+
+// Platform-specific lookups, exported for testing on any platform.
+var (
+	HomeFor    = Map.home
+	TempDirFor = Map.tempDir
+	UserFor    = Map.user
+)
+
+var (
+	// Isolated returns the environment CommandIsolated uses on goos.
+	Isolated = isolated
+
+	// BaselineFor returns the baseline environment for goos.
+	BaselineFor = baseline
+
+	// OverlayOn overlays a Map onto a base environment, as on goos.
+	OverlayOn = overlay
+
+	// BuildFrom builds a child environment from the specified base.
+	BuildFrom = build
+
+	// Colorized writes a Diff with colors enabled or disabled.
+	Colorized = Diff.colorized
+
+	// ExpandHomeWith expands home directory references using the
+	// specified lookups.
+	ExpandHomeWith = expandHome
+
+	// DetectRuntimeWith detects the runtime, given whether /.dockerenv
+	// exists.
+	DetectRuntimeWith = detectRuntime
+)
@@ -0,0 +1,39 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env
+
+import "expvar"
+
+// Publish publishes an expvar named name, holding the variables named by
+// keys, as set in m at the time of the call. If no keys are specified,
+// all variables in m are published. Values of variables marked as secret
+// by DefaultPolicy are redacted. Keys which are not set in m are omitted.
+//
+// Like expvar.Publish, Publish panics if name is already in use.
+func Publish(name string, m Map, keys ...string) {
+	if len(keys) == 0 {
+		keys = m.keys()
+	}
+	snapshot := make(Map, len(keys))
+	for _, k := range keys {
+		if v, ok := m[k]; ok {
+			snapshot[k] = v
+		}
+	}
+	snapshot = snapshot.Redact(DefaultPolicy)
+	expvar.Publish(name, expvar.Func(func() interface{} {
+		return map[string]string(snapshot)
+	}))
+}
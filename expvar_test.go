@@ -0,0 +1,51 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env_test
+
+import (
+	"encoding/json"
+	"expvar"
+	"testing"
+
+	"acln.ro/env"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestPublish(t *testing.T) {
+	m := env.Map{
+		"LISTEN_ADDR": ":8080",
+		"DB_PASSWORD": "hunter2",
+		"UNPUBLISHED": "x",
+	}
+	env.Publish("env-test-publish", m, "LISTEN_ADDR", "DB_PASSWORD", "MISSING")
+	m["LISTEN_ADDR"] = ":9090"
+
+	v := expvar.Get("env-test-publish")
+	if v == nil {
+		t.Fatal("expvar not published")
+	}
+	var got map[string]string
+	if err := json.Unmarshal([]byte(v.String()), &got); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"LISTEN_ADDR": ":8080",
+		"DB_PASSWORD": "xxxxx",
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("published %v, want %v: %s", got, want, diff)
+	}
+}
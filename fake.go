@@ -0,0 +1,32 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env
+
+// Fake returns a Store holding a copy of m, for use in tests as a stand-in
+// for the environment of the current process. Install it in a context
+// using WithProcess, so that code under test which resolves variables
+// using LookupContext, or loads them from ProcessFrom, observes the
+// variables in the Store, rather than the real environment:
+//
+//	fake := env.Fake(env.Map{"LOG_LEVEL": "debug"})
+//	ctx := env.WithProcess(context.Background(), fake)
+//	run(ctx) // env.GetContext(ctx, "LOG_LEVEL") returns "debug"
+//
+// Since nothing global is modified, tests using Fake can run in parallel.
+// The Store is safe for concurrent use, so tests can change variables
+// while the code under test runs.
+func Fake(m Map) *Store {
+	return NewStore(m)
+}
@@ -0,0 +1,82 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"acln.ro/env"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFake(t *testing.T) {
+	os.Setenv("ENV_TEST_FAKE", "process")
+	defer os.Unsetenv("ENV_TEST_FAKE")
+
+	fake := env.Fake(env.Map{"LOG_LEVEL": "debug"})
+	ctx := env.WithProcess(context.Background(), fake)
+	if got := env.GetContext(ctx, "LOG_LEVEL"); got != "debug" {
+		t.Errorf("GetContext(LOG_LEVEL) = %q, want %q", got, "debug")
+	}
+	if _, ok := env.LookupContext(ctx, "ENV_TEST_FAKE"); ok {
+		t.Error("process environment visible through fake")
+	}
+
+	fake.Set("LOG_LEVEL", "info")
+	if got := env.GetContext(ctx, "LOG_LEVEL"); got != "info" {
+		t.Errorf("after Set: GetContext(LOG_LEVEL) = %q, want %q", got, "info")
+	}
+	overlaid := env.NewContext(ctx, env.Map{"LOG_LEVEL": "warn"})
+	if got := env.GetContext(overlaid, "LOG_LEVEL"); got != "warn" {
+		t.Errorf("overlay on fake: GetContext(LOG_LEVEL) = %q, want %q", got, "warn")
+	}
+
+	m, err := env.ProcessFrom(ctx).Environ(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(m, env.Map{"LOG_LEVEL": "info"}); diff != "" {
+		t.Errorf("ProcessFrom(ctx).Environ: %s", diff)
+	}
+	if env.ProcessFrom(context.Background()) != env.Process {
+		t.Error("ProcessFrom on a bare context is not Process")
+	}
+}
+
+func TestFakeParallel(t *testing.T) {
+	for _, level := range []string{"debug", "info", "warn"} {
+		level := level
+		t.Run(level, func(t *testing.T) {
+			t.Parallel()
+			ctx := env.WithProcess(context.Background(), env.Fake(env.Map{"LOG_LEVEL": level}))
+			if got := env.GetContext(ctx, "LOG_LEVEL"); got != level {
+				t.Errorf("got %q, want %q", got, level)
+			}
+		})
+	}
+}
+
+func TestWithProcessMap(t *testing.T) {
+	ctx := env.WithProcess(context.Background(), env.Map{"A": "1"})
+	if v, ok := env.LookupContext(ctx, "A"); !ok || v != "1" {
+		t.Errorf("LookupContext(A) = %q, %t", v, ok)
+	}
+	if _, ok := env.LookupContext(ctx, "PATH"); ok {
+		t.Error("process environment visible through Map")
+	}
+}
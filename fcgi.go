@@ -0,0 +1,90 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// EncodeFastCGI encodes the Map as a sequence of FastCGI name-value pairs,
+// suitable for use as the content of FCGI_PARAMS records. Pairs are
+// sorted lexicographically by key.
+//
+// Splitting the encoding into records of at most 65535 bytes is left to
+// the caller.
+func (m Map) EncodeFastCGI() []byte {
+	var b []byte
+	for _, k := range m.keys() {
+		v := m[k]
+		b = appendFastCGILength(b, len(k))
+		b = appendFastCGILength(b, len(v))
+		b = append(b, k...)
+		b = append(b, v...)
+	}
+	return b
+}
+
+func appendFastCGILength(b []byte, n int) []byte {
+	if n < 0x80 {
+		return append(b, byte(n))
+	}
+	var buf [4]byte
+	binary.BigEndian.PutUint32(buf[:], uint32(n)|1<<31)
+	return append(b, buf[:]...)
+}
+
+// errShortFastCGI signals a truncated name-value pair.
+var errShortFastCGI = errors.New("env: truncated FastCGI name-value pair")
+
+// DecodeFastCGI decodes a sequence of FastCGI name-value pairs, such as
+// the concatenated content of the FCGI_PARAMS records of a request.
+func DecodeFastCGI(b []byte) (Map, error) {
+	m := make(Map)
+	for len(b) > 0 {
+		klen, n := readFastCGILength(b)
+		if n == 0 {
+			return nil, errShortFastCGI
+		}
+		b = b[n:]
+		vlen, n := readFastCGILength(b)
+		if n == 0 {
+			return nil, errShortFastCGI
+		}
+		b = b[n:]
+		if uint64(len(b)) < uint64(klen)+uint64(vlen) {
+			return nil, errShortFastCGI
+		}
+		m[string(b[:klen])] = string(b[klen : klen+vlen])
+		b = b[klen+vlen:]
+	}
+	return m, nil
+}
+
+// readFastCGILength reads a length from the beginning of b. It returns
+// the length, and the number of bytes consumed, which is zero if b is
+// too short.
+func readFastCGILength(b []byte) (uint32, int) {
+	if len(b) == 0 {
+		return 0, 0
+	}
+	if b[0]>>7 == 0 {
+		return uint32(b[0]), 1
+	}
+	if len(b) < 4 {
+		return 0, 0
+	}
+	return binary.BigEndian.Uint32(b) &^ (1 << 31), 4
+}
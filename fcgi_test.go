@@ -0,0 +1,67 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env_test
+
+import (
+	"strings"
+	"testing"
+
+	"acln.ro/env"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestEncodeFastCGI(t *testing.T) {
+	m := env.Map{"SCRIPT_NAME": "/x", "A": ""}
+	got := m.EncodeFastCGI()
+	want := []byte("\x01\x00A\x0b\x02SCRIPT_NAME/x")
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("%v.EncodeFastCGI() = %q, want %q: %s", m, got, want, diff)
+	}
+}
+
+func TestFastCGIRoundTrip(t *testing.T) {
+	tests := []env.Map{
+		{},
+		{"k": "v"},
+		{"k": ""},
+		{"REQUEST_METHOD": "GET", "QUERY_STRING": "a=b"},
+		{"LONG": strings.Repeat("x", 200), strings.Repeat("K", 128): "v"},
+	}
+	for _, m := range tests {
+		got, err := env.DecodeFastCGI(m.EncodeFastCGI())
+		if err != nil {
+			t.Errorf("DecodeFastCGI for %v: %v", m, err)
+			continue
+		}
+		if diff := cmp.Diff(got, m); diff != "" {
+			t.Errorf("round trip: got %v, want %v: %s", got, m, diff)
+		}
+	}
+}
+
+func TestDecodeFastCGIErrors(t *testing.T) {
+	tests := [][]byte{
+		[]byte("\x01"),
+		[]byte("\x01\x01k"),
+		[]byte("\x80\x00"),
+		[]byte("\x80\x00\x00\x05\x00abc"),
+	}
+	for _, b := range tests {
+		if m, err := env.DecodeFastCGI(b); err == nil {
+			t.Errorf("DecodeFastCGI(%q) = %v, want error", b, m)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// Sync causes files to be flushed to stable storage before they replace
+// the files they update, and the directory containing them to be flushed
+// afterwards, so that an update survives a system crash. Sync only
+// applies to functions which write files.
+func Sync() DotenvOption {
+	return func(cfg *dotenvConfig) {
+		cfg.sync = true
+	}
+}
+
+// WriteDotenvFile writes m to the named file, in the format produced by
+// WriteDotenv, and subject to the same options. The file is replaced
+// atomically, as described by EditFile. New files are created with mode
+// 0600, since environment files often hold secrets.
+func (m Map) WriteDotenvFile(path string, opts ...DotenvOption) error {
+	var cfg dotenvConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return writeFileAtomic(path, func(f *os.File) error {
+		return m.WriteDotenv(f, opts...)
+	}, cfg.sync)
+}
+
+// writeFileAtomic replaces the file at path, or the file it links to, with
+// a new file holding the data written by write. The data is written to a
+// temporary file in the same directory, which is then renamed over the
+// original, so that readers see either the old contents, or the new
+// ones, and never a partially written file. The mode of an existing file
+// is preserved, and so is its ownership, where the process is allowed to
+// set it.
+func writeFileAtomic(path string, write func(f *os.File) error, sync bool) (err error) {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	fi, err := os.Stat(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	dir, base := filepath.Split(path)
+	if dir == "" {
+		dir = "."
+	}
+	f, err := ioutil.TempFile(dir, "."+base+".tmp")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			f.Close()
+			os.Remove(f.Name())
+		}
+	}()
+	mode := os.FileMode(0600)
+	if fi != nil {
+		mode = fi.Mode().Perm()
+		if err := chown(f, fi); err != nil {
+			return err
+		}
+	}
+	if err := f.Chmod(mode); err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		return err
+	}
+	if sync {
+		if err := f.Sync(); err != nil {
+			return err
+		}
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(f.Name(), path); err != nil {
+		return err
+	}
+	if sync {
+		return syncDir(dir)
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"acln.ro/env"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWriteDotenvFile(t *testing.T) {
+	dir := writeFiles(t, nil)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, ".env")
+	m := env.Map{"A": "1", "B": "two words"}
+	if err := m.WriteDotenvFile(path, env.Sync()); err != nil {
+		t.Fatal(err)
+	}
+	got, err := env.ReadDotenvFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(got, m); diff != "" {
+		t.Errorf("round trip: %s", diff)
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if runtime.GOOS != "windows" && fi.Mode().Perm() != 0600 {
+		t.Errorf("new file mode = %v, want 0600", fi.Mode().Perm())
+	}
+	assertNoTempFiles(t, dir)
+}
+
+func TestWriteDotenvFilePreservesMode(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file modes are not meaningful on Windows")
+	}
+	dir := writeFiles(t, map[string]string{"target.env": "A=0\n"})
+	defer os.RemoveAll(dir)
+	target := filepath.Join(dir, "target.env")
+	if err := os.Chmod(target, 0640); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(dir, ".env")
+	if err := os.Symlink("target.env", link); err != nil {
+		t.Fatal(err)
+	}
+	if err := (env.Map{"A": "1"}).WriteDotenvFile(link); err != nil {
+		t.Fatal(err)
+	}
+	if fi, err := os.Lstat(link); err != nil || fi.Mode()&os.ModeSymlink == 0 {
+		t.Errorf("symlink replaced by regular file: %v, %v", fi, err)
+	}
+	fi, err := os.Stat(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Mode().Perm() != 0640 {
+		t.Errorf("mode = %v, want 0640", fi.Mode().Perm())
+	}
+	if m, _ := env.ReadDotenvFile(target); m["A"] != "1" {
+		t.Errorf("target not updated: %v", m)
+	}
+	assertNoTempFiles(t, dir)
+}
+
+func TestEditFileAtomicFailure(t *testing.T) {
+	dir := writeFiles(t, map[string]string{".env": "A=0\n"})
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, ".env")
+	missing := filepath.Join(dir, "missing", ".env")
+	err := env.EditFile(missing, func(doc *env.Document) error {
+		doc.Set("A", "1")
+		return nil
+	})
+	if err == nil {
+		t.Error("EditFile of missing file succeeded")
+	}
+	err = env.EditFile(path, func(doc *env.Document) error {
+		doc.Set("A", "1")
+		return nil
+	}, env.Sync())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertNoTempFiles(t, dir)
+}
+
+// assertNoTempFiles checks that dir holds no temporary files left behind
+// by atomic writes.
+func assertNoTempFiles(t *testing.T, dir string) {
+	t.Helper()
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, fi := range infos {
+		if matched, _ := filepath.Match(".*.tmp*", fi.Name()); matched {
+			t.Errorf("temporary file %s left behind", fi.Name())
+		}
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env
+
+import (
+	"io/ioutil"
+)
+
+// FileSuffix is the suffix of variables which name files holding the
+// values of other variables. See FromFiles.
+const FileSuffix = "_FILE"
+
+// FromFiles configures the loading of the specified variables using the
+// convention of Docker and Kubernetes secrets: for a variable named FOO,
+// if FOO_FILE is set instead, its value is the path of a file holding
+// the value of FOO. A single trailing newline is removed from the file
+// contents, and FOO_FILE is removed from the loaded Map.
+//
+// Setting both FOO and FOO_FILE is an error. So is a file which cannot
+// be read. Errors are reported together with those for required
+// variables, and variables resolved from files satisfy Require.
+func FromFiles(keys ...string) LoadOption {
+	return func(cfg *loadConfig) {
+		cfg.fileKeys = append(cfg.fileKeys, keys...)
+	}
+}
+
+// resolveFiles resolves the variables named by keys from files, as
+// described by FromFiles.
+func resolveFiles(m Map, keys []string) ErrorList {
+	var errs ErrorList
+	for _, key := range keys {
+		fileKey := key + FileSuffix
+		path, ok := m[fileKey]
+		if !ok {
+			continue
+		}
+		delete(m, fileKey)
+		if _, set := m[key]; set {
+			errs = append(errs, &ValidationError{
+				Key:    key,
+				Reason: "both " + key + " and " + fileKey + " are set",
+			})
+			continue
+		}
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			errs = append(errs, invalid(fileKey, err))
+			continue
+		}
+		m[key] = trimNewline(string(b))
+	}
+	return errs
+}
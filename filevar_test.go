@@ -0,0 +1,79 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"acln.ro/env"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFromFiles(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"db_password": "hunter2\n",
+		"api_token":   "t0ken",
+	})
+	defer os.RemoveAll(dir)
+
+	c := env.Chain{env.Map{
+		"DB_PASSWORD_FILE": filepath.Join(dir, "db_password"),
+		"API_TOKEN_FILE":   filepath.Join(dir, "api_token"),
+		"OTHER_FILE":       "/not/resolved",
+	}}
+	got, err := c.Load(context.Background(),
+		env.FromFiles("DB_PASSWORD", "API_TOKEN"),
+		env.Require("DB_PASSWORD", "API_TOKEN"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := env.Map{
+		"DB_PASSWORD": "hunter2",
+		"API_TOKEN":   "t0ken",
+		"OTHER_FILE":  "/not/resolved",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("(-want +got)\n%s", diff)
+	}
+
+	tests := []struct {
+		name string
+		m    env.Map
+	}{
+		{
+			name: "both set",
+			m: env.Map{
+				"DB_PASSWORD":      "x",
+				"DB_PASSWORD_FILE": filepath.Join(dir, "db_password"),
+			},
+		},
+		{
+			name: "missing file",
+			m:    env.Map{"DB_PASSWORD_FILE": filepath.Join(dir, "missing")},
+		},
+	}
+	for _, tt := range tests {
+		_, err := env.Chain{tt.m}.Load(context.Background(), env.FromFiles("DB_PASSWORD"))
+		var verr *env.ValidationError
+		if !errors.As(err, &verr) {
+			t.Errorf("%s: got %v, want ValidationError", tt.name, err)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env
+
+import (
+	"sort"
+	"strings"
+)
+
+// Frozen is a compact, read-only representation of a set of environment
+// variables. All keys and values are stored in a single buffer, as
+// "key=value" pairs sorted by key, so a Frozen retains two allocations
+// regardless of the number of variables, and considerably less memory
+// than the equivalent Map. This makes it suitable for holding many
+// environment snapshots in memory.
+//
+// A Frozen has no methods which modify it, so it is safe to share between
+// goroutines without synchronization. Lookups in a Frozen take
+// logarithmic time. The zero Frozen is empty.
+type Frozen struct {
+	buf string
+
+	// offs holds two offsets into buf for each pair: the offset of the
+	// '=' separator, and the offset of the end of the pair. Each pair
+	// starts where the previous one ends.
+	offs []uint32
+}
+
+// NewFrozen returns a Frozen holding the variables in m.
+func NewFrozen(m Map) Frozen {
+	keys := m.keys()
+	sb := new(strings.Builder)
+	sb.Grow(m.encodedSize())
+	offs := make([]uint32, 0, 2*len(keys))
+	for _, k := range keys {
+		sb.WriteString(k)
+		offs = append(offs, uint32(sb.Len()))
+		sb.WriteByte('=')
+		sb.WriteString(m[k])
+		offs = append(offs, uint32(sb.Len()))
+	}
+	return Frozen{buf: sb.String(), offs: offs}
+}
+
+// Freeze returns a Frozen holding the variables in m. Subsequent changes
+// to m do not affect the Frozen.
+func (m Map) Freeze() Frozen {
+	return NewFrozen(m)
+}
+
+// Len returns the number of variables in f.
+func (f Frozen) Len() int {
+	return len(f.offs) / 2
+}
+
+// pair returns the key and value of the i'th pair.
+func (f Frozen) pair(i int) (key, value string) {
+	start := 0
+	if i > 0 {
+		start = int(f.offs[2*i-1])
+	}
+	eq, end := int(f.offs[2*i]), int(f.offs[2*i+1])
+	return f.buf[start:eq], f.buf[eq+1 : end]
+}
+
+// Lookup returns the value of the variable named by key, and whether it
+// is set.
+func (f Frozen) Lookup(key string) (string, bool) {
+	n := f.Len()
+	i := sort.Search(n, func(i int) bool {
+		k, _ := f.pair(i)
+		return k >= key
+	})
+	if i == n {
+		return "", false
+	}
+	k, v := f.pair(i)
+	if k != key {
+		return "", false
+	}
+	return v, true
+}
+
+// Get returns the value of the variable named by key, or the empty string
+// if the variable is not set.
+func (f Frozen) Get(key string) string {
+	v, _ := f.Lookup(key)
+	return v
+}
+
+// Each calls fn for each variable in f, in lexicographic order of keys.
+func (f Frozen) Each(fn func(key, value string)) {
+	for i := 0; i < f.Len(); i++ {
+		fn(f.pair(i))
+	}
+}
+
+// Map returns a Map holding the variables in f.
+func (f Frozen) Map() Map {
+	m := make(Map, f.Len())
+	f.Each(func(k, v string) {
+		m[k] = v
+	})
+	return m
+}
+
+// Encode encodes f as a slice of "key=value" pairs, sorted by key. The
+// pairs share memory with f.
+func (f Frozen) Encode() []string {
+	kvs := make([]string, f.Len())
+	start := 0
+	for i := range kvs {
+		end := int(f.offs[2*i+1])
+		kvs[i] = f.buf[start:end]
+		start = end
+	}
+	return kvs
+}
+
+// String encodes f as space-separated "key=value" pairs, sorted by key.
+func (f Frozen) String() string {
+	return strings.Join(f.Encode(), " ")
+}
@@ -0,0 +1,108 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env_test
+
+import (
+	"sync"
+	"testing"
+
+	"acln.ro/env"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFrozen(t *testing.T) {
+	tests := []env.Map{
+		{},
+		{"k": "v"},
+		{"k": ""},
+		{"FOO": "x", "BAR": "", "BAZ": "a=b"},
+	}
+	for _, m := range tests {
+		f := env.NewFrozen(m)
+		if f.Len() != len(m) {
+			t.Errorf("NewFrozen(%v).Len() = %d, want %d", m, f.Len(), len(m))
+		}
+		for k, want := range m {
+			if got, ok := f.Lookup(k); got != want || !ok {
+				t.Errorf("NewFrozen(%v).Lookup(%q) = %q, %t, want %q, true", m, k, got, ok, want)
+			}
+		}
+		for _, k := range []string{"", "A", "BAZZ", "ZZZ"} {
+			if _, ok := f.Lookup(k); ok {
+				t.Errorf("NewFrozen(%v).Lookup(%q) reported the variable as set", m, k)
+			}
+		}
+		if diff := cmp.Diff(f.Map(), m); diff != "" {
+			t.Errorf("NewFrozen(%v).Map() = %v: %s", m, f.Map(), diff)
+		}
+		if diff := cmp.Diff(f.Encode(), m.Encode()); diff != "" {
+			t.Errorf("NewFrozen(%v).Encode() = %q: %s", m, f.Encode(), diff)
+		}
+		if got, want := f.String(), m.String(); got != want {
+			t.Errorf("NewFrozen(%v).String() = %q, want %q", m, got, want)
+		}
+	}
+}
+
+func TestFrozenZero(t *testing.T) {
+	var f env.Frozen
+	if f.Len() != 0 {
+		t.Errorf("zero Frozen has length %d", f.Len())
+	}
+	if _, ok := f.Lookup("k"); ok {
+		t.Error("zero Frozen has variable k")
+	}
+}
+
+func BenchmarkNewFrozen(b *testing.B) {
+	m := benchmarkMap(5000)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		env.NewFrozen(m)
+	}
+}
+
+func BenchmarkFrozenLookup(b *testing.B) {
+	f := env.NewFrozen(benchmarkMap(5000))
+	for i := 0; i < b.N; i++ {
+		f.Lookup("VARIABLE_02500")
+	}
+}
+
+func TestFreeze(t *testing.T) {
+	m := env.Map{"PATH": "/bin", "HOME": "/root"}
+	f := m.Freeze()
+	m["PATH"] = "/usr/bin"
+	delete(m, "HOME")
+	if got := f.Get("PATH"); got != "/bin" {
+		t.Errorf("Get(PATH) = %q, want %q", got, "/bin")
+	}
+	if got := f.Get("HOME"); got != "/root" {
+		t.Errorf("Get(HOME) = %q, want %q", got, "/root")
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			f.Lookup("PATH")
+			f.Encode()
+			f.Map()
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,67 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Handler returns an http.Handler which serves the environment provided
+// by source, with the values of variables marked as secret by redaction
+// redacted. If redaction is nil, DefaultPolicy is used.
+//
+// The output format is selected by the "format" query parameter, which
+// may be "text", "json" or "dotenv". In the absence of the parameter, JSON
+// is served if the request prefers it by way of the Accept header, and
+// text is served otherwise.
+func Handler(source Source, redaction Policy) http.Handler {
+	if redaction == nil {
+		redaction = DefaultPolicy
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m, err := source.Environ(r.Context())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		m = m.Redact(redaction)
+		format := r.URL.Query().Get("format")
+		if format == "" {
+			format = "text"
+			if strings.Contains(r.Header.Get("Accept"), "application/json") {
+				format = "json"
+			}
+		}
+		w.Header().Set("Cache-Control", "no-store")
+		switch format {
+		case "text":
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			fmt.Fprintf(w, "%+v\n", m)
+		case "json":
+			w.Header().Set("Content-Type", "application/json")
+			enc := json.NewEncoder(w)
+			enc.SetIndent("", "\t")
+			enc.Encode(map[string]string(m))
+		case "dotenv":
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			m.WriteDotenv(w)
+		default:
+			http.Error(w, fmt.Sprintf("unknown format %q", format), http.StatusBadRequest)
+		}
+	})
+}
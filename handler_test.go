@@ -0,0 +1,96 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"acln.ro/env"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestHandler(t *testing.T) {
+	m := env.Map{"ADDR": ":8080", "DB_PASSWORD": "hunter2"}
+	h := env.Handler(m, nil)
+	tests := []struct {
+		target string
+		accept string
+		ctype  string
+		want   string
+	}{
+		{
+			target: "/debug/env",
+			ctype:  "text/plain; charset=utf-8",
+			want:   "ADDR=:8080\nDB_PASSWORD=xxxxx\n",
+		},
+		{
+			target: "/debug/env",
+			accept: "application/json",
+			ctype:  "application/json",
+			want:   "{\n\t\"ADDR\": \":8080\",\n\t\"DB_PASSWORD\": \"xxxxx\"\n}\n",
+		},
+		{
+			target: "/debug/env?format=dotenv",
+			ctype:  "text/plain; charset=utf-8",
+			want:   "ADDR=:8080\nDB_PASSWORD=xxxxx\n",
+		},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", tt.target, nil)
+		if tt.accept != "" {
+			r.Header.Set("Accept", tt.accept)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		if w.Code != http.StatusOK {
+			t.Errorf("GET %s: status %d", tt.target, w.Code)
+		}
+		if ctype := w.Header().Get("Content-Type"); ctype != tt.ctype {
+			t.Errorf("GET %s: Content-Type %q, want %q", tt.target, ctype, tt.ctype)
+		}
+		if diff := cmp.Diff(w.Body.String(), tt.want); diff != "" {
+			t.Errorf("GET %s: got %q, want %q: %s", tt.target, w.Body.String(), tt.want, diff)
+		}
+	}
+}
+
+type failingSource struct{}
+
+func (failingSource) Environ(ctx context.Context) (env.Map, error) {
+	return nil, errors.New("unavailable")
+}
+
+func TestHandlerErrors(t *testing.T) {
+	tests := []struct {
+		h      http.Handler
+		target string
+		code   int
+	}{
+		{env.Handler(env.Map{}, nil), "/?format=yaml", http.StatusBadRequest},
+		{env.Handler(failingSource{}, nil), "/", http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		tt.h.ServeHTTP(w, httptest.NewRequest("GET", tt.target, nil))
+		if w.Code != tt.code {
+			t.Errorf("GET %s: status %d, want %d", tt.target, w.Code, tt.code)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"strings"
+)
+
+// ExpandHome is like Map.ExpandHome, for the environment of the current
+// process.
+func ExpandHome(path string) (string, error) {
+	return Variables().ExpandHome(path)
+}
+
+// ExpandHomeIn is like Map.ExpandHome, for the variables of e.
+func ExpandHomeIn(e Environment, path string) (string, error) {
+	return e.All().ExpandHome(path)
+}
+
+// ExpandHome expands a leading "~", "~user" or "%USERPROFILE%" in path to
+// the corresponding home directory, so that values such as "~/data" name
+// the intended directory, rather than one called "~". The prefix must
+// make up the whole first element of path. Other paths are returned
+// unchanged.
+//
+// "~" and "%USERPROFILE%" expand to m.Home(). "~user" expands to the home
+// directory of the named user, as recorded by the operating system. It is
+// an error if the home directory cannot be determined.
+func (m Map) ExpandHome(path string) (string, error) {
+	return expandHome(path, m.Home, lookupUserHome)
+}
+
+func lookupUserHome(name string) (string, error) {
+	u, err := user.Lookup(name)
+	if err != nil {
+		return "", err
+	}
+	return u.HomeDir, nil
+}
+
+const userProfileRef = "%" + KeyUserProfile + "%"
+
+func expandHome(path string, home func() string, userHome func(string) (string, error)) (string, error) {
+	var prefix, dir string
+	switch {
+	case strings.HasPrefix(path, "~"):
+		prefix = path[:firstSeparator(path)]
+		if prefix == "~" {
+			dir = home()
+			break
+		}
+		d, err := userHome(prefix[1:])
+		if err != nil {
+			return "", fmt.Errorf("expanding %s: %v", prefix, err)
+		}
+		dir = d
+	case len(path) >= len(userProfileRef) && strings.EqualFold(path[:len(userProfileRef)], userProfileRef):
+		prefix = path[:len(userProfileRef)]
+		if len(path) > len(prefix) && !os.IsPathSeparator(path[len(prefix)]) {
+			return path, nil
+		}
+		dir = home()
+	default:
+		return path, nil
+	}
+	if dir == "" {
+		return "", errors.New("expanding " + prefix + ": home directory is unknown")
+	}
+	return dir + path[len(prefix):], nil
+}
+
+// firstSeparator returns the index of the first path separator in path,
+// or len(path) if there is none.
+func firstSeparator(path string) int {
+	for i := 0; i < len(path); i++ {
+		if os.IsPathSeparator(path[i]) {
+			return i
+		}
+	}
+	return len(path)
+}
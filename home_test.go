@@ -0,0 +1,93 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env_test
+
+import (
+	"errors"
+	"testing"
+
+	"acln.ro/env"
+)
+
+func TestExpandHome(t *testing.T) {
+	home := func() string { return "/home/gopher" }
+	userHome := func(name string) (string, error) {
+		if name == "root" {
+			return "/root", nil
+		}
+		return "", errors.New("unknown user " + name)
+	}
+	tests := []struct {
+		in   string
+		want string
+		err  bool
+	}{
+		{in: "", want: ""},
+		{in: "/var/lib/data", want: "/var/lib/data"},
+		{in: "data/~", want: "data/~"},
+		{in: "~", want: "/home/gopher"},
+		{in: "~/data", want: "/home/gopher/data"},
+		{in: "~root/.cache", want: "/root/.cache"},
+		{in: "~nobody/x", err: true},
+		{in: "%USERPROFILE%/data", want: "/home/gopher/data"},
+		{in: "%userprofile%", want: "/home/gopher"},
+		{in: "%USERPROFILE%x", want: "%USERPROFILE%x"},
+	}
+	for _, tt := range tests {
+		got, err := env.ExpandHomeWith(tt.in, home, userHome)
+		if (err != nil) != tt.err {
+			t.Errorf("ExpandHome(%q): got error %v, want error: %t", tt.in, err, tt.err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ExpandHome(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+
+	if _, err := env.ExpandHomeWith("~/x", func() string { return "" }, userHome); err == nil {
+		t.Error("ExpandHome with unknown home directory succeeded")
+	}
+}
+
+func TestDecodeHome(t *testing.T) {
+	var cfg struct {
+		Data  string `env:"DATA_DIR,home"`
+		Cache string `env:"CACHE_DIR,home" default:"~/.cache"`
+		Plain string `env:"PLAIN_DIR"`
+	}
+	m := env.Map{
+		"HOME":        "/home/gopher",
+		"USERPROFILE": "/home/gopher",
+		"DATA_DIR":    "~/data",
+		"PLAIN_DIR":   "~/plain",
+	}
+	if err := env.Decode(m, &cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Data != "/home/gopher/data" || cfg.Cache != "/home/gopher/.cache" || cfg.Plain != "~/plain" {
+		t.Errorf("got %+v", cfg)
+	}
+}
+
+func TestExpandHomeIn(t *testing.T) {
+	e := env.Fake(env.Map{"HOME": "/home/fake", "USERPROFILE": "/home/fake"})
+	got, err := env.ExpandHomeIn(e, "~/data")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "/home/fake/data"; got != want {
+		t.Errorf("ExpandHomeIn = %q, want %q", got, want)
+	}
+}
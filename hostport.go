@@ -0,0 +1,146 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// A HostPort is a network address made up of a host and a port, parsed
+// from a value such as "db.internal:5432", "0.0.0.0:443" or "[::1]:9090".
+type HostPort struct {
+	// Host is a host name, or an IP address, without the brackets
+	// which surround IPv6 addresses in host:port form. It is empty in
+	// listen addresses which refer to all interfaces, such as ":8080".
+	Host string
+
+	// Port is the port number.
+	Port int
+}
+
+// String returns the address in host:port form, bracketing IPv6
+// addresses, as expected by the net package.
+func (hp HostPort) String() string {
+	return net.JoinHostPort(hp.Host, strconv.Itoa(hp.Port))
+}
+
+// ParseHostPort parses s as a host:port pair. The host must not be empty,
+// IPv6 addresses must be enclosed in brackets, and the port must be a
+// number between 1 and 65535.
+func ParseHostPort(s string) (HostPort, error) {
+	hp, err := parseAddr(s)
+	if err != nil {
+		return HostPort{}, err
+	}
+	if hp.Host == "" {
+		return HostPort{}, fmt.Errorf("address %s has no host", s)
+	}
+	if hp.Port == 0 {
+		return HostPort{}, fmt.Errorf("address %s has port 0", s)
+	}
+	return hp, nil
+}
+
+// ParseListenAddr parses s as an address to listen on, as accepted by
+// net.Listen. Unlike in ParseHostPort, the host may be empty, to listen
+// on all interfaces, and the port may be 0, to listen on any free port.
+func ParseListenAddr(s string) (HostPort, error) {
+	return parseAddr(s)
+}
+
+func parseAddr(s string) (HostPort, error) {
+	host, port, err := net.SplitHostPort(s)
+	if err != nil {
+		var aerr *net.AddrError
+		if errors.As(err, &aerr) && strings.Count(s, ":") > 1 && !strings.HasPrefix(s, "[") {
+			return HostPort{}, fmt.Errorf("address %s: IPv6 addresses must be enclosed in brackets, as in [::1]:8080", s)
+		}
+		return HostPort{}, fmt.Errorf("address %s: %v", s, unwrapAddrError(err))
+	}
+	if err := checkHost(host, strings.HasPrefix(s, "[")); err != nil {
+		return HostPort{}, fmt.Errorf("address %s: %v", s, err)
+	}
+	n, err := strconv.ParseUint(port, 10, 16)
+	if err != nil {
+		return HostPort{}, fmt.Errorf("address %s: invalid port %q", s, port)
+	}
+	return HostPort{Host: host, Port: int(n)}, nil
+}
+
+// checkHost checks that host is a plausible host name or IP address.
+// bracketed reports whether host was enclosed in brackets, in which case
+// it must be an IPv6 address, optionally followed by a zone.
+func checkHost(host string, bracketed bool) error {
+	if bracketed {
+		ip := host
+		if i := strings.IndexByte(ip, '%'); i >= 0 {
+			ip = ip[:i]
+		}
+		if net.ParseIP(ip) == nil || !strings.Contains(ip, ":") {
+			return fmt.Errorf("invalid IPv6 address %q", host)
+		}
+		return nil
+	}
+	for i := 0; i < len(host); i++ {
+		c := host[i]
+		if !isAlnum(c) && c != '.' && c != '-' && c != '_' {
+			return fmt.Errorf("invalid host %q", host)
+		}
+	}
+	return nil
+}
+
+func isAlnum(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9'
+}
+
+// unwrapAddrError returns the description of a *net.AddrError, without
+// the address, which is redundant in the messages above.
+func unwrapAddrError(err error) error {
+	if aerr, ok := err.(*net.AddrError); ok {
+		return errors.New(aerr.Err)
+	}
+	return err
+}
+
+// HostPort parses the value of the variable named by key as a host:port
+// pair, as described by ParseHostPort. If the variable is not set, the
+// error wraps ErrNotFound.
+func (m Map) HostPort(key string) (HostPort, error) {
+	return m.addr(key, ParseHostPort)
+}
+
+// ListenAddr parses the value of the variable named by key as an address
+// to listen on, as described by ParseListenAddr. If the variable is not
+// set, the error wraps ErrNotFound.
+func (m Map) ListenAddr(key string) (HostPort, error) {
+	return m.addr(key, ParseListenAddr)
+}
+
+func (m Map) addr(key string, parse func(string) (HostPort, error)) (HostPort, error) {
+	v, ok := m[key]
+	if !ok {
+		return HostPort{}, notSet(key)
+	}
+	hp, err := parse(v)
+	if err != nil {
+		return HostPort{}, invalid(key, err)
+	}
+	return hp, nil
+}
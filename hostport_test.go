@@ -0,0 +1,112 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"acln.ro/env"
+)
+
+func TestParseHostPort(t *testing.T) {
+	tests := []struct {
+		in    string
+		want  env.HostPort
+		error string // substring of the error, if any
+	}{
+		{in: "db.internal:5432", want: env.HostPort{Host: "db.internal", Port: 5432}},
+		{in: "10.0.0.1:80", want: env.HostPort{Host: "10.0.0.1", Port: 80}},
+		{in: "[::1]:9090", want: env.HostPort{Host: "::1", Port: 9090}},
+		{in: "[fe80::1%eth0]:22", want: env.HostPort{Host: "fe80::1%eth0", Port: 22}},
+		{in: ":8080", error: "no host"},
+		{in: "db:0", error: "port 0"},
+		{in: "db", error: "missing port"},
+		{in: "db:http", error: "invalid port"},
+		{in: "db:70000", error: "invalid port"},
+		{in: "::1:9090", error: "enclosed in brackets"},
+		{in: "[10.0.0.1]:80", error: "invalid IPv6 address"},
+		{in: "d b:80", error: "invalid host"},
+	}
+	for _, tt := range tests {
+		got, err := env.ParseHostPort(tt.in)
+		if tt.error != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.error) {
+				t.Errorf("ParseHostPort(%q): got %v, want error containing %q", tt.in, err, tt.error)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseHostPort(%q): %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseHostPort(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+		if s := got.String(); s != tt.in {
+			t.Errorf("String() = %q, want %q", s, tt.in)
+		}
+	}
+}
+
+func TestParseListenAddr(t *testing.T) {
+	tests := []struct {
+		in   string
+		want env.HostPort
+		err  bool
+	}{
+		{in: ":8080", want: env.HostPort{Port: 8080}},
+		{in: "0.0.0.0:443", want: env.HostPort{Host: "0.0.0.0", Port: 443}},
+		{in: "[::1]:9090", want: env.HostPort{Host: "::1", Port: 9090}},
+		{in: "localhost:0", want: env.HostPort{Host: "localhost"}},
+		{in: "8080", err: true},
+		{in: ":65536", err: true},
+	}
+	for _, tt := range tests {
+		got, err := env.ParseListenAddr(tt.in)
+		if (err != nil) != tt.err {
+			t.Errorf("ParseListenAddr(%q): got error %v, want error: %t", tt.in, err, tt.err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseListenAddr(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestMapHostPort(t *testing.T) {
+	m := env.Map{"LISTEN_ADDR": ":8080", "DB_ADDR": ":5432"}
+	if hp, err := m.ListenAddr("LISTEN_ADDR"); err != nil || hp.Port != 8080 {
+		t.Errorf("ListenAddr = %+v, %v", hp, err)
+	}
+	var verr *env.ValidationError
+	if _, err := m.HostPort("DB_ADDR"); !errors.As(err, &verr) || verr.Key != "DB_ADDR" {
+		t.Errorf("HostPort(DB_ADDR): got %v, want ValidationError", err)
+	}
+	if _, err := m.HostPort("UNSET"); !errors.Is(err, env.ErrNotFound) {
+		t.Errorf("HostPort(UNSET): got %v, want ErrNotFound", err)
+	}
+
+	var cfg struct {
+		Listen string `env:"LISTEN_ADDR" validate:"listen"`
+		DB     string `env:"DB_ADDR" validate:"hostport"`
+	}
+	err := env.Decode(m, &cfg)
+	var errs env.ErrorList
+	if !errors.As(err, &errs) || len(errs) != 1 || !errors.As(errs[0], &verr) || verr.Key != "DB_ADDR" {
+		t.Errorf("Decode: got %v, want one error for DB_ADDR", err)
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env
+
+import (
+	"runtime"
+	"strings"
+)
+
+// An Inherit policy selects the variables of the current process which a
+// child process inherits, by reporting whether the variable named by key
+// is inherited. Policies compose as functions do: for example, a policy
+// inheriting everything except secrets is
+//
+//	func(key string) bool { return !env.DefaultPolicy(key) }
+type Inherit func(key string) bool
+
+// InheritAll inherits every variable.
+var InheritAll Inherit = func(string) bool { return true }
+
+// InheritNone inherits no variables.
+var InheritNone Inherit = func(string) bool { return false }
+
+// InheritOnly returns a policy which inherits only the variables whose
+// names match at least one of the patterns, in the syntax of AcceptEnv.
+func InheritOnly(patterns ...string) Inherit {
+	return inheritMatching(patterns, true)
+}
+
+// InheritExcept returns a policy which inherits every variable except
+// those whose names match at least one of the patterns, in the syntax of
+// AcceptEnv.
+func InheritExcept(patterns ...string) Inherit {
+	return inheritMatching(patterns, false)
+}
+
+// inheritMatching returns a policy which inherits the variables matching
+// the patterns if match is set, or those not matching them otherwise.
+func inheritMatching(patterns []string, match bool) Inherit {
+	var pats []string
+	for _, p := range patterns {
+		pats = append(pats, strings.Fields(p)...)
+	}
+	return func(key string) bool {
+		for _, p := range pats {
+			if matchSSHPattern(p, key) {
+				return match
+			}
+		}
+		return !match
+	}
+}
+
+// Filter returns the variables in m which p inherits.
+func (p Inherit) Filter(m Map) Map {
+	f := make(Map)
+	for k, v := range m {
+		if p(k) {
+			f[k] = v
+		}
+	}
+	return f
+}
+
+// Build returns the environment of a child process: the variables of the
+// current process which policy inherits, overlaid with each of the
+// overlays in turn. Variables set to Tombstone in an overlay are removed.
+// On Windows, names are compared case-insensitively, as by Apply.
+func Build(policy Inherit, overlays ...Map) Map {
+	return build(Variables(), policy, overlays)
+}
+
+// BuildIn is like Build, but inherits the variables of e, rather than
+// those of the current process.
+func BuildIn(e Environment, policy Inherit, overlays ...Map) Map {
+	return build(e.All(), policy, overlays)
+}
+
+func build(base Map, policy Inherit, overlays []Map) Map {
+	b := policy.Filter(base)
+	for _, m := range overlays {
+		overlay(b, m, runtime.GOOS)
+	}
+	return b
+}
@@ -0,0 +1,143 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env_test
+
+import (
+	"os"
+	"os/exec"
+	"testing"
+
+	"acln.ro/env"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestBuild(t *testing.T) {
+	base := env.Map{
+		"HOME":         "/home/u",
+		"PATH":         "/usr/bin",
+		"LC_ALL":       "C",
+		"LC_TIME":      "C",
+		"AWS_TOKEN":    "t",
+		"DATABASE_URL": "postgres://",
+	}
+	tests := []struct {
+		name     string
+		policy   env.Inherit
+		overlays []env.Map
+		want     env.Map
+	}{
+		{
+			name:   "all",
+			policy: env.InheritAll,
+			want:   base,
+		},
+		{
+			name:     "none",
+			policy:   env.InheritNone,
+			overlays: []env.Map{{"A": "1"}},
+			want:     env.Map{"A": "1"},
+		},
+		{
+			name:   "only",
+			policy: env.InheritOnly("HOME PATH", "LC_*"),
+			want: env.Map{
+				"HOME":    "/home/u",
+				"PATH":    "/usr/bin",
+				"LC_ALL":  "C",
+				"LC_TIME": "C",
+			},
+		},
+		{
+			name:   "except",
+			policy: env.InheritExcept("LC_*", "DATABASE_URL"),
+			want: env.Map{
+				"HOME":      "/home/u",
+				"PATH":      "/usr/bin",
+				"AWS_TOKEN": "t",
+			},
+		},
+		{
+			name:   "except secrets, with overrides",
+			policy: func(key string) bool { return !env.DefaultPolicy(key) },
+			overlays: []env.Map{
+				{"PATH": "/opt/bin", "LC_ALL": env.Tombstone},
+				{"PATH": "/bin", "X": "x"},
+			},
+			want: env.Map{
+				"HOME":         "/home/u",
+				"PATH":         "/bin",
+				"LC_TIME":      "C",
+				"DATABASE_URL": "postgres://",
+				"X":            "x",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := env.BuildFrom(base, tt.policy, tt.overlays)
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Errorf("Build: %s", diff)
+			}
+		})
+	}
+	if base["LC_ALL"] != "C" {
+		t.Error("Build modified its base")
+	}
+}
+
+func TestCommandWith(t *testing.T) {
+	if _, err := exec.LookPath("env"); err != nil {
+		t.Skip("env not available")
+	}
+	defer restoreEnv(env.Variables())
+	os.Setenv("ENV_TEST_INHERITED", "inherited")
+	os.Setenv("ENV_TEST_NOT_INHERITED", "x")
+	policy := env.InheritOnly("ENV_TEST_INHERITED")
+	out, err := env.CommandWith(policy, env.Map{"ENV_TEST_ADDED": "added"}, "env").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(out), "ENV_TEST_ADDED=added\nENV_TEST_INHERITED=inherited\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestBuildIn(t *testing.T) {
+	defer restoreEnv(env.Variables())
+	os.Setenv("ENV_TEST_PROCESS", "x")
+	e := env.Fake(env.Map{"ENV_TEST_FAKE": "fake", "ENV_TEST_DROPPED": "x"})
+	got := env.BuildIn(e, env.InheritOnly("ENV_TEST_*"), env.Map{"ENV_TEST_DROPPED": env.Tombstone})
+	if diff := cmp.Diff(got, env.Map{"ENV_TEST_FAKE": "fake"}); diff != "" {
+		t.Errorf("BuildIn: %s", diff)
+	}
+}
+
+func TestCommandWithIn(t *testing.T) {
+	if _, err := exec.LookPath("env"); err != nil {
+		t.Skip("env not available")
+	}
+	defer restoreEnv(env.Variables())
+	os.Setenv("ENV_TEST_PROCESS", "x")
+	e := env.Fake(env.Map{"ENV_TEST_INHERITED": "inherited"})
+	policy := env.InheritOnly("ENV_TEST_*")
+	out, err := env.CommandWithIn(e, policy, env.Map{"ENV_TEST_ADDED": "added"}, "env").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(out), "ENV_TEST_ADDED=added\nENV_TEST_INHERITED=inherited\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,157 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// ReadINI reads variables from an INI file. Keys in a [section] are
+// prefixed by the name of the section and an underscore, and names are
+// converted to the conventional form of environment variable names:
+// upper case, with characters other than letters, digits and underscores
+// replaced by underscores. For example,
+//
+//	[database]
+//	host = db.internal
+//	max-conns = 16
+//
+// reads as DATABASE_HOST=db.internal and DATABASE_MAX_CONNS=16. Keys
+// before the first section are not prefixed.
+//
+// Lines starting with ';' or '#' are comments. Whitespace around keys and
+// values is ignored. Values may be enclosed in double or single quotes,
+// which are removed, to preserve such whitespace. Later definitions of a
+// variable override earlier ones. Malformed lines are reported together,
+// as an ErrorList of *ParseError.
+func ReadINI(r io.Reader) (Map, error) {
+	m := make(Map)
+	var errs ErrorList
+	prefix := ""
+	sc := bufio.NewScanner(r)
+	for line := 1; sc.Scan(); line++ {
+		s := strings.TrimSpace(sc.Text())
+		switch {
+		case s == "" || s[0] == ';' || s[0] == '#':
+		case s[0] == '[':
+			if !strings.HasSuffix(s, "]") || len(s) == 2 {
+				errs = append(errs, &ParseError{Line: line, Input: s, Err: errors.New("malformed section header")})
+				continue
+			}
+			prefix = iniName(s[1:len(s)-1]) + "_"
+		default:
+			eq := strings.IndexByte(s, '=')
+			if eq <= 0 {
+				errs = append(errs, &ParseError{Line: line, Input: s, Err: errors.New("missing '='")})
+				continue
+			}
+			key := strings.TrimSpace(s[:eq])
+			m[prefix+iniName(key)] = iniUnquote(strings.TrimSpace(s[eq+1:]))
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if err := errs.err(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// iniName converts an INI section or key name to an environment variable
+// name, as described by ReadINI.
+func iniName(s string) string {
+	b := []byte(strings.ToUpper(strings.TrimSpace(s)))
+	for i, c := range b {
+		if !('A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '_') {
+			b[i] = '_'
+		}
+	}
+	return string(b)
+}
+
+// iniUnquote removes a pair of matching quotes around s.
+func iniUnquote(s string) string {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+// WriteINI writes m to w as an INI file, reversing the mapping performed
+// by ReadINI: the part of each key before the first underscore names its
+// section, and the rest names the key in the section. Section and key
+// names are written in lower case. Variables whose names contain no
+// underscore are written before the first section. Sections and keys are
+// sorted.
+//
+// Values which begin or end with whitespace or quotes are enclosed in
+// double quotes. Values containing newlines
+// cannot be represented; if there are any, nothing is written, and they
+// are reported together, as an ErrorList.
+func (m Map) WriteINI(w io.Writer) error {
+	var errs ErrorList
+	for _, k := range m.keys() {
+		if strings.ContainsAny(m[k], "\r\n") {
+			errs = append(errs, &ValidationError{Key: k, Reason: "value contains a newline"})
+		}
+	}
+	if err := errs.err(); err != nil {
+		return err
+	}
+	var global []string
+	sections := make(map[string][]string)
+	var names []string
+	for _, k := range m.keys() {
+		i := strings.IndexByte(k, '_')
+		if i <= 0 || i == len(k)-1 {
+			global = append(global, k)
+			continue
+		}
+		if _, ok := sections[k[:i]]; !ok {
+			names = append(names, k[:i])
+		}
+		sections[k[:i]] = append(sections[k[:i]], k)
+	}
+	sort.Strings(names)
+	bw := bufio.NewWriter(w)
+	for _, k := range global {
+		fmt.Fprintf(bw, "%s = %s\n", strings.ToLower(k), iniQuote(m[k]))
+	}
+	for i, sec := range names {
+		if i > 0 || len(global) > 0 {
+			bw.WriteString("\n")
+		}
+		fmt.Fprintf(bw, "[%s]\n", strings.ToLower(sec))
+		for _, k := range sections[sec] {
+			fmt.Fprintf(bw, "%s = %s\n", strings.ToLower(k[len(sec)+1:]), iniQuote(m[k]))
+		}
+	}
+	return bw.Flush()
+}
+
+// iniQuote encloses v in double quotes if ReadINI would not otherwise
+// read it back unchanged.
+func iniQuote(v string) string {
+	if v != strings.TrimSpace(v) || iniUnquote(v) != v {
+		return `"` + v + `"`
+	}
+	return v
+}
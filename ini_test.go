@@ -0,0 +1,120 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"acln.ro/env"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestReadINI(t *testing.T) {
+	input := `; global settings
+debug = true
+
+[database]
+host = db.internal
+max-conns=16
+# quoted values keep their whitespace
+password = " p;w "
+
+[log.output]
+path = '/var/log/app.log'
+path = /var/log/app2.log
+`
+	got, err := env.ReadINI(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := env.Map{
+		"DEBUG":              "true",
+		"DATABASE_HOST":      "db.internal",
+		"DATABASE_MAX_CONNS": "16",
+		"DATABASE_PASSWORD":  " p;w ",
+		"LOG_OUTPUT_PATH":    "/var/log/app2.log",
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("ReadINI: %s", diff)
+	}
+}
+
+func TestReadINIErrors(t *testing.T) {
+	input := "[ok]\na = 1\n[broken\nno equals\n= value\n[]\n"
+	_, err := env.ReadINI(strings.NewReader(input))
+	var errs env.ErrorList
+	if !errors.As(err, &errs) {
+		t.Fatalf("got %v, want ErrorList", err)
+	}
+	var lines []int
+	for _, err := range errs {
+		lines = append(lines, err.(*env.ParseError).Line)
+	}
+	if diff := cmp.Diff(lines, []int{3, 4, 5, 6}); diff != "" {
+		t.Errorf("error lines: %s", diff)
+	}
+}
+
+func TestWriteINI(t *testing.T) {
+	m := env.Map{
+		"DEBUG":              "true",
+		"DATABASE_HOST":      "db.internal",
+		"DATABASE_MAX_CONNS": "16",
+		"DATABASE_PASSWORD":  " p;w ",
+		"APP_NAME":           `"quoted"`,
+		"PORT_":              "x",
+	}
+	var buf bytes.Buffer
+	if err := m.WriteINI(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := `debug = true
+port_ = x
+
+[app]
+name = ""quoted""
+
+[database]
+host = db.internal
+max_conns = 16
+password = " p;w "
+`
+	if diff := cmp.Diff(buf.String(), want); diff != "" {
+		t.Errorf("WriteINI: %s", diff)
+	}
+	got, err := env.ReadINI(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(got, m); diff != "" {
+		t.Errorf("ReadINI(WriteINI(m)): %s", diff)
+	}
+}
+
+func TestWriteININewlines(t *testing.T) {
+	var buf bytes.Buffer
+	err := env.Map{"A_B": "x\ny", "C": "ok"}.WriteINI(&buf)
+	var verr *env.ValidationError
+	if !errors.As(err, &verr) || verr.Key != "A_B" {
+		t.Fatalf("got %v, want ValidationError for A_B", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("wrote %q despite errors", buf.String())
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env
+
+import (
+	"encoding/json"
+	"strconv"
+)
+
+// Patterns matching the values accepted for each Type, for use in JSON
+// Schema documents.
+var typePatterns = map[Type]string{
+	Int:      `^[+-]?[0-9]+$`,
+	Float:    `^[+-]?(([0-9]+(\.[0-9]*)?|\.[0-9]+)([eE][+-]?[0-9]+)?|[iI][nN][fF]([iI][nN][iI][tT][yY])?|[nN][aA][nN])$`,
+	Duration: `^([+-]?0|[+-]?(([0-9]+(\.[0-9]*)?|\.[0-9]+)(ns|us|µs|μs|ms|s|m|h))+)$`,
+
+	HostPortType: `^([0-9A-Za-z._-]+|\[[0-9A-Fa-f:.]*:[0-9A-Fa-f:.]*(%[^\]]+)?\]):` + portPattern + `$`,
+	ListenAddr:   `^([0-9A-Za-z._-]*|\[[0-9A-Fa-f:.]*:[0-9A-Fa-f:.]*(%[^\]]+)?\]):(0+|` + portPattern + `)$`,
+}
+
+// portPattern matches port numbers between 1 and 65535.
+const portPattern = `0*([1-9][0-9]{0,3}|[1-5][0-9]{4}|6[0-4][0-9]{3}|65[0-4][0-9]{2}|655[0-2][0-9]|6553[0-5])`
+
+// boolValues lists the values accepted for the Bool type.
+var boolValues = []string{"1", "t", "T", "TRUE", "true", "True", "0", "f", "F", "FALSE", "false", "False"}
+
+// JSONSchema returns a JSON Schema (draft-07) document describing the
+// environment contract of s, as an object the properties of which are
+// the variables. Since the values of variables are strings, every
+// property is of type string, constrained by a pattern, enumeration or
+// format which matches the values Validate accepts for its Type. Secret
+// variables are marked writeOnly. Conditional requirements are expressed
+// as if-then-else subschemas. Variables not described by s are permitted.
+func (s *Schema) JSONSchema() ([]byte, error) {
+	props := make(map[string]interface{}, len(s.Vars))
+	var (
+		required    []string
+		conditional []interface{}
+	)
+	for _, v := range s.Vars {
+		p := map[string]interface{}{"type": "string"}
+		switch v.Type {
+		case Bool:
+			p["enum"] = boolValues
+		case URLType:
+			p["format"] = "uri-reference"
+		default:
+			if pattern, ok := typePatterns[v.Type]; ok {
+				p["pattern"] = pattern
+			}
+		}
+		if len(v.Enum) > 0 {
+			p["enum"] = v.Enum
+		}
+		if v.Pattern != "" {
+			if _, ok := p["pattern"]; ok {
+				p["allOf"] = []interface{}{map[string]interface{}{"pattern": anchored(v.Pattern)}}
+			} else {
+				p["pattern"] = anchored(v.Pattern)
+			}
+		}
+		if v.Description != "" {
+			p["description"] = v.Description
+		}
+		if v.Default != "" {
+			p["default"] = v.Default
+		}
+		if v.Secret {
+			p["writeOnly"] = true
+		}
+		props[v.Key] = p
+		if v.Default != "" {
+			continue
+		}
+		if v.Required {
+			required = append(required, v.Key)
+			continue
+		}
+		conditional = append(conditional, conditionalRequirements(v)...)
+	}
+	doc := map[string]interface{}{
+		"$schema":    "http://json-schema.org/draft-07/schema#",
+		"type":       "object",
+		"properties": props,
+	}
+	if s.Version != 0 {
+		doc["$comment"] = "schema version " + strconv.Itoa(s.Version)
+	}
+	if len(required) > 0 {
+		doc["required"] = required
+	}
+	if len(conditional) > 0 {
+		doc["allOf"] = conditional
+	}
+	return json.MarshalIndent(doc, "", "\t")
+}
+
+// conditionalRequirements returns if-then-else subschemas which express
+// the RequiredIf and RequiredUnless conditions of v.
+func conditionalRequirements(v Var) []interface{} {
+	then := map[string]interface{}{"required": []string{v.Key}}
+	var subs []interface{}
+	for _, c := range v.RequiredIf {
+		subs = append(subs, map[string]interface{}{"if": c.jsonSchema(), "then": then})
+	}
+	if len(v.RequiredUnless) > 0 {
+		conds := make([]interface{}, 0, len(v.RequiredUnless))
+		for _, c := range v.RequiredUnless {
+			conds = append(conds, c.jsonSchema())
+		}
+		subs = append(subs, map[string]interface{}{"if": map[string]interface{}{"anyOf": conds}, "else": then})
+	}
+	return subs
+}
+
+// jsonSchema returns a subschema matching the objects for which c holds.
+func (c Condition) jsonSchema() map[string]interface{} {
+	sub := map[string]interface{}{"required": []string{c.Key}}
+	if !c.Any {
+		sub["properties"] = map[string]interface{}{
+			c.Key: map[string]interface{}{"const": c.Value},
+		}
+	}
+	return sub
+}
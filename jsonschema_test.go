@@ -0,0 +1,109 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env_test
+
+import (
+	"encoding/json"
+	"regexp"
+	"testing"
+
+	"acln.ro/env"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestJSONSchema(t *testing.T) {
+	schema := &env.Schema{
+		Version: 2,
+		Vars: []env.Var{
+			{Key: "DATABASE_URL", Type: env.URLType, Required: true, Secret: true, Description: "primary database"},
+			{Key: "PORT", Type: env.Int, Required: true, Default: "8080"},
+			{Key: "DEBUG", Type: env.Bool},
+			{Key: "NAME"},
+		},
+	}
+	data, err := schema.JSONSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		Schema     string                            `json:"$schema"`
+		Type       string                            `json:"type"`
+		Required   []string                          `json:"required"`
+		Properties map[string]map[string]interface{} `json:"properties"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.Type != "object" || doc.Schema == "" {
+		t.Errorf("got type %q, $schema %q", doc.Type, doc.Schema)
+	}
+	if diff := cmp.Diff(doc.Required, []string{"DATABASE_URL"}); diff != "" {
+		t.Errorf("required: %s", diff)
+	}
+	db := doc.Properties["DATABASE_URL"]
+	if db["format"] != "uri-reference" || db["writeOnly"] != true || db["description"] != "primary database" {
+		t.Errorf("DATABASE_URL: %v", db)
+	}
+	if doc.Properties["PORT"]["default"] != "8080" {
+		t.Errorf("PORT: %v", doc.Properties["PORT"])
+	}
+	if _, ok := doc.Properties["DEBUG"]["enum"]; !ok {
+		t.Errorf("DEBUG: %v", doc.Properties["DEBUG"])
+	}
+	if diff := cmp.Diff(doc.Properties["NAME"], map[string]interface{}{"type": "string"}); diff != "" {
+		t.Errorf("NAME: %s", diff)
+	}
+}
+
+// TestJSONSchemaPatterns checks that the patterns in JSON Schema
+// documents agree with Schema.Validate.
+func TestJSONSchemaPatterns(t *testing.T) {
+	values := map[env.Type][]string{
+		env.Int:      {"0", "42", "-7", "+3", "0x1F", "0b101", "0o17", "017", "1_000", "", "1.5", "abc", "0x", "- 1"},
+		env.Float:    {"0", "1.5", "-.5", "1e10", "2.5E-3", "Inf", "-inf", "NaN", "", "1.2.3", "e5", "abc"},
+		env.Duration: {"0", "1s", "1.5h", "-2m30s", "100ms", "1µs", "", "1", "1d", "s", "1s2"},
+		env.HostPortType: {
+			"db:5432", "10.0.0.1:80", "[::1]:9090", "[fe80::1%eth0]:22", "a_b.c-d:65535", "x:08080",
+			"", ":8080", "db", "db:0", "db:65536", "db:http", "::1:80", "[10.0.0.1]:80", "d b:80", "db:-1",
+		},
+		env.ListenAddr: {
+			":8080", ":0", "0.0.0.0:443", "[::]:80", "[::1]:9090", "localhost:00",
+			"", "8080", ":", ":65536", "::1:80", "[]:80", "host:port",
+		},
+	}
+	for typ, vals := range values {
+		schema := &env.Schema{Vars: []env.Var{{Key: "V", Type: typ}}}
+		data, err := schema.JSONSchema()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var doc struct {
+			Properties map[string]struct {
+				Pattern string `json:"pattern"`
+			} `json:"properties"`
+		}
+		if err := json.Unmarshal(data, &doc); err != nil {
+			t.Fatal(err)
+		}
+		re := regexp.MustCompile(doc.Properties["V"].Pattern)
+		for _, v := range vals {
+			valid := schema.Validate(env.Map{"V": v}) == nil
+			if matched := re.MatchString(v); matched != valid {
+				t.Errorf("%s %q: pattern matches = %t, Validate accepts = %t", typ, v, matched, valid)
+			}
+		}
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// JSON unmarshals the JSON value of the variable named by key from m into
+// the value pointed to by v, as in json.Unmarshal. Errors are reported as
+// a *ValidationError naming the variable, whose reason describes where in
+// the value decoding failed, and which wraps the error from the json
+// package.
+func JSON(m Map, key string, v interface{}) error {
+	s, ok := m[key]
+	if !ok {
+		return notSet(key)
+	}
+	if strings.TrimSpace(s) == "" {
+		return &ValidationError{Key: key, Reason: "empty JSON value"}
+	}
+	if err := json.Unmarshal([]byte(s), v); err != nil {
+		return &ValidationError{Key: key, Reason: jsonReason(s, err), Err: err}
+	}
+	return nil
+}
+
+// jsonReason describes a decoding error from the json package in terms of
+// the variable's value.
+func jsonReason(s string, err error) string {
+	var (
+		serr *json.SyntaxError
+		terr *json.UnmarshalTypeError
+	)
+	switch {
+	case errors.As(err, &serr):
+		return fmt.Sprintf("invalid JSON at offset %d, near %q: %v",
+			serr.Offset, jsonContext(s, serr.Offset), serr)
+	case errors.As(err, &terr) && terr.Field != "":
+		return fmt.Sprintf("JSON %s at %q cannot be decoded into %v",
+			terr.Value, terr.Field, terr.Type)
+	case errors.As(err, &terr):
+		return fmt.Sprintf("JSON %s cannot be decoded into %v", terr.Value, terr.Type)
+	default:
+		return err.Error()
+	}
+}
+
+// jsonContext returns the part of s leading up to offset, limited to a few
+// bytes, for pointing at the location of a syntax error.
+func jsonContext(s string, offset int64) string {
+	const n = 16
+	end := int(offset)
+	if end > len(s) {
+		end = len(s)
+	}
+	start := end - n
+	if start < 0 {
+		start = 0
+	}
+	return s[start:end]
+}
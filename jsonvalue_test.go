@@ -0,0 +1,81 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env_test
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"acln.ro/env"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type features struct {
+	Beta    bool     `json:"beta"`
+	Regions []string `json:"regions"`
+	Limits  struct {
+		RPS int `json:"rps"`
+	} `json:"limits"`
+}
+
+func TestJSON(t *testing.T) {
+	m := env.Map{
+		"FEATURES_JSON": `{"beta": true, "regions": ["eu", "us"], "limits": {"rps": 100}}`,
+		"BAD_SYNTAX":    `{"beta": true,, "regions": []}`,
+		"BAD_TYPE":      `{"limits": {"rps": "fast"}}`,
+		"EMPTY":         " ",
+	}
+
+	var got features
+	if err := env.JSON(m, "FEATURES_JSON", &got); err != nil {
+		t.Fatal(err)
+	}
+	want := features{Beta: true, Regions: []string{"eu", "us"}}
+	want.Limits.RPS = 100
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("(-want +got)\n%s", diff)
+	}
+
+	tests := []struct {
+		key    string
+		substr string
+		target interface{}
+	}{
+		{key: "BAD_SYNTAX", substr: "offset 15", target: new(*json.SyntaxError)},
+		{key: "BAD_TYPE", substr: `"limits.rps"`, target: new(*json.UnmarshalTypeError)},
+		{key: "EMPTY", substr: "empty JSON value"},
+		{key: "UNSET", substr: "not set"},
+	}
+	for _, tt := range tests {
+		err := env.JSON(m, tt.key, new(features))
+		var verr *env.ValidationError
+		if !errors.As(err, &verr) || verr.Key != tt.key {
+			t.Errorf("%s: got %v, want ValidationError", tt.key, err)
+			continue
+		}
+		if !strings.Contains(verr.Reason, tt.substr) {
+			t.Errorf("%s: reason %q does not contain %q", tt.key, verr.Reason, tt.substr)
+		}
+		if tt.target != nil && !errors.As(err, tt.target) {
+			t.Errorf("%s: got %v, want %T", tt.key, err, tt.target)
+		}
+	}
+	if err := env.JSON(m, "UNSET", new(features)); !errors.Is(err, env.ErrNotFound) {
+		t.Errorf("UNSET: got %v, want ErrNotFound", err)
+	}
+}
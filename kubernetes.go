@@ -0,0 +1,218 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// A PodEnv is the environment of the containers of a Kubernetes pod, as
+// configured in its specification.
+type PodEnv struct {
+	Namespace  string
+	Name       string
+	Containers []ContainerEnv // init containers first, then the others
+}
+
+// A ContainerEnv is the environment of a container in a Kubernetes pod.
+type ContainerEnv struct {
+	Name string
+	Init bool // whether the container is an init container
+
+	// Env holds the variables with literal values.
+	Env Map
+
+	// ValueFrom holds the variables whose values come from elsewhere,
+	// such as Secrets, by name.
+	ValueFrom map[string]ValueFrom
+
+	// EnvFrom holds the ConfigMaps and Secrets all of whose keys are
+	// imported as variables, in order.
+	EnvFrom []ValueFrom
+}
+
+// ValueFromKind is the kind of source of a value.
+type ValueFromKind string
+
+// Kinds of value sources. The names are those of the fields of the pod
+// specification.
+const (
+	ConfigMapKeyRef  ValueFromKind = "configMapKeyRef"
+	SecretKeyRef     ValueFromKind = "secretKeyRef"
+	FieldRef         ValueFromKind = "fieldRef"
+	ResourceFieldRef ValueFromKind = "resourceFieldRef"
+	ConfigMapRef     ValueFromKind = "configMapRef" // in EnvFrom only
+	SecretRef        ValueFromKind = "secretRef"    // in EnvFrom only
+)
+
+// A ValueFrom is a placeholder for values which Kubernetes fills in when
+// it starts a container.
+type ValueFrom struct {
+	Kind ValueFromKind
+
+	Name     string // ConfigMap or Secret name
+	Key      string // key in the ConfigMap or Secret, for key references
+	Optional bool   // whether the ConfigMap, Secret or key may be missing
+
+	FieldPath string // for FieldRef, such as "status.podIP"
+	Resource  string // for ResourceFieldRef, such as "limits.memory"
+
+	Prefix string // for EnvFrom, prepended to each key
+}
+
+// String returns a short description of v, such as
+// "secretKeyRef:db-creds/password" or "fieldRef:status.podIP".
+func (v ValueFrom) String() string {
+	s := string(v.Kind) + ":"
+	switch v.Kind {
+	case FieldRef:
+		return s + v.FieldPath
+	case ResourceFieldRef:
+		return s + v.Resource
+	case ConfigMapKeyRef, SecretKeyRef:
+		return s + v.Name + "/" + v.Key
+	default:
+		return s + v.Name
+	}
+}
+
+type k8sKeyRef struct {
+	Name     string `json:"name"`
+	Key      string `json:"key,omitempty"`
+	Optional bool   `json:"optional,omitempty"`
+}
+
+type k8sContainer struct {
+	Name string `json:"name"`
+	Env  []struct {
+		Name      string `json:"name"`
+		Value     string `json:"value"`
+		ValueFrom *struct {
+			ConfigMapKeyRef *k8sKeyRef `json:"configMapKeyRef"`
+			SecretKeyRef    *k8sKeyRef `json:"secretKeyRef"`
+			FieldRef        *struct {
+				FieldPath string `json:"fieldPath"`
+			} `json:"fieldRef"`
+			ResourceFieldRef *struct {
+				Resource string `json:"resource"`
+			} `json:"resourceFieldRef"`
+		} `json:"valueFrom"`
+	} `json:"env"`
+	EnvFrom []struct {
+		Prefix       string     `json:"prefix"`
+		ConfigMapRef *k8sKeyRef `json:"configMapRef"`
+		SecretRef    *k8sKeyRef `json:"secretRef"`
+	} `json:"envFrom"`
+}
+
+type k8sPod struct {
+	Kind     string `json:"kind"`
+	Metadata struct {
+		Name      string `json:"name"`
+		Namespace string `json:"namespace"`
+	} `json:"metadata"`
+	Spec struct {
+		InitContainers []k8sContainer `json:"initContainers"`
+		Containers     []k8sContainer `json:"containers"`
+	} `json:"spec"`
+	Items []k8sPod `json:"items"`
+}
+
+// FromKubectlPods returns the environments of the pods described by data,
+// the output of "kubectl get pod -o json", which is a single Pod, or a
+// List of them. Variables whose values come from ConfigMaps, Secrets or
+// the pod itself are recorded as ValueFrom placeholders, rather than
+// dropped.
+func FromKubectlPods(data []byte) ([]PodEnv, error) {
+	var obj k8sPod
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, fmt.Errorf("env: kubectl pod: %v", err)
+	}
+	switch obj.Kind {
+	case "Pod":
+		return []PodEnv{obj.podEnv()}, nil
+	case "List", "PodList":
+		pods := make([]PodEnv, 0, len(obj.Items))
+		for _, item := range obj.Items {
+			if item.Kind != "" && item.Kind != "Pod" {
+				return nil, fmt.Errorf("env: kubectl pod: unexpected %s in list", item.Kind)
+			}
+			pods = append(pods, item.podEnv())
+		}
+		return pods, nil
+	default:
+		return nil, fmt.Errorf("env: kubectl pod: unexpected kind %q", obj.Kind)
+	}
+}
+
+func (p k8sPod) podEnv() PodEnv {
+	pe := PodEnv{Namespace: p.Metadata.Namespace, Name: p.Metadata.Name}
+	for _, c := range p.Spec.InitContainers {
+		pe.Containers = append(pe.Containers, c.containerEnv(true))
+	}
+	for _, c := range p.Spec.Containers {
+		pe.Containers = append(pe.Containers, c.containerEnv(false))
+	}
+	return pe
+}
+
+func (c k8sContainer) containerEnv(init bool) ContainerEnv {
+	ce := ContainerEnv{Name: c.Name, Init: init, Env: make(Map)}
+	for _, e := range c.Env {
+		vf := e.ValueFrom
+		if vf == nil {
+			ce.Env[e.Name] = e.Value
+			delete(ce.ValueFrom, e.Name)
+			continue
+		}
+		var v ValueFrom
+		switch {
+		case vf.ConfigMapKeyRef != nil:
+			v = keyRef(ConfigMapKeyRef, vf.ConfigMapKeyRef)
+		case vf.SecretKeyRef != nil:
+			v = keyRef(SecretKeyRef, vf.SecretKeyRef)
+		case vf.FieldRef != nil:
+			v = ValueFrom{Kind: FieldRef, FieldPath: vf.FieldRef.FieldPath}
+		case vf.ResourceFieldRef != nil:
+			v = ValueFrom{Kind: ResourceFieldRef, Resource: vf.ResourceFieldRef.Resource}
+		default:
+			continue
+		}
+		if ce.ValueFrom == nil {
+			ce.ValueFrom = make(map[string]ValueFrom)
+		}
+		ce.ValueFrom[e.Name] = v
+		delete(ce.Env, e.Name)
+	}
+	for _, ef := range c.EnvFrom {
+		var v ValueFrom
+		switch {
+		case ef.ConfigMapRef != nil:
+			v = keyRef(ConfigMapRef, ef.ConfigMapRef)
+		case ef.SecretRef != nil:
+			v = keyRef(SecretRef, ef.SecretRef)
+		default:
+			continue
+		}
+		v.Prefix = ef.Prefix
+		ce.EnvFrom = append(ce.EnvFrom, v)
+	}
+	return ce
+}
+
+func keyRef(kind ValueFromKind, r *k8sKeyRef) ValueFrom {
+	return ValueFrom{Kind: kind, Name: r.Name, Key: r.Key, Optional: r.Optional}
+}
@@ -0,0 +1,137 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env_test
+
+import (
+	"strings"
+	"testing"
+
+	"acln.ro/env"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const kubectlPod = `{
+	"apiVersion": "v1",
+	"kind": "Pod",
+	"metadata": {"name": "web-7d4b9", "namespace": "prod"},
+	"spec": {
+		"initContainers": [{
+			"name": "migrate",
+			"env": [{"name": "MODE", "value": "migrate"}]
+		}],
+		"containers": [{
+			"name": "web",
+			"env": [
+				{"name": "PORT", "value": "8080"},
+				{"name": "EMPTY"},
+				{"name": "DB_PASSWORD", "valueFrom": {"secretKeyRef": {"name": "db-creds", "key": "password"}}},
+				{"name": "FEATURES", "valueFrom": {"configMapKeyRef": {"name": "flags", "key": "features", "optional": true}}},
+				{"name": "POD_IP", "valueFrom": {"fieldRef": {"apiVersion": "v1", "fieldPath": "status.podIP"}}},
+				{"name": "MEM_LIMIT", "valueFrom": {"resourceFieldRef": {"containerName": "web", "resource": "limits.memory"}}}
+			],
+			"envFrom": [
+				{"configMapRef": {"name": "app-config"}},
+				{"prefix": "S3_", "secretRef": {"name": "s3"}}
+			]
+		}, {
+			"name": "sidecar"
+		}]
+	}
+}`
+
+func TestFromKubectlPods(t *testing.T) {
+	want := env.PodEnv{
+		Namespace: "prod",
+		Name:      "web-7d4b9",
+		Containers: []env.ContainerEnv{
+			{
+				Name: "migrate",
+				Init: true,
+				Env:  env.Map{"MODE": "migrate"},
+			},
+			{
+				Name: "web",
+				Env:  env.Map{"PORT": "8080", "EMPTY": ""},
+				ValueFrom: map[string]env.ValueFrom{
+					"DB_PASSWORD": {Kind: env.SecretKeyRef, Name: "db-creds", Key: "password"},
+					"FEATURES":    {Kind: env.ConfigMapKeyRef, Name: "flags", Key: "features", Optional: true},
+					"POD_IP":      {Kind: env.FieldRef, FieldPath: "status.podIP"},
+					"MEM_LIMIT":   {Kind: env.ResourceFieldRef, Resource: "limits.memory"},
+				},
+				EnvFrom: []env.ValueFrom{
+					{Kind: env.ConfigMapRef, Name: "app-config"},
+					{Kind: env.SecretRef, Name: "s3", Prefix: "S3_"},
+				},
+			},
+			{
+				Name: "sidecar",
+				Env:  env.Map{},
+			},
+		},
+	}
+	list := `{"apiVersion": "v1", "kind": "List", "items": [` + kubectlPod + `,` + kubectlPod + `]}`
+	tests := []struct {
+		input string
+		want  []env.PodEnv
+	}{
+		{kubectlPod, []env.PodEnv{want}},
+		{list, []env.PodEnv{want, want}},
+		{`{"kind": "List", "items": []}`, []env.PodEnv{}},
+	}
+	for _, tt := range tests {
+		got, err := env.FromKubectlPods([]byte(tt.input))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(got, tt.want); diff != "" {
+			t.Errorf("FromKubectlPods: %s", diff)
+		}
+	}
+}
+
+func TestFromKubectlPodsErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{`{"kind": "Deployment"}`, `unexpected kind "Deployment"`},
+		{`{"kind": "List", "items": [{"kind": "Service"}]}`, "unexpected Service"},
+		{`{"kind": `, "unexpected end"},
+	}
+	for _, tt := range tests {
+		_, err := env.FromKubectlPods([]byte(tt.input))
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("FromKubectlPods(%q): got error %v, want one containing %q", tt.input, err, tt.want)
+		}
+	}
+}
+
+func TestValueFromString(t *testing.T) {
+	tests := []struct {
+		v    env.ValueFrom
+		want string
+	}{
+		{env.ValueFrom{Kind: env.SecretKeyRef, Name: "db", Key: "password"}, "secretKeyRef:db/password"},
+		{env.ValueFrom{Kind: env.FieldRef, FieldPath: "status.podIP"}, "fieldRef:status.podIP"},
+		{env.ValueFrom{Kind: env.ResourceFieldRef, Resource: "limits.cpu"}, "resourceFieldRef:limits.cpu"},
+		{env.ValueFrom{Kind: env.SecretRef, Name: "s3", Prefix: "S3_"}, "secretRef:s3"},
+	}
+	for _, tt := range tests {
+		if got := tt.v.String(); got != tt.want {
+			t.Errorf("got %q, want %q", got, tt.want)
+		}
+	}
+}
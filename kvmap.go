@@ -0,0 +1,72 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env
+
+import (
+	"fmt"
+	"strings"
+)
+
+// KVMap parses the value of the variable named by key as a comma-separated
+// list of key=value pairs, such as "service.name=api,team=infra", the
+// convention used for OpenTelemetry resource attributes, Prometheus labels
+// and Docker labels.
+//
+// Items are split as described by Map.List, so a comma may be escaped as
+// \, within a value. Each item is split at the first '=', and whitespace
+// surrounding keys and values is trimmed. Items without '=', empty keys
+// and duplicate keys are errors.
+func (m Map) KVMap(key string) (Map, error) {
+	v, ok := m[key]
+	if !ok {
+		return nil, notSet(key)
+	}
+	kv, err := parseKVMap(v)
+	if err != nil {
+		return nil, invalid(key, err)
+	}
+	return kv, nil
+}
+
+func parseKVMap(s string) (Map, error) {
+	items := splitList(s, Comma)
+	kv := make(Map, len(items))
+	for _, item := range items {
+		eq := strings.IndexByte(item, '=')
+		if eq < 0 {
+			return nil, fmt.Errorf("missing '=' in %q", item)
+		}
+		k := strings.TrimSpace(item[:eq])
+		if k == "" {
+			return nil, fmt.Errorf("empty key in %q", item)
+		}
+		if _, dup := kv[k]; dup {
+			return nil, fmt.Errorf("duplicate key %q", k)
+		}
+		kv[k] = strings.TrimSpace(item[eq+1:])
+	}
+	return kv, nil
+}
+
+// EncodeKVMap encodes kv as comma-separated key=value pairs, sorted by
+// key, in the format understood by Map.KVMap. Commas and backslashes in
+// keys and values are escaped. Keys must not contain '='.
+func EncodeKVMap(kv Map) string {
+	items := make([]string, 0, len(kv))
+	for _, k := range kv.keys() {
+		items = append(items, k+"="+kv[k])
+	}
+	return JoinList(items, Comma)
+}
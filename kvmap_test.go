@@ -0,0 +1,69 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env_test
+
+import (
+	"errors"
+	"testing"
+
+	"acln.ro/env"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestKVMap(t *testing.T) {
+	tests := []struct {
+		in   string
+		want env.Map
+		err  bool
+	}{
+		{in: "", want: env.Map{}},
+		{in: "service.name=api, team = infra", want: env.Map{"service.name": "api", "team": "infra"}},
+		{in: "query=a=b", want: env.Map{"query": "a=b"}},
+		{in: `hosts=a\,b,empty=`, want: env.Map{"hosts": "a,b", "empty": ""}},
+		{in: "novalue", err: true},
+		{in: "=v", err: true},
+		{in: "k=1,k=2", err: true},
+	}
+	for _, tt := range tests {
+		m := env.Map{"LABELS": tt.in}
+		got, err := m.KVMap("LABELS")
+		if (err != nil) != tt.err {
+			t.Errorf("KVMap(%q): got error %v, want error: %t", tt.in, err, tt.err)
+			continue
+		}
+		if diff := cmp.Diff(tt.want, got); diff != "" {
+			t.Errorf("KVMap(%q): (-want +got)\n%s", tt.in, diff)
+		}
+	}
+	if _, err := (env.Map{}).KVMap("LABELS"); !errors.Is(err, env.ErrNotFound) {
+		t.Errorf("KVMap(unset): got %v, want ErrNotFound", err)
+	}
+}
+
+func TestEncodeKVMap(t *testing.T) {
+	kv := env.Map{"team": "infra", "hosts": "a,b", "path": `C:\tmp`}
+	s := env.EncodeKVMap(kv)
+	if want := `hosts=a\,b,path=C:\\tmp,team=infra`; s != want {
+		t.Errorf("EncodeKVMap = %q, want %q", s, want)
+	}
+	got, err := env.Map{"LABELS": s}.KVMap("LABELS")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(kv, got); diff != "" {
+		t.Errorf("round trip: (-want +got)\n%s", diff)
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+)
+
+// LaunchdGetenv returns the value of the variable named by key in the
+// environment of the launchd user session, like "launchctl getenv". The
+// session environment is inherited by applications launchd starts, such
+// as GUI applications. launchctl does not distinguish unset variables from
+// empty ones: LaunchdGetenv returns the empty string for both.
+func LaunchdGetenv(ctx context.Context, key string) (string, error) {
+	out, err := launchctl(ctx, "getenv", key)
+	if err != nil {
+		return "", err
+	}
+	return trimNewline(string(out)), nil
+}
+
+// LaunchdSetenv sets the variables in m in the environment of the launchd
+// user session, like "launchctl setenv", in sorted order. Applications
+// launchd starts afterwards observe the variables; running applications
+// do not.
+func LaunchdSetenv(ctx context.Context, m Map) error {
+	for _, k := range m.keys() {
+		if _, err := launchctl(ctx, "setenv", k, m[k]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LaunchdUnsetenv unsets the specified variables in the environment of
+// the launchd user session, like "launchctl unsetenv".
+func LaunchdUnsetenv(ctx context.Context, keys ...string) error {
+	for _, k := range keys {
+		if _, err := launchctl(ctx, "unsetenv", k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// launchctl runs launchctl with the specified arguments, and returns its
+// standard output.
+func launchctl(ctx context.Context, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "launchctl", args...)
+	stderr := new(bytes.Buffer)
+	cmd.Stderr = stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if stderr.Len() > 0 {
+			return nil, fmt.Errorf("env: launchctl %s: %v: %s", args[0], err, bytes.TrimSpace(stderr.Bytes()))
+		}
+		return nil, fmt.Errorf("env: launchctl %s: %v", args[0], err)
+	}
+	return out, nil
+}
@@ -0,0 +1,58 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"testing"
+
+	"acln.ro/env"
+)
+
+func TestLaunchd(t *testing.T) {
+	if os.Getenv("ENV_TEST_LAUNCHD") == "" {
+		t.Skip("modifies the launchd user session; set ENV_TEST_LAUNCHD to run")
+	}
+	if _, err := exec.LookPath("launchctl"); err != nil {
+		t.Skip("launchctl not available")
+	}
+	ctx := context.Background()
+	key := fmt.Sprintf("ENV_TEST_LAUNCHD_%d", os.Getpid())
+	defer env.LaunchdUnsetenv(ctx, key)
+
+	if err := env.LaunchdSetenv(ctx, env.Map{key: "a b"}); err != nil {
+		t.Fatal(err)
+	}
+	got, err := env.LaunchdGetenv(ctx, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "a b" {
+		t.Errorf("got %q, want %q", got, "a b")
+	}
+	if err := env.LaunchdUnsetenv(ctx, key); err != nil {
+		t.Fatal(err)
+	}
+	got, err = env.LaunchdGetenv(ctx, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "" {
+		t.Errorf("after unset: got %q", got)
+	}
+}
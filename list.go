@@ -0,0 +1,95 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env
+
+import "strings"
+
+// A Separator separates the items of list-valued variables.
+type Separator rune
+
+// Common separators.
+const (
+	Comma     Separator = ','
+	Semicolon Separator = ';'
+	Colon     Separator = ':'
+	Pipe      Separator = '|'
+)
+
+// List splits the value of the variable named by key into items separated
+// by sep. Surrounding whitespace is trimmed from each item, and empty items
+// are dropped. Within an item, the separator may be escaped as \, and a
+// backslash as \\. Other backslashes, including a trailing one, are
+// preserved. Windows paths therefore need no escaping, unless they hold
+// two backslashes in a row, as UNC paths do, or end in a backslash which
+// is followed by the separator, as in `C:\Go\bin\,C:\tools`.
+func (m Map) List(key string, sep Separator) ([]string, error) {
+	v, ok := m[key]
+	if !ok {
+		return nil, notSet(key)
+	}
+	return splitList(v, sep), nil
+}
+
+// JoinList joins items using sep, escaping occurrences of sep and of
+// backslashes within items, such that Map.List recovers the items, as
+// long as they are non-empty and have no surrounding whitespace.
+func JoinList(items []string, sep Separator) string {
+	r := strings.NewReplacer(`\`, `\\`, string(sep), `\`+string(sep))
+	quoted := make([]string, len(items))
+	for i, item := range items {
+		quoted[i] = r.Replace(item)
+	}
+	return strings.Join(quoted, string(sep))
+}
+
+func splitList(s string, sep Separator) []string {
+	var (
+		items []string
+		raw   strings.Builder // current item, escapes intact
+	)
+	flush := func() {
+		if item := strings.TrimSpace(raw.String()); item != "" {
+			items = append(items, unescapeItem(item, sep))
+		}
+		raw.Reset()
+	}
+	rs := []rune(s)
+	for i := 0; i < len(rs); i++ {
+		switch r := rs[i]; {
+		case r == '\\' && i+1 < len(rs):
+			raw.WriteRune(r)
+			raw.WriteRune(rs[i+1])
+			i++
+		case r == rune(sep):
+			flush()
+		default:
+			raw.WriteRune(r)
+		}
+	}
+	flush()
+	return items
+}
+
+func unescapeItem(s string, sep Separator) string {
+	var sb strings.Builder
+	rs := []rune(s)
+	for i := 0; i < len(rs); i++ {
+		if rs[i] == '\\' && i+1 < len(rs) && (rs[i+1] == '\\' || rs[i+1] == rune(sep)) {
+			i++
+		}
+		sb.WriteRune(rs[i])
+	}
+	return sb.String()
+}
@@ -0,0 +1,76 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env_test
+
+import (
+	"errors"
+	"testing"
+
+	"acln.ro/env"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestList(t *testing.T) {
+	tests := []struct {
+		in   string
+		sep  env.Separator
+		want []string
+		err  bool
+	}{
+		{in: "", sep: env.Comma, want: nil},
+		{in: "a", sep: env.Comma, want: []string{"a"}},
+		{in: "a.example, b.example ,c.example", sep: env.Comma, want: []string{"a.example", "b.example", "c.example"}},
+		{in: "a,,b,", sep: env.Comma, want: []string{"a", "b"}},
+		{in: `x\,y,z`, sep: env.Comma, want: []string{"x,y", "z"}},
+		{in: `a\\,b`, sep: env.Comma, want: []string{`a\`, "b"}},
+		{in: `C:\Go\bin;C:\tools`, sep: env.Semicolon, want: []string{`C:\Go\bin`, `C:\tools`}},
+		{in: "a,b|c", sep: env.Pipe, want: []string{"a,b", "c"}},
+		{in: `a,b\`, sep: env.Comma, want: []string{"a", `b\`}},
+		{in: `C:\Go\bin;C:\tools\`, sep: env.Semicolon, want: []string{`C:\Go\bin`, `C:\tools\`}},
+		{in: `C:\Go\bin\,C:\tools`, sep: env.Comma, want: []string{`C:\Go\bin,C:\tools`}},
+		{in: `\\host\share`, sep: env.Semicolon, want: []string{`\host\share`}},
+		{in: `\\\\host\share`, sep: env.Semicolon, want: []string{`\\host\share`}},
+	}
+	for _, tt := range tests {
+		m := env.Map{"LIST": tt.in}
+		got, err := m.List("LIST", tt.sep)
+		if (err != nil) != tt.err {
+			t.Errorf("List(%q): got error %v, want error: %t", tt.in, err, tt.err)
+			continue
+		}
+		if diff := cmp.Diff(tt.want, got); diff != "" {
+			t.Errorf("List(%q): (-want +got)\n%s", tt.in, diff)
+		}
+	}
+	if _, err := (env.Map{}).List("LIST", env.Comma); !errors.Is(err, env.ErrNotFound) {
+		t.Errorf("List(unset): got %v, want ErrNotFound", err)
+	}
+}
+
+func TestJoinList(t *testing.T) {
+	items := []string{"a,b", `c\d`, "e"}
+	s := env.JoinList(items, env.Comma)
+	if want := `a\,b,c\\d,e`; s != want {
+		t.Errorf("JoinList = %q, want %q", s, want)
+	}
+	got, err := env.Map{"LIST": s}.List("LIST", env.Comma)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(items, got); diff != "" {
+		t.Errorf("round trip: (-want +got)\n%s", diff)
+	}
+}
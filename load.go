@@ -0,0 +1,215 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"sort"
+)
+
+// A Source provides environment variables.
+type Source interface {
+	// Environ returns the variables provided by the Source. The caller
+	// may modify the returned Map.
+	Environ(ctx context.Context) (Map, error)
+}
+
+// Environ implements Source for Map. It returns a copy of m.
+func (m Map) Environ(ctx context.Context) (Map, error) {
+	return Merge(m), nil
+}
+
+// Process is a Source for the environment of the current process.
+var Process Source = process{}
+
+type process struct{}
+
+func (process) Environ(ctx context.Context) (Map, error) {
+	return Variables(), nil
+}
+
+// A Chain is a sequence of sources, the variables of which are merged
+// when the Chain is loaded. In case of key collisions, values from
+// sources which appear later in the Chain take precedence.
+type Chain []Source
+
+// Environ loads c without any options.
+func (c Chain) Environ(ctx context.Context) (Map, error) {
+	return c.Load(ctx)
+}
+
+// Load loads the variables from the sources in c, and merges them,
+// subject to the specified options.
+func (c Chain) Load(ctx context.Context, opts ...LoadOption) (Map, error) {
+	var cfg loadConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	maps := make([]Map, 0, len(c))
+	for _, src := range c {
+		m, err := src.Environ(ctx)
+		if err != nil {
+			return nil, err
+		}
+		maps = append(maps, m)
+	}
+	m := Merge(maps...)
+	if cfg.aliases != nil {
+		cfg.aliases.apply(m, cfg.warn)
+	}
+	errs := resolveFiles(m, cfg.fileKeys)
+	for _, key := range cfg.required {
+		if _, ok := m[key]; ok {
+			continue
+		}
+		if cfg.credential == nil {
+			errs = append(errs, notSet(key))
+			continue
+		}
+		v, err := cfg.credential(ctx, key)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("env: fetching credential for %s: %v", key, err))
+			continue
+		}
+		if v == "" {
+			errs = append(errs, &ValidationError{
+				Key:    key,
+				Reason: "required variable is not set, and no credential is available",
+				Err:    ErrNotFound,
+			})
+			continue
+		}
+		m[key] = v
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return m, nil
+}
+
+// A LoadOption configures the loading of a Chain.
+type LoadOption func(*loadConfig)
+
+type loadConfig struct {
+	required   []string
+	credential CredentialFunc
+	aliases    Aliases
+	warn       func(Deprecation)
+	fileKeys   []string
+
+	environment     Environment
+	noLocalInCI     bool
+	profileKey      string
+	allowedProfiles []string
+}
+
+// Require marks the specified keys as required. Loading fails if a
+// required variable is not set by any source, and it cannot be obtained
+// from a CredentialFunc. All missing variables are reported together, in
+// an ErrorList.
+func Require(keys ...string) LoadOption {
+	return func(cfg *loadConfig) {
+		cfg.required = append(cfg.required, keys...)
+	}
+}
+
+// A CredentialFunc fetches the value of the variable named by key from an
+// external credential store. If no value is available, but no error
+// occurred, a CredentialFunc returns an empty string.
+type CredentialFunc func(ctx context.Context, key string) (string, error)
+
+// WithCredentials configures f to supply the values of required
+// variables which are not set by any source.
+func WithCredentials(f CredentialFunc) LoadOption {
+	return func(cfg *loadConfig) {
+		cfg.credential = f
+	}
+}
+
+// CommandCredentials returns a CredentialFunc which runs an external
+// helper program, in the style of git credential helpers. The program
+// is invoked with the specified arguments, followed by the key, and must
+// print the value to standard output. A single trailing newline is
+// removed from the output.
+func CommandCredentials(name string, args ...string) CredentialFunc {
+	return func(ctx context.Context, key string) (string, error) {
+		argv := append(append([]string(nil), args...), key)
+		out, err := exec.CommandContext(ctx, name, argv...).Output()
+		if err != nil {
+			return "", err
+		}
+		return trimNewline(string(out)), nil
+	}
+}
+
+// Aliases maps variable names to lists of deprecated names for the same
+// variable, in order of precedence.
+type Aliases map[string][]string
+
+// A Deprecation describes the use of a deprecated variable name.
+type Deprecation struct {
+	Old string // the deprecated name
+	New string // the name which replaces it
+
+	// Ignored is true if the variable was also set under its new name,
+	// in which case the value set under the deprecated name is ignored.
+	Ignored bool
+}
+
+func (d Deprecation) String() string {
+	if d.Ignored {
+		return fmt.Sprintf("%s is deprecated and ignored, since %s is set", d.Old, d.New)
+	}
+	return fmt.Sprintf("%s is deprecated, use %s instead", d.Old, d.New)
+}
+
+// WithAliases configures the renaming of deprecated variables according
+// to a. If a variable is set under a deprecated name, but not under its
+// new name, the value is used for the new name. Deprecated names are
+// removed from the loaded Map. If warn is not nil, it is called for each
+// deprecated name in use.
+func WithAliases(a Aliases, warn func(Deprecation)) LoadOption {
+	return func(cfg *loadConfig) {
+		cfg.aliases = a
+		cfg.warn = warn
+	}
+}
+
+func (a Aliases) apply(m Map, warn func(Deprecation)) {
+	news := make([]string, 0, len(a))
+	for k := range a {
+		news = append(news, k)
+	}
+	sort.Strings(news)
+	for _, newKey := range news {
+		_, set := m[newKey]
+		for _, old := range a[newKey] {
+			v, ok := m[old]
+			if !ok {
+				continue
+			}
+			if warn != nil {
+				warn(Deprecation{Old: old, New: newKey, Ignored: set})
+			}
+			if !set {
+				m[newKey] = v
+				set = true
+			}
+			delete(m, old)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"acln.ro/env"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestChainLoad(t *testing.T) {
+	c := env.Chain{
+		env.Map{"FOO": "x", "BAR": "y"},
+		env.Map{"BAR": "z"},
+	}
+	got, err := c.Load(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := env.Map{"FOO": "x", "BAR": "z"}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("Load: got %v, want %v: %s", got, want, diff)
+	}
+}
+
+func TestChainLoadProcess(t *testing.T) {
+	c := env.Chain{env.Process, env.Map{"ENV_TEST_OVERRIDE": "x"}}
+	got, err := c.Load(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := env.Merge(env.Variables(), env.Map{"ENV_TEST_OVERRIDE": "x"})
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("Load: got %v, want %v: %s", got, want, diff)
+	}
+}
+
+func TestChainLoadRequired(t *testing.T) {
+	c := env.Chain{env.Map{"FOO": "x"}}
+	if _, err := c.Load(context.Background(), env.Require("FOO", "TOKEN")); err == nil {
+		t.Error("Load succeeded with missing required variable")
+	}
+
+	var asked []string
+	creds := func(ctx context.Context, key string) (string, error) {
+		asked = append(asked, key)
+		switch key {
+		case "TOKEN":
+			return "t0ken", nil
+		case "BROKEN":
+			return "", errors.New("helper failed")
+		}
+		return "", nil
+	}
+	got, err := c.Load(context.Background(), env.Require("FOO", "TOKEN"), env.WithCredentials(creds))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := env.Map{"FOO": "x", "TOKEN": "t0ken"}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("Load: got %v, want %v: %s", got, want, diff)
+	}
+	if diff := cmp.Diff(asked, []string{"TOKEN"}); diff != "" {
+		t.Errorf("credentials requested for %q: %s", asked, diff)
+	}
+	for _, key := range []string{"BROKEN", "UNKNOWN"} {
+		if _, err := c.Load(context.Background(), env.Require(key), env.WithCredentials(creds)); err == nil {
+			t.Errorf("Load succeeded for %s", key)
+		}
+	}
+}
+
+func TestCommandCredentials(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test uses a shell script")
+	}
+	dir, err := ioutil.TempDir("", "env-creds")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	helper := filepath.Join(dir, "helper")
+	script := "#!/bin/sh\necho \"$1-$2\"\n"
+	if err := ioutil.WriteFile(helper, []byte(script), 0700); err != nil {
+		t.Fatal(err)
+	}
+	creds := env.CommandCredentials(helper, "get")
+	got, err := creds(context.Background(), "TOKEN")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "get-TOKEN"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestChainLoadAliases(t *testing.T) {
+	c := env.Chain{env.Map{
+		"DB_HOST":      "db",
+		"DATABASE_URL": "postgres://db",
+		"DB_URI":       "postgres://old",
+		"LISTEN":       ":80",
+		"ADDR":         ":8080",
+	}}
+	aliases := env.Aliases{
+		"DATABASE_HOST": {"DB_HOST"},
+		"DATABASE_URL":  {"DB_URL", "DB_URI"},
+		"LISTEN_ADDR":   {"LISTEN", "ADDR"},
+	}
+	var warnings []env.Deprecation
+	warn := func(d env.Deprecation) {
+		warnings = append(warnings, d)
+	}
+	got, err := c.Load(context.Background(), env.WithAliases(aliases, warn), env.Require("DATABASE_HOST"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := env.Map{
+		"DATABASE_HOST": "db",
+		"DATABASE_URL":  "postgres://db",
+		"LISTEN_ADDR":   ":80",
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("Load: got %v, want %v: %s", got, want, diff)
+	}
+	wantWarnings := []env.Deprecation{
+		{Old: "DB_HOST", New: "DATABASE_HOST"},
+		{Old: "DB_URI", New: "DATABASE_URL", Ignored: true},
+		{Old: "LISTEN", New: "LISTEN_ADDR"},
+		{Old: "ADDR", New: "LISTEN_ADDR", Ignored: true},
+	}
+	if diff := cmp.Diff(warnings, wantWarnings); diff != "" {
+		t.Errorf("warnings: got %v, want %v: %s", warnings, wantWarnings, diff)
+	}
+}
+
+func TestDeprecationString(t *testing.T) {
+	tests := []struct {
+		d    env.Deprecation
+		want string
+	}{
+		{
+			d:    env.Deprecation{Old: "DB_HOST", New: "DATABASE_HOST"},
+			want: "DB_HOST is deprecated, use DATABASE_HOST instead",
+		},
+		{
+			d:    env.Deprecation{Old: "DB_HOST", New: "DATABASE_HOST", Ignored: true},
+			want: "DB_HOST is deprecated and ignored, since DATABASE_HOST is set",
+		},
+	}
+	for _, tt := range tests {
+		if got := tt.d.String(); got != tt.want {
+			t.Errorf("%#v.String() = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env
+
+import "os"
+
+// EditFileLocked is like EditFile, but holds an exclusive advisory lock
+// for the duration of the read-modify-write cycle, so that concurrent
+// edits by cooperating processes do not clobber each other. It blocks
+// until the lock is acquired.
+//
+// Since EditFile replaces the file rather than modifying it in place,
+// the lock is taken on a separate lock file, named by appending ".lock"
+// to path. The lock file is created if necessary, and left in place
+// afterwards. The lock uses flock(2) on Unix systems, and LockFileEx on
+// Windows. On other systems, EditFileLocked returns an error.
+func EditFileLocked(path string, edit func(*Document) error, opts ...DotenvOption) error {
+	f, err := os.OpenFile(path+".lock", os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close() // releases the lock
+	if err := lockFile(f); err != nil {
+		return err
+	}
+	return EditFile(path, edit, opts...)
+}
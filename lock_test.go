@@ -0,0 +1,64 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env_test
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+
+	"acln.ro/env"
+)
+
+func TestEditFileLocked(t *testing.T) {
+	dir := writeFiles(t, map[string]string{".env": "# counter\nCOUNTER=0\n"})
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, ".env")
+
+	const n = 20
+	var wg sync.WaitGroup
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- env.EditFileLocked(path, func(doc *env.Document) error {
+				v, _ := doc.Lookup("COUNTER")
+				c, err := strconv.Atoi(v)
+				if err != nil {
+					return err
+				}
+				doc.Set("COUNTER", strconv.Itoa(c+1))
+				return nil
+			})
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	m, err := env.ReadDotenvFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := m["COUNTER"], strconv.Itoa(n); got != want {
+		t.Errorf("COUNTER = %s, want %s: concurrent edits were lost", got, want)
+	}
+}
@@ -0,0 +1,212 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// A ManifestOption configures the generation of Kubernetes manifests.
+type ManifestOption func(*manifestConfig)
+
+type manifestConfig struct {
+	stringData  bool
+	namespace   string
+	annotations map[string]string
+	secretName  string
+}
+
+// StringData causes KubernetesSecret to store values in the stringData
+// field of the Secret, as plain text, rather than base64 encoded in the
+// data field. This is more readable, but only suitable for values which
+// are valid UTF-8: other values are still stored in the data field.
+func StringData() ManifestOption {
+	return func(cfg *manifestConfig) {
+		cfg.stringData = true
+	}
+}
+
+// InNamespace sets the namespace of generated objects.
+func InNamespace(ns string) ManifestOption {
+	return func(cfg *manifestConfig) {
+		cfg.namespace = ns
+	}
+}
+
+// Annotations sets the annotations of generated objects.
+func Annotations(a map[string]string) ManifestOption {
+	return func(cfg *manifestConfig) {
+		cfg.annotations = a
+	}
+}
+
+// SecretKeyRefs causes KubernetesEnv to generate entries which refer to
+// the keys of the named Secret, such as one generated by KubernetesSecret,
+// rather than entries with literal values.
+func SecretKeyRefs(secretName string) ManifestOption {
+	return func(cfg *manifestConfig) {
+		cfg.secretName = secretName
+	}
+}
+
+type k8sMetadata struct {
+	Name        string            `json:"name"`
+	Namespace   string            `json:"namespace,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+type k8sSecret struct {
+	APIVersion string            `json:"apiVersion"`
+	Kind       string            `json:"kind"`
+	Metadata   k8sMetadata       `json:"metadata"`
+	Type       string            `json:"type"`
+	Data       map[string]string `json:"data,omitempty"`
+	StringData map[string]string `json:"stringData,omitempty"`
+}
+
+type k8sEnvVar struct {
+	Name      string `json:"name"`
+	Value     string `json:"value,omitempty"`
+	ValueFrom *struct {
+		SecretKeyRef k8sKeyRef `json:"secretKeyRef"`
+	} `json:"valueFrom,omitempty"`
+}
+
+// KubernetesSecret returns a manifest for an Opaque Kubernetes Secret
+// with the specified name, holding the variables in m, with values base64
+// encoded. Manifests are JSON, which kubectl accepts wherever it accepts
+// YAML. Kubernetes restricts the keys of Secrets to letters, digits, '-',
+// '_' and '.'; invalid keys are reported together, as an ErrorList.
+func (m Map) KubernetesSecret(name string, opts ...ManifestOption) ([]byte, error) {
+	var cfg manifestConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	var errs ErrorList
+	for _, k := range m.keys() {
+		if !isSecretKey(k) {
+			errs = append(errs, fmt.Errorf("env: invalid Secret key %q", k))
+		}
+	}
+	if err := errs.err(); err != nil {
+		return nil, err
+	}
+	s := k8sSecret{
+		APIVersion: "v1",
+		Kind:       "Secret",
+		Metadata: k8sMetadata{
+			Name:        name,
+			Namespace:   cfg.namespace,
+			Annotations: cfg.annotations,
+		},
+		Type: "Opaque",
+	}
+	for k, v := range m {
+		if cfg.stringData && utf8.ValidString(v) {
+			if s.StringData == nil {
+				s.StringData = make(map[string]string)
+			}
+			s.StringData[k] = v
+			continue
+		}
+		if s.Data == nil {
+			s.Data = make(map[string]string)
+		}
+		s.Data[k] = base64.StdEncoding.EncodeToString([]byte(v))
+	}
+	return marshalManifest(s)
+}
+
+// KubernetesEnv returns the env field of a container specification, which
+// sets the variables in m, sorted by key. If the SecretKeyRefs option is
+// specified, the entries refer to the keys of the Secret, rather than
+// holding the values.
+//
+// Kubernetes expands $(VAR) references in values, and reduces $$ to $.
+// KubernetesEnv escapes values such that the container observes them
+// exactly as they are in m.
+func (m Map) KubernetesEnv(opts ...ManifestOption) ([]byte, error) {
+	var cfg manifestConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	env := make([]k8sEnvVar, 0, len(m))
+	for _, k := range m.keys() {
+		e := k8sEnvVar{Name: k}
+		if cfg.secretName != "" {
+			e.ValueFrom = &struct {
+				SecretKeyRef k8sKeyRef `json:"secretKeyRef"`
+			}{k8sKeyRef{Name: cfg.secretName, Key: k}}
+		} else {
+			e.Value = escapeK8sRefs(m[k])
+		}
+		env = append(env, e)
+	}
+	return marshalManifest(env)
+}
+
+// KubernetesEnvFrom returns the envFrom field of a container
+// specification, which imports all the keys of the named Secret as
+// variables.
+func KubernetesEnvFrom(secretName string) ([]byte, error) {
+	return marshalManifest([]map[string]k8sKeyRef{
+		{"secretRef": {Name: secretName}},
+	})
+}
+
+// escapeK8sRefs escapes s such that Kubernetes does not expand $(VAR)
+// references in it, by doubling each '$' which is followed by '(' or by
+// another '$'.
+func escapeK8sRefs(s string) string {
+	if !strings.Contains(s, "$") {
+		return s
+	}
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		sb.WriteByte(s[i])
+		if s[i] == '$' && i+1 < len(s) && (s[i+1] == '(' || s[i+1] == '$') {
+			sb.WriteByte('$')
+		}
+	}
+	return sb.String()
+}
+
+func marshalManifest(v interface{}) ([]byte, error) {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(b, '\n'), nil
+}
+
+// isSecretKey reports whether s is a valid key for a Kubernetes Secret
+// or ConfigMap: letters, digits, '-', '_' and '.', but not "." or "..",
+// or a name starting with "..".
+func isSecretKey(s string) bool {
+	if s == "" || len(s) > 253 || s == "." || strings.HasPrefix(s, "..") {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '-' || c == '_' || c == '.') {
+			return false
+		}
+	}
+	return true
+}
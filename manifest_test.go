@@ -0,0 +1,212 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env_test
+
+import (
+	"errors"
+	"testing"
+
+	"acln.ro/env"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestKubernetesSecret(t *testing.T) {
+	m := env.Map{"DB_PASSWORD": "hunter2", "API_KEY": "k3y"}
+	tests := []struct {
+		name string
+		opts []env.ManifestOption
+		want string
+	}{
+		{
+			name: "data",
+			want: `{
+  "apiVersion": "v1",
+  "kind": "Secret",
+  "metadata": {
+    "name": "app"
+  },
+  "type": "Opaque",
+  "data": {
+    "API_KEY": "azN5",
+    "DB_PASSWORD": "aHVudGVyMg=="
+  }
+}
+`,
+		},
+		{
+			name: "stringData",
+			opts: []env.ManifestOption{
+				env.StringData(),
+				env.InNamespace("prod"),
+				env.Annotations(map[string]string{"owner": "platform"}),
+			},
+			want: `{
+  "apiVersion": "v1",
+  "kind": "Secret",
+  "metadata": {
+    "name": "app",
+    "namespace": "prod",
+    "annotations": {
+      "owner": "platform"
+    }
+  },
+  "type": "Opaque",
+  "stringData": {
+    "API_KEY": "k3y",
+    "DB_PASSWORD": "hunter2"
+  }
+}
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := m.KubernetesSecret("app", tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(string(got), tt.want); diff != "" {
+				t.Errorf("KubernetesSecret: %s", diff)
+			}
+		})
+	}
+}
+
+func TestKubernetesSecretStringDataBinary(t *testing.T) {
+	m := env.Map{"NAME": "app", "BLOB": "\xff\xfe"}
+	got, err := m.KubernetesSecret("app", env.StringData())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{
+  "apiVersion": "v1",
+  "kind": "Secret",
+  "metadata": {
+    "name": "app"
+  },
+  "type": "Opaque",
+  "data": {
+    "BLOB": "//4="
+  },
+  "stringData": {
+    "NAME": "app"
+  }
+}
+`
+	if diff := cmp.Diff(string(got), want); diff != "" {
+		t.Errorf("KubernetesSecret: %s", diff)
+	}
+}
+
+func TestKubernetesSecretInvalidKeys(t *testing.T) {
+	m := env.Map{"OK": "1", "a b": "2", "..x": "3", "tls.crt": "4"}
+	_, err := m.KubernetesSecret("app")
+	var errs env.ErrorList
+	if !errors.As(err, &errs) || len(errs) != 2 {
+		t.Fatalf("got %v, want 2 errors", err)
+	}
+}
+
+func TestKubernetesEnv(t *testing.T) {
+	m := env.Map{"PORT": "8080", "DB_PASSWORD": "hunter2"}
+	got, err := m.KubernetesEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `[
+  {
+    "name": "DB_PASSWORD",
+    "value": "hunter2"
+  },
+  {
+    "name": "PORT",
+    "value": "8080"
+  }
+]
+`
+	if diff := cmp.Diff(string(got), want); diff != "" {
+		t.Errorf("KubernetesEnv: %s", diff)
+	}
+
+	got, err = env.Map{"CMD": "echo $(HOME) $$ $5"}.KubernetesEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = `[
+  {
+    "name": "CMD",
+    "value": "echo $$(HOME) $$$ $5"
+  }
+]
+`
+	if diff := cmp.Diff(string(got), want); diff != "" {
+		t.Errorf("KubernetesEnv with references: %s", diff)
+	}
+
+	got, err = env.Map{"DB_PASSWORD": "hunter2"}.KubernetesEnv(env.SecretKeyRefs("app"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = `[
+  {
+    "name": "DB_PASSWORD",
+    "valueFrom": {
+      "secretKeyRef": {
+        "name": "app",
+        "key": "DB_PASSWORD"
+      }
+    }
+  }
+]
+`
+	if diff := cmp.Diff(string(got), want); diff != "" {
+		t.Errorf("KubernetesEnv with SecretKeyRefs: %s", diff)
+	}
+}
+
+func TestKubernetesEnvFrom(t *testing.T) {
+	got, err := env.KubernetesEnvFrom("app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `[
+  {
+    "secretRef": {
+      "name": "app"
+    }
+  }
+]
+`
+	if diff := cmp.Diff(string(got), want); diff != "" {
+		t.Errorf("KubernetesEnvFrom: %s", diff)
+	}
+}
+
+func TestKubernetesRoundTrip(t *testing.T) {
+	m := env.Map{"PORT": "8080", "EMPTY": ""}
+	fragment, err := m.KubernetesEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pod := `{"kind": "Pod", "spec": {"containers": [{"name": "c", "env": ` + string(fragment) + `}]}}`
+	pods, err := env.FromKubectlPods([]byte(pod))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(pods[0].Containers[0].Env, m); diff != "" {
+		t.Errorf("round trip: %s", diff)
+	}
+}
@@ -0,0 +1,177 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+// hasRange reports whether v has range constraints.
+func (v *Var) hasRange() bool {
+	return v.Min != "" || v.Max != "" || v.Step != ""
+}
+
+// parseRange parses the range constraints of v, and returns a function
+// which checks that a valid value of the type of v is within the range.
+// The function does not refer to v, so later changes to v do not affect
+// it.
+func (v *Var) parseRange() (func(s string) error, error) {
+	switch v.Type {
+	case Int:
+		return parseIntRange(v, func(s string) (int64, error) {
+			return strconv.ParseInt(s, 10, 64)
+		}, func(n int64) string {
+			return strconv.FormatInt(n, 10)
+		})
+	case Duration:
+		return parseIntRange(v, func(s string) (int64, error) {
+			d, err := time.ParseDuration(s)
+			return int64(d), err
+		}, func(n int64) string {
+			return time.Duration(n).String()
+		})
+	case Float:
+		return parseFloatRange(v)
+	default:
+		return nil, errors.New("min, max and step require an int, float or duration type")
+	}
+}
+
+// parseIntRange parses the range constraints of v, the values of which
+// are parsed by parse and formatted by format.
+func parseIntRange(v *Var, parse func(string) (int64, error), format func(int64) string) (func(string) error, error) {
+	minS, maxS, stepS, secret := v.Min, v.Max, v.Step, v.Secret
+	min, max, step := int64(math.MinInt64), int64(math.MaxInt64), int64(0)
+	for _, b := range []struct {
+		name, s string
+		n       *int64
+	}{
+		{"min", v.Min, &min},
+		{"max", v.Max, &max},
+		{"step", v.Step, &step},
+	} {
+		if b.s == "" {
+			continue
+		}
+		n, err := parse(b.s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %q for type %s", b.name, b.s, v.Type)
+		}
+		*b.n = n
+	}
+	if min > max {
+		return nil, fmt.Errorf("min %s is greater than max %s", v.Min, v.Max)
+	}
+	if step < 0 || v.Step != "" && step == 0 {
+		return nil, fmt.Errorf("step %s is not positive", v.Step)
+	}
+	return func(s string) error {
+		n, err := parse(s)
+		if err != nil {
+			return err
+		}
+		switch {
+		case n < min:
+			return fmt.Errorf("%s is less than the minimum of %s", describeValue(s, secret), minS)
+		case n > max:
+			return fmt.Errorf("%s is greater than the maximum of %s", describeValue(s, secret), maxS)
+		}
+		if step == 0 {
+			return nil
+		}
+		base := int64(0)
+		if minS != "" {
+			base = min
+		}
+		// The difference may overflow int64, but not uint64.
+		diff := uint64(n) - uint64(base)
+		if n < base {
+			diff = uint64(base) - uint64(n)
+		}
+		if diff%uint64(step) != 0 {
+			return fmt.Errorf("%s is not a multiple of %s from %s", describeValue(s, secret), stepS, format(base))
+		}
+		return nil
+	}, nil
+}
+
+// parseFloatRange parses the range constraints of v, of type Float.
+func parseFloatRange(v *Var) (func(string) error, error) {
+	minS, maxS, stepS, secret := v.Min, v.Max, v.Step, v.Secret
+	min, max, step := math.Inf(-1), math.Inf(1), 0.0
+	for _, b := range []struct {
+		name, s string
+		f       *float64
+	}{
+		{"min", v.Min, &min},
+		{"max", v.Max, &max},
+		{"step", v.Step, &step},
+	} {
+		if b.s == "" {
+			continue
+		}
+		f, err := strconv.ParseFloat(b.s, 64)
+		if err != nil || math.IsNaN(f) {
+			return nil, fmt.Errorf("invalid %s %q for type %s", b.name, b.s, v.Type)
+		}
+		*b.f = f
+	}
+	if min > max {
+		return nil, fmt.Errorf("min %s is greater than max %s", v.Min, v.Max)
+	}
+	if step < 0 || v.Step != "" && (step == 0 || math.IsInf(step, 0)) {
+		return nil, fmt.Errorf("step %s is not positive", v.Step)
+	}
+	return func(s string) error {
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return err
+		}
+		switch {
+		case math.IsNaN(f):
+			return fmt.Errorf("%s is not a number", describeValue(s, secret))
+		case f < min:
+			return fmt.Errorf("%s is less than the minimum of %s", describeValue(s, secret), minS)
+		case f > max:
+			return fmt.Errorf("%s is greater than the maximum of %s", describeValue(s, secret), maxS)
+		}
+		if step == 0 {
+			return nil
+		}
+		base := 0.0
+		if minS != "" {
+			base = min
+		}
+		// Allow for rounding errors in decimal steps, such as 0.1.
+		q := (f - base) / step
+		if math.Abs(q-math.Round(q)) > 1e-9 {
+			return fmt.Errorf("%s is not a multiple of %s from %s", describeValue(s, secret), stepS, strconv.FormatFloat(base, 'g', -1, 64))
+		}
+		return nil
+	}, nil
+}
+
+// describeValue describes the value s in error messages, unless it is
+// secret.
+func describeValue(s string, secret bool) string {
+	if secret {
+		return "value"
+	}
+	return "value " + s
+}
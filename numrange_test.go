@@ -0,0 +1,117 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env_test
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"acln.ro/env"
+)
+
+func TestRange(t *testing.T) {
+	port := env.Var{Key: "V", Type: env.Int, Min: "1", Max: "65535"}
+	timeout := env.Var{Key: "V", Type: env.Duration, Max: "5m"}
+	ratio := env.Var{Key: "V", Type: env.Float, Min: "0", Max: "1", Step: "0.1"}
+	even := env.Var{Key: "V", Type: env.Int, Step: "2"}
+	workers := env.Var{Key: "V", Type: env.Int, Min: "3", Step: "4"}
+	wide := env.Var{Key: "V", Type: env.Int, Min: "-9000000000000000000", Step: "3"}
+	tests := []struct {
+		v      env.Var
+		value  string
+		reason string
+	}{
+		{v: port, value: "8080"},
+		{v: port, value: "0x1F90", reason: "value is not a valid int"},
+		{v: port, value: "010"},
+		{v: port, value: "1"},
+		{v: port, value: "65535"},
+		{v: port, value: "0", reason: "value 0 is less than the minimum of 1"},
+		{v: port, value: "70000", reason: "value 70000 is greater than the maximum of 65535"},
+		{v: port, value: "http", reason: "value is not a valid int"},
+		{v: timeout, value: "30s"},
+		{v: timeout, value: "5m"},
+		{v: timeout, value: "5m1s", reason: "value 5m1s is greater than the maximum of 5m"},
+		{v: ratio, value: "0.3"},
+		{v: ratio, value: "1"},
+		{v: ratio, value: "0.35", reason: "value 0.35 is not a multiple of 0.1 from 0"},
+		{v: ratio, value: "-0.1", reason: "value -0.1 is less than the minimum of 0"},
+		{v: ratio, value: "NaN", reason: "value NaN is not a number"},
+		{v: even, value: "-4"},
+		{v: even, value: "-3", reason: "value -3 is not a multiple of 2 from 0"},
+		{v: workers, value: "11"},
+		{v: workers, value: "12", reason: "value 12 is not a multiple of 4 from 3"},
+		{v: wide, value: "9000000000000000000"},
+		{v: wide, value: "8999999999999999999", reason: "is not a multiple of 3"},
+	}
+	for _, tt := range tests {
+		v := tt.v
+		schema := &env.Schema{Vars: []env.Var{v}}
+		err := schema.Validate(env.Map{"V": tt.value})
+		if tt.reason == "" {
+			if err != nil {
+				t.Errorf("%+v, %q: %v", tt.v, tt.value, err)
+			}
+			continue
+		}
+		var verr *env.ValidationError
+		if !errors.As(err, &verr) || !strings.Contains(verr.Reason, tt.reason) {
+			t.Errorf("%+v, %q: got %v, want reason %q", tt.v, tt.value, err, tt.reason)
+		}
+	}
+}
+
+func TestInvalidRange(t *testing.T) {
+	tests := []string{
+		`{"key": "PORT", "type": "int", "min": "one"}`,
+		`{"key": "PORT", "type": "int", "min": "10", "max": "1"}`,
+		`{"key": "PORT", "type": "int", "step": "0"}`,
+		`{"key": "RATIO", "type": "float", "step": "-0.5"}`,
+		`{"key": "TIMEOUT", "type": "duration", "max": "300"}`,
+		`{"key": "NAME", "min": "1"}`,
+	}
+	for _, data := range tests {
+		var v env.Var
+		if err := json.Unmarshal([]byte(data), &v); err == nil {
+			t.Errorf("Unmarshal(%s) succeeded", data)
+		}
+	}
+}
+
+func TestDecodeRange(t *testing.T) {
+	var cfg struct {
+		Port    uint16        `env:"PORT" default:"8080" validate:"min=1 max=65535"`
+		Timeout time.Duration `env:"TIMEOUT" default:"30s" validate:"max=5m"`
+		Ratio   float64       `env:"RATIO" default:"0.5" validate:"min=0 max=1"`
+	}
+	if err := env.Decode(env.Map{}, &cfg); err != nil {
+		t.Fatal(err)
+	}
+	err := env.Decode(env.Map{"PORT": "0", "TIMEOUT": "1h", "RATIO": "2"}, &cfg)
+	var errs env.ErrorList
+	if !errors.As(err, &errs) || len(errs) != 3 {
+		t.Errorf("got %v, want 3 errors", err)
+	}
+
+	var bad struct {
+		Name string `env:"NAME" validate:"min=1"`
+	}
+	if err := env.Decode(env.Map{}, &bad); err == nil || !strings.Contains(err.Error(), "field Name") {
+		t.Errorf("Decode with range on string field: got %v", err)
+	}
+}
@@ -0,0 +1,142 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env
+
+import (
+	"sort"
+	"strings"
+)
+
+// An EncodeOption configures the encoding of a Map.
+type EncodeOption func(*encodeConfig)
+
+type encodeConfig struct {
+	less func(a, b string) bool
+}
+
+// SortFunc specifies that keys are to be ordered by less, rather than
+// lexicographically. less must be a strict weak ordering. Keys which are
+// not ordered with respect to one another are ordered lexicographically.
+func SortFunc(less func(a, b string) bool) EncodeOption {
+	return func(cfg *encodeConfig) {
+		cfg.less = less
+	}
+}
+
+// Priority specifies that the variables named by keys are to be emitted
+// first, in the order in which they are specified, followed by the other
+// variables, sorted lexicographically by key.
+func Priority(keys ...string) EncodeOption {
+	rank := make(map[string]int, len(keys))
+	for i, k := range keys {
+		if _, ok := rank[k]; !ok {
+			rank[k] = i
+		}
+	}
+	return SortFunc(func(a, b string) bool {
+		ra, aok := rank[a]
+		rb, bok := rank[b]
+		switch {
+		case aok && bok:
+			return ra < rb
+		case aok != bok:
+			return aok
+		default:
+			return false
+		}
+	})
+}
+
+// orderedKeys returns the keys in m, in the order configured by opts.
+func (m Map) orderedKeys(opts []EncodeOption) []string {
+	keys := m.keys()
+	var cfg encodeConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.less != nil {
+		sort.SliceStable(keys, func(i, j int) bool {
+			return cfg.less(keys[i], keys[j])
+		})
+	}
+	return keys
+}
+
+// Sprint is like String, but configurable by opts.
+func (m Map) Sprint(opts ...EncodeOption) string {
+	sb := new(strings.Builder)
+	sb.Grow(m.encodedSize())
+	m.print(sb, ' ', m.orderedKeys(opts))
+	return sb.String()
+}
+
+// Ordered is a Map which remembers the order and exact encoding of the
+// "key=value" pairs it was parsed from, such that it can be encoded
+// without reordering the environment.
+type Ordered struct {
+	Map
+
+	raw  []string
+	orig Map
+}
+
+// ParseOrdered parses a list of environment variables in "key=value"
+// format, like Parse, but remembers the original list.
+func ParseOrdered(kvs ...string) *Ordered {
+	return &Ordered{
+		Map:  Parse(kvs...),
+		raw:  append([]string(nil), kvs...),
+		orig: Parse(kvs...),
+	}
+}
+
+// EncodeOriginalOrder encodes o as a slice of "key=value" pairs, in the
+// order in which they were originally parsed. Pairs for variables which
+// were added to o.Map after parsing follow, sorted lexicographically by
+// key.
+//
+// If o.Map was not modified after parsing, EncodeOriginalOrder returns
+// the original list exactly, including duplicate keys and entries not in
+// "key=value" format. Otherwise, variables whose values have not changed
+// are emitted as they originally were, variables whose values have
+// changed are emitted once, in place of their first occurrence, and
+// variables which were deleted are omitted.
+func (o *Ordered) EncodeOriginalOrder() []string {
+	kvs := make([]string, 0, len(o.raw)+len(o.Map))
+	emitted := make(map[string]bool, len(o.Map))
+	for _, kv := range o.raw {
+		i := strings.IndexByte(kv, '=')
+		if i == -1 {
+			kvs = append(kvs, kv)
+			continue
+		}
+		k := kv[:i]
+		v, ok := o.Map[k]
+		switch {
+		case !ok:
+		case v == o.orig[k]:
+			kvs = append(kvs, kv)
+		case !emitted[k]:
+			kvs = append(kvs, k+"="+v)
+		}
+		emitted[k] = true
+	}
+	for _, k := range o.keys() {
+		if !emitted[k] {
+			kvs = append(kvs, k+"="+o.Map[k])
+		}
+	}
+	return kvs
+}
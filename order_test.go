@@ -0,0 +1,85 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env_test
+
+import (
+	"strings"
+	"testing"
+
+	"acln.ro/env"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestEncodeOrder(t *testing.T) {
+	m := env.Map{"LANG": "C", "HOME": "/root", "PATH": "/bin", "A": "a", "Z": "z"}
+	tests := []struct {
+		opts []env.EncodeOption
+		want []string
+	}{
+		{
+			opts: nil,
+			want: []string{"A=a", "HOME=/root", "LANG=C", "PATH=/bin", "Z=z"},
+		},
+		{
+			opts: []env.EncodeOption{env.Priority("PATH", "HOME", "MISSING")},
+			want: []string{"PATH=/bin", "HOME=/root", "A=a", "LANG=C", "Z=z"},
+		},
+		{
+			opts: []env.EncodeOption{env.SortFunc(func(a, b string) bool {
+				return a > b
+			})},
+			want: []string{"Z=z", "PATH=/bin", "LANG=C", "HOME=/root", "A=a"},
+		},
+		{
+			opts: []env.EncodeOption{env.SortFunc(func(a, b string) bool {
+				return len(a) < len(b)
+			})},
+			want: []string{"A=a", "Z=z", "HOME=/root", "LANG=C", "PATH=/bin"},
+		},
+	}
+	for _, tt := range tests {
+		got := m.Encode(tt.opts...)
+		if diff := cmp.Diff(got, tt.want); diff != "" {
+			t.Errorf("Encode: got %q, want %q: %s", got, tt.want, diff)
+		}
+		gotStr := m.Sprint(tt.opts...)
+		if want := strings.Join(tt.want, " "); gotStr != want {
+			t.Errorf("Sprint: got %q, want %q", gotStr, want)
+		}
+	}
+}
+
+func TestEncodeOriginalOrder(t *testing.T) {
+	kvs := []string{"PATH=/bin", "HOME=/root", "malformed", "LANG=C", "HOME=/home/x", "TERM=xterm"}
+	o := env.ParseOrdered(kvs...)
+	if diff := cmp.Diff(o.EncodeOriginalOrder(), kvs); diff != "" {
+		t.Errorf("unmodified EncodeOriginalOrder: %s", diff)
+	}
+	if got := o.Map["HOME"]; got != "/home/x" {
+		t.Errorf("HOME = %q, want %q", got, "/home/x")
+	}
+
+	o.Map["LANG"] = "C.UTF-8"
+	o.Map["HOME"] = "/home/y"
+	delete(o.Map, "PATH")
+	o.Map["B"] = "b"
+	o.Map["A"] = "a"
+	got := o.EncodeOriginalOrder()
+	want := []string{"HOME=/home/y", "malformed", "LANG=C.UTF-8", "TERM=xterm", "A=a", "B=b"}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("EncodeOriginalOrder: got %q, want %q: %s", got, want, diff)
+	}
+}
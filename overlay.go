@@ -0,0 +1,63 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env
+
+// Overlay returns a Layered view of base, overridden by layers. The maps
+// are not copied, so changes to them are reflected in the view.
+func Overlay(base Map, layers ...Map) *Layered {
+	return &Layered{maps: append([]Map{base}, layers...)}
+}
+
+// Layered is a view of a stack of maps, which behaves like the result of
+// merging them with Merge, but without copying any of them. In case of
+// key collisions, values from maps higher in the stack take precedence.
+type Layered struct {
+	maps []Map // base first
+}
+
+// Lookup returns the value of the variable named by key, and whether it
+// is set. Lookup searches the layers from the top of the stack down, and
+// the base last.
+func (l *Layered) Lookup(key string) (string, bool) {
+	for i := len(l.maps) - 1; i >= 0; i-- {
+		if v, ok := l.maps[i][key]; ok {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// Get returns the value of the variable named by key, or the empty string
+// if the variable is not set.
+func (l *Layered) Get(key string) string {
+	v, _ := l.Lookup(key)
+	return v
+}
+
+// Map materializes the view into a new Map.
+func (l *Layered) Map() Map {
+	return Merge(l.maps...)
+}
+
+// Encode encodes the view as a slice of "key=value" pairs, like
+// Map.Encode.
+func (l *Layered) Encode(opts ...EncodeOption) []string {
+	return l.Map().Encode(opts...)
+}
+
+// String encodes the view like Map.String.
+func (l *Layered) String() string {
+	return l.Map().String()
+}
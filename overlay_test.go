@@ -0,0 +1,66 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env_test
+
+import (
+	"testing"
+
+	"acln.ro/env"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestOverlay(t *testing.T) {
+	base := env.Map{"PATH": "/bin", "HOME": "/root", "LANG": "C"}
+	req := env.Map{"LANG": "C.UTF-8", "REQUEST_ID": "1"}
+	debug := env.Map{"REQUEST_ID": "2", "DEBUG": "1"}
+	l := env.Overlay(base, req, debug)
+
+	tests := []struct {
+		key  string
+		want string
+		ok   bool
+	}{
+		{"PATH", "/bin", true},
+		{"LANG", "C.UTF-8", true},
+		{"REQUEST_ID", "2", true},
+		{"DEBUG", "1", true},
+		{"MISSING", "", false},
+	}
+	for _, tt := range tests {
+		if got, ok := l.Lookup(tt.key); got != tt.want || ok != tt.ok {
+			t.Errorf("Lookup(%q) = %q, %t, want %q, %t", tt.key, got, ok, tt.want, tt.ok)
+		}
+		if got := l.Get(tt.key); got != tt.want {
+			t.Errorf("Get(%q) = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+
+	merged := env.Merge(base, req, debug)
+	if diff := cmp.Diff(l.Map(), merged); diff != "" {
+		t.Errorf("Map() = %v, want %v: %s", l.Map(), merged, diff)
+	}
+	if diff := cmp.Diff(l.Encode(), merged.Encode()); diff != "" {
+		t.Errorf("Encode() = %q, want %q: %s", l.Encode(), merged.Encode(), diff)
+	}
+	if got, want := l.String(), merged.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+
+	base["HOME"] = "/home/x"
+	if got := l.Get("HOME"); got != "/home/x" {
+		t.Errorf("after modifying base: Get(HOME) = %q, want %q", got, "/home/x")
+	}
+}
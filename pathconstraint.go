@@ -0,0 +1,128 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// A PathConstraint constrains a path-valued variable.
+type PathConstraint struct {
+	// Absolute requires the path to be absolute.
+	Absolute bool `json:"absolute,omitempty"`
+
+	// Exists requires the path to exist.
+	Exists bool `json:"exists,omitempty"`
+
+	// Dir requires the path to be a directory, if it exists. Combine
+	// with Exists to require an existing directory.
+	Dir bool `json:"dir,omitempty"`
+
+	// Writable requires the path to be writable: an existing regular
+	// file must open for writing, and an existing directory must permit
+	// the creation of files. Other files, such as FIFOs and devices,
+	// which opening could block on, must have a write permission bit
+	// set. If the path does not exist, its parent directory must permit
+	// creating it.
+	Writable bool `json:"writable,omitempty"`
+}
+
+// check checks that path satisfies c. The file system is only consulted
+// if c requires it.
+func (c *PathConstraint) check(path string) error {
+	if path == "" {
+		return errors.New("path is empty")
+	}
+	if c.Absolute && !filepath.IsAbs(path) {
+		return fmt.Errorf("path %s is not absolute", path)
+	}
+	if !c.Exists && !c.Dir && !c.Writable {
+		return nil
+	}
+	fi, err := os.Stat(path)
+	switch {
+	case os.IsNotExist(err) && c.Exists:
+		return fmt.Errorf("path %s does not exist", path)
+	case os.IsNotExist(err):
+		if c.Writable {
+			return checkCreatable(path)
+		}
+		return nil
+	case err != nil:
+		return err
+	}
+	if c.Dir && !fi.IsDir() {
+		return fmt.Errorf("path %s is not a directory", path)
+	}
+	if !c.Writable {
+		return nil
+	}
+	if fi.IsDir() {
+		return checkDirWritable(path, path)
+	}
+	if !fi.Mode().IsRegular() {
+		if fi.Mode().Perm()&0222 == 0 {
+			return fmt.Errorf("path %s is not writable: mode %v", path, fi.Mode())
+		}
+		return nil
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		return fmt.Errorf("path %s is not writable: %v", path, unwrapPathError(err))
+	}
+	return f.Close()
+}
+
+// checkCreatable checks that path, which does not exist, can be created.
+func checkCreatable(path string) error {
+	parent := filepath.Dir(path)
+	fi, err := os.Stat(parent)
+	switch {
+	case os.IsNotExist(err):
+		return fmt.Errorf("path %s cannot be created: %s does not exist", path, parent)
+	case err != nil:
+		return err
+	case !fi.IsDir():
+		return fmt.Errorf("path %s cannot be created: %s is not a directory", path, parent)
+	}
+	return checkDirWritable(path, parent)
+}
+
+// checkDirWritable checks that files can be created in dir, by creating
+// and removing a temporary file. path is the path being validated.
+func checkDirWritable(path, dir string) error {
+	f, err := ioutil.TempFile(dir, ".env-writable-")
+	if err != nil {
+		if path != dir {
+			return fmt.Errorf("path %s cannot be created: %v", path, unwrapPathError(err))
+		}
+		return fmt.Errorf("path %s is not writable: %v", path, unwrapPathError(err))
+	}
+	f.Close()
+	return os.Remove(f.Name())
+}
+
+// unwrapPathError returns the underlying error of a *os.PathError, the
+// path of which is redundant in the messages above.
+func unwrapPathError(err error) error {
+	if perr, ok := err.(*os.PathError); ok {
+		return perr.Err
+	}
+	return err
+}
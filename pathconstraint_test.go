@@ -0,0 +1,155 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env_test
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"acln.ro/env"
+)
+
+func TestPathConstraint(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"file": "x"})
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "file")
+	missing := filepath.Join(dir, "missing")
+
+	tests := []struct {
+		c     env.PathConstraint
+		path  string
+		error string // substring of the reason, if any
+	}{
+		{c: env.PathConstraint{Absolute: true}, path: dir},
+		{c: env.PathConstraint{Absolute: true}, path: "data", error: "not absolute"},
+		{c: env.PathConstraint{}, path: "", error: "empty"},
+		{c: env.PathConstraint{Exists: true}, path: file},
+		{c: env.PathConstraint{Exists: true}, path: missing, error: "does not exist"},
+		{c: env.PathConstraint{Dir: true}, path: missing},
+		{c: env.PathConstraint{Exists: true, Dir: true}, path: dir},
+		{c: env.PathConstraint{Exists: true, Dir: true}, path: file, error: "not a directory"},
+		{c: env.PathConstraint{Writable: true}, path: dir},
+		{c: env.PathConstraint{Writable: true}, path: file},
+		{c: env.PathConstraint{Writable: true}, path: missing},
+		{c: env.PathConstraint{Writable: true}, path: filepath.Join(missing, "x"), error: "cannot be created"},
+		{c: env.PathConstraint{Writable: true}, path: filepath.Join(file, "x"), error: "not a directory"},
+	}
+	for _, tt := range tests {
+		c := tt.c
+		schema := &env.Schema{Vars: []env.Var{{Key: "P", Path: &c}}}
+		err := schema.Validate(env.Map{"P": tt.path})
+		if tt.error == "" {
+			if err != nil {
+				t.Errorf("%+v, %q: %v", tt.c, tt.path, err)
+			}
+			continue
+		}
+		var verr *env.ValidationError
+		if !errors.As(err, &verr) || !strings.Contains(verr.Reason, tt.error) {
+			t.Errorf("%+v, %q: got %v, want error containing %q", tt.c, tt.path, err, tt.error)
+		}
+	}
+
+	entries, err := filepath.Glob(filepath.Join(dir, ".env-writable-*"))
+	if err != nil || len(entries) > 0 {
+		t.Errorf("temporary files left behind: %v, %v", entries, err)
+	}
+}
+
+func TestPathConstraintFIFO(t *testing.T) {
+	if _, err := exec.LookPath("mkfifo"); err != nil {
+		t.Skip("mkfifo not available")
+	}
+	dir := writeFiles(t, nil)
+	defer os.RemoveAll(dir)
+	fifo := filepath.Join(dir, "fifo")
+	readOnly := filepath.Join(dir, "fifo-ro")
+	if out, err := exec.Command("mkfifo", "-m", "0600", fifo).CombinedOutput(); err != nil {
+		t.Fatalf("mkfifo: %v: %s", err, out)
+	}
+	if out, err := exec.Command("mkfifo", "-m", "0400", readOnly).CombinedOutput(); err != nil {
+		t.Fatalf("mkfifo: %v: %s", err, out)
+	}
+	c := env.PathConstraint{Writable: true}
+	schema := &env.Schema{Vars: []env.Var{{Key: "P", Path: &c}}}
+	// A FIFO with no reader would block a writer opening it.
+	if err := schema.Validate(env.Map{"P": fifo}); err != nil {
+		t.Errorf("FIFO: %v", err)
+	}
+	err := schema.Validate(env.Map{"P": readOnly})
+	var verr *env.ValidationError
+	if !errors.As(err, &verr) || !strings.Contains(verr.Reason, "not writable") {
+		t.Errorf("read-only FIFO: got %v, want error containing %q", err, "not writable")
+	}
+}
+
+func TestPathConstraintNotWritable(t *testing.T) {
+	if runtime.GOOS == "windows" || os.Geteuid() == 0 {
+		t.Skip("permissions are not enforced")
+	}
+	dir := writeFiles(t, nil)
+	defer os.RemoveAll(dir)
+	if err := os.Chmod(dir, 0500); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chmod(dir, 0700)
+	c := env.PathConstraint{Writable: true}
+	schema := &env.Schema{Vars: []env.Var{
+		{Key: "DIR", Path: &c},
+		{Key: "NEW", Path: &c},
+	}}
+	err := schema.Validate(env.Map{"DIR": dir, "NEW": filepath.Join(dir, "new")})
+	var errs env.ErrorList
+	if !errors.As(err, &errs) || len(errs) != 2 {
+		t.Errorf("got %v, want two errors", err)
+	}
+}
+
+func TestDecodePathRules(t *testing.T) {
+	dir := writeFiles(t, nil)
+	defer os.RemoveAll(dir)
+	var cfg struct {
+		Data  string `env:"DATA_DIR" validate:"abs exists dir writable"`
+		Cache string `env:"CACHE_DIR" validate:"abs"`
+	}
+	err := env.Decode(env.Map{"DATA_DIR": dir, "CACHE_DIR": "cache"}, &cfg)
+	var errs env.ErrorList
+	if !errors.As(err, &errs) || len(errs) != 1 {
+		t.Fatalf("got %v, want one error", err)
+	}
+	var verr *env.ValidationError
+	if !errors.As(errs[0], &verr) || verr.Key != "CACHE_DIR" {
+		t.Errorf("got %v, want error for CACHE_DIR", errs[0])
+	}
+
+	var bad struct {
+		Data string `env:"DATA_DIR" validate:"abs bogus"`
+	}
+	err = env.Decode(env.Map{}, &bad)
+	if err == nil || !strings.Contains(err.Error(), "bogus") {
+		t.Errorf("Decode with unknown rule: got %v", err)
+	}
+	var bad2 struct {
+		Data string `env:"DATA_DIR" validate:"abs=yes"`
+	}
+	if err := env.Decode(env.Map{}, &bad2); err == nil {
+		t.Error("Decode with argument to abs succeeded")
+	}
+}
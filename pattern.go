@@ -0,0 +1,46 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// compilePattern compiles pattern, anchored so as to match whole values.
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	re, err := regexp.Compile(anchored(pattern))
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern %s: %v", pattern, err)
+	}
+	return re, nil
+}
+
+// anchored returns pattern, anchored to match whole values.
+func anchored(pattern string) string {
+	return "^(?:" + pattern + ")$"
+}
+
+// checkPattern checks that s matches re, which was compiled from pattern.
+// Unless secret is true, the error reports the value.
+func checkPattern(s string, re *regexp.Regexp, pattern string, secret bool) error {
+	if re.MatchString(s) {
+		return nil
+	}
+	if secret {
+		return fmt.Errorf("value does not match pattern %s", pattern)
+	}
+	return fmt.Errorf("value %q does not match pattern %s", s, pattern)
+}
@@ -0,0 +1,120 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env_test
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"acln.ro/env"
+)
+
+func TestPattern(t *testing.T) {
+	const region = `[a-z]{2}-[a-z]+-\d`
+	tests := []struct {
+		value  string
+		secret bool
+		reason string
+	}{
+		{value: "eu-west-1"},
+		{value: "us-east-2"},
+		{value: "eu-west-1a", reason: `value "eu-west-1a" does not match pattern [a-z]{2}-[a-z]+-\d`},
+		{value: "xeu-west-1", reason: `value "xeu-west-1" does not match pattern [a-z]{2}-[a-z]+-\d`},
+		{value: "EU", secret: true, reason: `value does not match pattern [a-z]{2}-[a-z]+-\d`},
+	}
+	for _, tt := range tests {
+		schema := &env.Schema{Vars: []env.Var{{Key: "REGION", Pattern: region, Secret: tt.secret}}}
+		err := schema.Validate(env.Map{"REGION": tt.value})
+		if tt.reason == "" {
+			if err != nil {
+				t.Errorf("%q: %v", tt.value, err)
+			}
+			continue
+		}
+		var verr *env.ValidationError
+		if !errors.As(err, &verr) || verr.Reason != tt.reason {
+			t.Errorf("%q: got %v, want reason %q", tt.value, err, tt.reason)
+		}
+	}
+}
+
+func TestPatternAlternation(t *testing.T) {
+	// Anchoring must apply to the whole alternation.
+	schema := &env.Schema{Vars: []env.Var{{Key: "MODE", Pattern: "a|b"}}}
+	if err := schema.Validate(env.Map{"MODE": "ab"}); err == nil {
+		t.Error("pattern a|b matched ab")
+	}
+	if err := schema.Validate(env.Map{"MODE": "b"}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestInvalidPattern(t *testing.T) {
+	var schema env.Schema
+	err := json.Unmarshal([]byte(`{"vars": [{"key": "REGION", "pattern": "[a-z"}]}`), &schema)
+	if err == nil || !strings.Contains(err.Error(), "REGION") || !strings.Contains(err.Error(), "[a-z") {
+		t.Errorf("Unmarshal: got %v, want error naming the variable and pattern", err)
+	}
+
+	var cfg struct {
+		Region string `env:"REGION" validate:"pattern=[a-z"`
+	}
+	if err := env.Decode(env.Map{}, &cfg); err == nil || !strings.Contains(err.Error(), "invalid pattern") {
+		t.Errorf("Decode: got %v, want invalid pattern error", err)
+	}
+}
+
+func TestDecodePattern(t *testing.T) {
+	var cfg struct {
+		Name string `env:"RESOURCE_NAME" validate:"pattern=[a-z][a-z0-9-]{0,62}"`
+	}
+	if err := env.Decode(env.Map{"RESOURCE_NAME": "my-bucket"}, &cfg); err != nil {
+		t.Error(err)
+	}
+	if err := env.Decode(env.Map{"RESOURCE_NAME": "My_Bucket"}, &cfg); err == nil {
+		t.Error("Decode with value not matching pattern succeeded")
+	}
+}
+
+func TestJSONSchemaPattern(t *testing.T) {
+	schema := &env.Schema{Vars: []env.Var{
+		{Key: "REGION", Pattern: `[a-z]{2}-[a-z]+-\d`},
+		{Key: "PORT", Type: env.Int, Pattern: `[0-9]{4}`},
+	}}
+	data, err := schema.JSONSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		Properties map[string]struct {
+			Pattern string `json:"pattern"`
+			AllOf   []struct {
+				Pattern string `json:"pattern"`
+			} `json:"allOf"`
+		} `json:"properties"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := doc.Properties["REGION"].Pattern, `^(?:[a-z]{2}-[a-z]+-\d)$`; got != want {
+		t.Errorf("REGION pattern = %q, want %q", got, want)
+	}
+	port := doc.Properties["PORT"]
+	if port.Pattern == "" || len(port.AllOf) != 1 || port.AllOf[0].Pattern != `^(?:[0-9]{4})$` {
+		t.Errorf("PORT: got %+v", port)
+	}
+}
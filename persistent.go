@@ -0,0 +1,89 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env
+
+// Scope selects the persistent Windows environment of the current user,
+// or that of the machine.
+type Scope int
+
+// Supported scopes.
+const (
+	// UserScope is the environment of the current user, stored under
+	// HKEY_CURRENT_USER\Environment.
+	UserScope Scope = iota
+
+	// MachineScope is the environment shared by all users, stored
+	// under HKEY_LOCAL_MACHINE\SYSTEM\CurrentControlSet\Control\Session
+	// Manager\Environment. Changing it requires administrator rights.
+	MachineScope
+)
+
+// String returns "user" or "machine".
+func (s Scope) String() string {
+	if s == MachineScope {
+		return "machine"
+	}
+	return "user"
+}
+
+// A PersistentValue is the value of a variable in the persistent Windows
+// environment, as stored in the registry.
+//
+// Values stored as REG_EXPAND_SZ, such as %USERPROFILE%\bin, refer to
+// other variables, and are expanded by Windows when a session starts.
+// Collapsing such a value to its expanded form when writing it back
+// breaks the reference, so PersistentValue keeps the raw form and the
+// type, and expands on demand.
+type PersistentValue struct {
+	// Raw is the value as stored.
+	Raw string
+
+	// Expand reports whether the value is stored as REG_EXPAND_SZ
+	// rather than REG_SZ.
+	Expand bool
+}
+
+// Expanded returns the value with %VAR% references expanded against m,
+// which is usually the environment of the current process, if v is stored
+// as REG_EXPAND_SZ. Otherwise, it returns v.Raw.
+func (v PersistentValue) Expanded(m Map) string {
+	if !v.Expand {
+		return v.Raw
+	}
+	return m.Expand(v.Raw, WithSyntax(WindowsSyntax))
+}
+
+// ReadPersistent reads the persistent Windows environment of the
+// specified scope. Values of types other than REG_SZ and REG_EXPAND_SZ are
+// skipped. On other systems, ReadPersistent returns an error.
+func ReadPersistent(scope Scope) (map[string]PersistentValue, error) {
+	return readPersistent(scope)
+}
+
+// SetPersistent sets key to v in the persistent Windows environment of
+// the specified scope, storing it as REG_EXPAND_SZ if v.Expand is set, and
+// notifies running applications of the change. On other systems,
+// SetPersistent returns an error.
+func SetPersistent(scope Scope, key string, v PersistentValue) error {
+	return setPersistent(scope, key, v)
+}
+
+// UnsetPersistent removes key from the persistent Windows environment of
+// the specified scope, and notifies running applications of the change.
+// Removing a variable which is not set is not an error. On other systems,
+// UnsetPersistent returns an error.
+func UnsetPersistent(scope Scope, key string) error {
+	return unsetPersistent(scope, key)
+}
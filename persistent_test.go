@@ -0,0 +1,39 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env_test
+
+import (
+	"testing"
+
+	"acln.ro/env"
+)
+
+func TestPersistentValueExpanded(t *testing.T) {
+	m := env.Map{"USERPROFILE": `C:\Users\u`}
+	tests := []struct {
+		v    env.PersistentValue
+		want string
+	}{
+		{env.PersistentValue{Raw: `%USERPROFILE%\bin`, Expand: true}, `C:\Users\u\bin`},
+		{env.PersistentValue{Raw: `%USERPROFILE%\bin`}, `%USERPROFILE%\bin`},
+		{env.PersistentValue{Raw: `%MISSING%\bin`, Expand: true}, `%MISSING%\bin`},
+		{env.PersistentValue{Raw: `$HOME`, Expand: true}, `$HOME`},
+	}
+	for _, tt := range tests {
+		if got := tt.v.Expanded(m); got != tt.want {
+			t.Errorf("%+v.Expanded() = %q, want %q", tt.v, got, tt.want)
+		}
+	}
+}
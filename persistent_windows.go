@@ -0,0 +1,177 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	modadvapi32         = syscall.NewLazyDLL("advapi32.dll")
+	procRegEnumValueW   = modadvapi32.NewProc("RegEnumValueW")
+	procRegSetValueExW  = modadvapi32.NewProc("RegSetValueExW")
+	procRegDeleteValueW = modadvapi32.NewProc("RegDeleteValueW")
+
+	moduser32               = syscall.NewLazyDLL("user32.dll")
+	procSendMessageTimeoutW = moduser32.NewProc("SendMessageTimeoutW")
+)
+
+const (
+	userEnvKey    = `Environment`
+	machineEnvKey = `SYSTEM\CurrentControlSet\Control\Session Manager\Environment`
+
+	errorNoMoreItems = 259
+
+	hwndBroadcast    = 0xffff
+	wmSettingChange  = 0x001a
+	smtoAbortIfHung  = 0x0002
+	broadcastTimeout = 5000 // milliseconds
+)
+
+// openEnvKey opens the registry key holding the environment of scope.
+func openEnvKey(scope Scope, access uint32) (syscall.Handle, error) {
+	root, path := syscall.Handle(syscall.HKEY_CURRENT_USER), userEnvKey
+	if scope == MachineScope {
+		root, path = syscall.Handle(syscall.HKEY_LOCAL_MACHINE), machineEnvKey
+	}
+	p, err := syscall.UTF16PtrFromString(path)
+	if err != nil {
+		return 0, err
+	}
+	var h syscall.Handle
+	if err := syscall.RegOpenKeyEx(root, p, 0, access, &h); err != nil {
+		return 0, err
+	}
+	return h, nil
+}
+
+func readPersistent(scope Scope) (map[string]PersistentValue, error) {
+	h, err := openEnvKey(scope, syscall.KEY_READ)
+	if err != nil {
+		return nil, fmt.Errorf("env: reading %s environment: %v", scope, err)
+	}
+	defer syscall.RegCloseKey(h)
+
+	var nvalues, maxNameLen, maxDataLen uint32
+	err = syscall.RegQueryInfoKey(h, nil, nil, nil, nil, nil, nil, &nvalues, &maxNameLen, &maxDataLen, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("env: reading %s environment: %v", scope, err)
+	}
+	name := make([]uint16, maxNameLen+1)
+	data := make([]uint16, maxDataLen/2+1)
+	vals := make(map[string]PersistentValue, nvalues)
+	for i := uint32(0); ; i++ {
+		nameLen := uint32(len(name))
+		dataLen := uint32(len(data) * 2)
+		var typ uint32
+		r, _, _ := procRegEnumValueW.Call(
+			uintptr(h),
+			uintptr(i),
+			uintptr(unsafe.Pointer(&name[0])),
+			uintptr(unsafe.Pointer(&nameLen)),
+			0, // reserved
+			uintptr(unsafe.Pointer(&typ)),
+			uintptr(unsafe.Pointer(&data[0])),
+			uintptr(unsafe.Pointer(&dataLen)),
+		)
+		if r == errorNoMoreItems {
+			break
+		}
+		if r != 0 {
+			return nil, fmt.Errorf("env: reading %s environment: %v", scope, syscall.Errno(r))
+		}
+		if typ != syscall.REG_SZ && typ != syscall.REG_EXPAND_SZ {
+			continue
+		}
+		vals[syscall.UTF16ToString(name[:nameLen])] = PersistentValue{
+			Raw:    syscall.UTF16ToString(data[:dataLen/2]),
+			Expand: typ == syscall.REG_EXPAND_SZ,
+		}
+	}
+	return vals, nil
+}
+
+func setPersistent(scope Scope, key string, v PersistentValue) error {
+	name, err := syscall.UTF16FromString(key)
+	if err != nil {
+		return fmt.Errorf("env: setting %s in %s environment: %v", key, scope, err)
+	}
+	data, err := syscall.UTF16FromString(v.Raw)
+	if err != nil {
+		return fmt.Errorf("env: setting %s in %s environment: %v", key, scope, err)
+	}
+	typ := uint32(syscall.REG_SZ)
+	if v.Expand {
+		typ = syscall.REG_EXPAND_SZ
+	}
+	h, err := openEnvKey(scope, syscall.KEY_SET_VALUE)
+	if err != nil {
+		return fmt.Errorf("env: setting %s in %s environment: %v", key, scope, err)
+	}
+	defer syscall.RegCloseKey(h)
+	r, _, _ := procRegSetValueExW.Call(
+		uintptr(h),
+		uintptr(unsafe.Pointer(&name[0])),
+		0, // reserved
+		uintptr(typ),
+		uintptr(unsafe.Pointer(&data[0])),
+		uintptr(len(data)*2),
+	)
+	if r != 0 {
+		return fmt.Errorf("env: setting %s in %s environment: %v", key, scope, syscall.Errno(r))
+	}
+	broadcastEnvChange()
+	return nil
+}
+
+func unsetPersistent(scope Scope, key string) error {
+	name, err := syscall.UTF16PtrFromString(key)
+	if err != nil {
+		return fmt.Errorf("env: unsetting %s in %s environment: %v", key, scope, err)
+	}
+	h, err := openEnvKey(scope, syscall.KEY_SET_VALUE)
+	if err != nil {
+		return fmt.Errorf("env: unsetting %s in %s environment: %v", key, scope, err)
+	}
+	defer syscall.RegCloseKey(h)
+	r, _, _ := procRegDeleteValueW.Call(uintptr(h), uintptr(unsafe.Pointer(name)))
+	if r != 0 && r != uintptr(syscall.ERROR_FILE_NOT_FOUND) {
+		return fmt.Errorf("env: unsetting %s in %s environment: %v", key, scope, syscall.Errno(r))
+	}
+	broadcastEnvChange()
+	return nil
+}
+
+// broadcastEnvChange notifies top-level windows, such as Explorer, that
+// the persistent environment changed, so that processes they start later
+// observe the change. Failures are ignored: the change is stored either
+// way, and is picked up at the next logon.
+func broadcastEnvChange() {
+	p, err := syscall.UTF16PtrFromString("Environment")
+	if err != nil {
+		return
+	}
+	procSendMessageTimeoutW.Call(
+		hwndBroadcast,
+		wmSettingChange,
+		0,
+		uintptr(unsafe.Pointer(p)),
+		smtoAbortIfHung,
+		broadcastTimeout,
+		0, // result
+	)
+}
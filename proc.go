@@ -0,0 +1,47 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env
+
+import "strings"
+
+// ProcessVariables returns a Map of the environment of the process with
+// the specified pid. On Linux, the environment is read from /proc, and
+// reflects the environment the process was started with. On other
+// systems, ProcessVariables returns an error.
+//
+// Reading the environment of another user's process usually requires
+// elevated privileges.
+func ProcessVariables(pid int) (Map, error) {
+	return processVariables(pid)
+}
+
+// DiffProcesses computes differences between the environments of the
+// processes with the specified pids.
+func DiffProcesses(pid1, pid2 int) (Diff, error) {
+	m, err := ProcessVariables(pid1)
+	if err != nil {
+		return Diff{}, err
+	}
+	n, err := ProcessVariables(pid2)
+	if err != nil {
+		return Diff{}, err
+	}
+	return m.Diff(n), nil
+}
+
+// parseNUL parses a NUL-separated list of "key=value" pairs.
+func parseNUL(s string) Map {
+	return Parse(strings.Split(strings.TrimSuffix(s, "\x00"), "\x00")...)
+}
@@ -0,0 +1,74 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env_test
+
+import (
+	"os"
+	"os/exec"
+	"runtime"
+	"testing"
+
+	"acln.ro/env"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDiffProcesses(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("reading process environments is only supported on Linux")
+	}
+	start := func(m env.Map) *exec.Cmd {
+		cmd := exec.Command("sleep", "10")
+		cmd.Env = m.Encode()
+		if err := cmd.Start(); err != nil {
+			t.Skip(err)
+		}
+		return cmd
+	}
+	stop := func(cmd *exec.Cmd) {
+		cmd.Process.Kill()
+		cmd.Wait()
+	}
+	path := os.Getenv("PATH")
+	m := env.Map{"PATH": path, "SHELL_MODE": "interactive", "HOME": "/root"}
+	n := env.Map{"PATH": path, "SHELL_MODE": "cron", "CRON": "1"}
+	cmd1 := start(m)
+	defer stop(cmd1)
+	cmd2 := start(n)
+	defer stop(cmd2)
+
+	got, err := env.ProcessVariables(cmd1.Process.Pid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(got, m); diff != "" {
+		t.Errorf("ProcessVariables = %v, want %v: %s", got, m, diff)
+	}
+
+	d, err := env.DiffProcesses(cmd1.Process.Pid, cmd2.Process.Pid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := env.Diff{
+		OnlyInM: env.Map{"HOME": "/root"},
+		Changes: []env.Change{
+			{Key: "SHELL_MODE", MValue: "interactive", NValue: "cron"},
+		},
+		OnlyInN: env.Map{"CRON": "1"},
+	}
+	if diff := cmp.Diff(d, want); diff != "" {
+		t.Errorf("DiffProcesses = %+v, want %+v: %s", d, want, diff)
+	}
+}
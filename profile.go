@@ -0,0 +1,121 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LoadProfiles loads the conventional cascade of dotenv files for the
+// named profile from dir. In order of increasing precedence:
+//
+//	.env                   defaults, shared by all profiles
+//	.env.<profile>         profile-specific defaults
+//	.env.local             local overrides, for all profiles
+//	.env.<profile>.local   local overrides for the profile
+//
+// Missing files are skipped. If profile is empty, only .env and
+// .env.local are loaded. By convention, .local files hold settings for a
+// single machine, and are not checked into version control.
+//
+// LoadProfiles only loads files. To give precedence to variables set in
+// the process environment, as most frameworks do, load the result in a
+// Chain which ends with Process.
+//
+// The options are applied as by Chain.Load. If the ProfileVar option is
+// specified, profile is only a default.
+func LoadProfiles(dir, profile string, opts ...LoadOption) (Map, error) {
+	cfg := loadConfig{environment: OS}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	vars := cfg.environment.All()
+	if cfg.profileKey != "" {
+		if v := vars[cfg.profileKey]; v != "" {
+			profile = v
+		}
+		if !allowedProfile(profile, cfg.allowedProfiles) {
+			return nil, fmt.Errorf("env: %s: unknown profile %q, want one of %s",
+				cfg.profileKey, profile, strings.Join(cfg.allowedProfiles, ", "))
+		}
+	}
+	if strings.ContainsAny(profile, `/\`) || profile == "." || profile == ".." {
+		return nil, fmt.Errorf("env: invalid profile name %q", profile)
+	}
+	names := []string{".env", ".env.local"}
+	if profile != "" {
+		names = []string{".env", ".env." + profile, ".env.local", ".env." + profile + ".local"}
+	}
+	_, inCI := DetectCI(vars)
+	var chain Chain
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		if cfg.noLocalInCI && inCI && strings.HasSuffix(name, ".local") {
+			if _, err := os.Stat(path); err == nil {
+				return nil, fmt.Errorf("env: %s: local files are not allowed in CI", path)
+			}
+			continue
+		}
+		chain = append(chain, DotenvFile{Path: path, Optional: true})
+	}
+	return chain.Load(context.Background(), opts...)
+}
+
+// DisallowLocalInCI causes LoadProfiles to fail if a .local file is
+// present while running in a continuous integration build, as detected
+// by DetectCI in the environment. Local files in CI usually mean
+// that machine-specific settings leaked into the build.
+func DisallowLocalInCI() LoadOption {
+	return func(cfg *loadConfig) {
+		cfg.noLocalInCI = true
+	}
+}
+
+// ProfileVar causes LoadProfiles to select the active profile from the
+// variable named by key in the environment, such as APP_ENV or
+// GO_ENV. If the variable is not set, or is empty, the profile passed to
+// LoadProfiles is used. If allowed is not empty, LoadProfiles fails
+// unless the active profile is one of the allowed profiles.
+func ProfileVar(key string, allowed ...string) LoadOption {
+	return func(cfg *loadConfig) {
+		cfg.profileKey = key
+		cfg.allowedProfiles = allowed
+	}
+}
+
+// WithEnvironment causes LoadProfiles to consult e, rather than OS, when
+// selecting the active profile through ProfileVar, and when detecting CI
+// builds for DisallowLocalInCI.
+func WithEnvironment(e Environment) LoadOption {
+	return func(cfg *loadConfig) {
+		cfg.environment = e
+	}
+}
+
+func allowedProfile(profile string, allowed []string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	for _, p := range allowed {
+		if p == profile {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,136 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"acln.ro/env"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// writeFiles creates a temporary directory holding the specified files,
+// and returns its path.
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "env-profile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			os.RemoveAll(dir)
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestLoadProfiles(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		".env":                  "A=env\nB=env\nC=env\nD=env\nE=env\n",
+		".env.local":            "B=local\nC=local\nD=local\n",
+		".env.production":       "C=production\nD=production\nE=production\n",
+		".env.production.local": "D=production.local\n",
+		".env.staging":          "A=staging\n",
+	})
+	defer os.RemoveAll(dir)
+
+	got, err := env.LoadProfiles(dir, "production")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := env.Map{"A": "env", "B": "local", "C": "local", "D": "production.local", "E": "production"}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("production: %s", diff)
+	}
+
+	got, err = env.LoadProfiles(dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = env.Map{"A": "env", "B": "local", "C": "local", "D": "local", "E": "env"}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("no profile: %s", diff)
+	}
+
+	if _, err := env.LoadProfiles(dir, "../etc"); err == nil {
+		t.Error("LoadProfiles accepted profile containing a path separator")
+	}
+	if _, err := env.LoadProfiles(dir, "test", env.Require("MISSING")); err == nil {
+		t.Error("LoadProfiles ignored Require")
+	}
+}
+
+func TestLoadProfilesDisallowLocalInCI(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		".env":       "A=env\n",
+		".env.local": "A=local\n",
+	})
+	defer os.RemoveAll(dir)
+	ci := env.WithEnvironment(env.Fake(env.Map{"CI": "true"}))
+
+	if _, err := env.LoadProfiles(dir, "", env.DisallowLocalInCI(), ci); err == nil {
+		t.Error("LoadProfiles loaded .local file in CI")
+	}
+	if err := os.Remove(filepath.Join(dir, ".env.local")); err != nil {
+		t.Fatal(err)
+	}
+	got, err := env.LoadProfiles(dir, "", env.DisallowLocalInCI(), ci)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(got, env.Map{"A": "env"}); diff != "" {
+		t.Errorf("LoadProfiles: %s", diff)
+	}
+}
+
+func TestLoadProfilesProfileVar(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		".env":            "MODE=default\n",
+		".env.production": "MODE=production\n",
+		".env.staging":    "MODE=staging\n",
+	})
+	defer os.RemoveAll(dir)
+
+	allowed := []string{"development", "staging", "production"}
+	fake := env.Fake(env.Map{"APP_ENV": "staging"})
+	opts := []env.LoadOption{env.ProfileVar("APP_ENV", allowed...), env.WithEnvironment(fake)}
+	got, err := env.LoadProfiles(dir, "development", opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got["MODE"] != "staging" {
+		t.Errorf("APP_ENV=staging: MODE = %q, want %q", got["MODE"], "staging")
+	}
+
+	fake.Unset("APP_ENV")
+	got, err = env.LoadProfiles(dir, "production", opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got["MODE"] != "production" {
+		t.Errorf("APP_ENV unset: MODE = %q, want default profile", got["MODE"])
+	}
+
+	fake.Set("APP_ENV", "prod")
+	if _, err := env.LoadProfiles(dir, "", opts...); err == nil {
+		t.Error("LoadProfiles accepted profile not in the allowed set")
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// A ProfileScriptOption configures the writing of profile scripts.
+type ProfileScriptOption func(*profileScriptConfig)
+
+type profileScriptConfig struct {
+	appendPaths map[string]bool
+}
+
+// AppendPaths causes the specified list variables, such as PATH or
+// MANPATH, to be extended rather than overwritten: each colon-separated
+// element of the value is appended to the existing value of the variable
+// in the shell, unless it is already present. This keeps the script
+// idempotent, and preserves the entries of other profile scripts.
+func AppendPaths(keys ...string) ProfileScriptOption {
+	return func(cfg *profileScriptConfig) {
+		if cfg.appendPaths == nil {
+			cfg.appendPaths = make(map[string]bool)
+		}
+		for _, k := range keys {
+			cfg.appendPaths[k] = true
+		}
+	}
+}
+
+// WriteProfileScript writes m to w as a POSIX shell script suitable for
+// /etc/profile.d, which exports each variable, sorted by key. Values are
+// quoted such that the shell evaluates them literally.
+//
+// Variable names must be valid shell names: a letter or underscore,
+// followed by letters, digits and underscores. If some are not, nothing
+// is written, and the invalid names are reported together, as an
+// ErrorList.
+func (m Map) WriteProfileScript(w io.Writer, opts ...ProfileScriptOption) error {
+	var cfg profileScriptConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	var errs ErrorList
+	for _, k := range m.keys() {
+		if !isShellName(k) {
+			errs = append(errs, fmt.Errorf("env: invalid shell variable name %q", k))
+		}
+	}
+	if err := errs.err(); err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(w)
+	for _, k := range m.keys() {
+		if !cfg.appendPaths[k] {
+			fmt.Fprintf(bw, "%s\n", exportCommand(POSIXShell, k, m[k]))
+			continue
+		}
+		for _, elem := range strings.Split(m[k], ":") {
+			if elem == "" {
+				continue
+			}
+			writePathAppend(bw, k, elem)
+		}
+	}
+	return bw.Flush()
+}
+
+// writePathAppend writes the commands which append elem to the list
+// variable key, unless it is already present:
+//
+//	case ":${PATH}:" in
+//	*:'/opt/go/bin':*) ;;
+//	*) export PATH="${PATH:+${PATH}:}"'/opt/go/bin' ;;
+//	esac
+func writePathAppend(w io.Writer, key, elem string) {
+	q := posixQuote(elem)
+	fmt.Fprintf(w, "case \":${%s}:\" in\n", key)
+	fmt.Fprintf(w, "*:%s:*) ;;\n", q)
+	fmt.Fprintf(w, "*) export %s=\"${%s:+${%s}:}\"%s ;;\n", key, key, key, q)
+	fmt.Fprintf(w, "esac\n")
+}
+
+// isShellName reports whether s is a valid POSIX shell variable name.
+func isShellName(s string) bool {
+	return isHeredocDelim(s) && !('0' <= s[0] && s[0] <= '9')
+}
@@ -0,0 +1,99 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env_test
+
+import (
+	"bytes"
+	"errors"
+	"os/exec"
+	"runtime"
+	"strings"
+	"testing"
+
+	"acln.ro/env"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWriteProfileScript(t *testing.T) {
+	m := env.Map{
+		"GOPATH": "/opt/go",
+		"MSG":    "it's $HOME",
+		"PATH":   "/opt/go/bin:/opt/tools bin",
+	}
+	var buf bytes.Buffer
+	if err := m.WriteProfileScript(&buf, env.AppendPaths("PATH")); err != nil {
+		t.Fatal(err)
+	}
+	want := `export GOPATH='/opt/go'
+export MSG='it'\''s $HOME'
+case ":${PATH}:" in
+*:'/opt/go/bin':*) ;;
+*) export PATH="${PATH:+${PATH}:}"'/opt/go/bin' ;;
+esac
+case ":${PATH}:" in
+*:'/opt/tools bin':*) ;;
+*) export PATH="${PATH:+${PATH}:}"'/opt/tools bin' ;;
+esac
+`
+	if diff := cmp.Diff(buf.String(), want); diff != "" {
+		t.Errorf("WriteProfileScript: %s", diff)
+	}
+}
+
+func TestWriteProfileScriptSourced(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("sh not available")
+	}
+	m := env.Map{
+		"MSG":  "it's \"$HOME\"\n`x`",
+		"PATH": "/opt/a:/usr/bin",
+	}
+	var buf bytes.Buffer
+	if err := m.WriteProfileScript(&buf, env.AppendPaths("PATH")); err != nil {
+		t.Fatal(err)
+	}
+	script := buf.String()
+	// Sourcing the script twice must not duplicate PATH entries.
+	cmd := env.CommandIsolated(env.Map{"PATH": "/usr/bin:/bin"}, sh, "-c", script+script+`printf '%s\n%s' "$PATH" "$MSG"`)
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "/usr/bin:/bin:/opt/a\n" + m["MSG"]
+	if got := string(out); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestWriteProfileScriptInvalidNames(t *testing.T) {
+	m := env.Map{"OK": "1", "1BAD": "2", "BAD-NAME": "3"}
+	var buf bytes.Buffer
+	err := m.WriteProfileScript(&buf)
+	var errs env.ErrorList
+	if !errors.As(err, &errs) || len(errs) != 2 {
+		t.Fatalf("got %v, want 2 errors", err)
+	}
+	if !strings.Contains(err.Error(), `"1BAD"`) || !strings.Contains(err.Error(), `"BAD-NAME"`) {
+		t.Errorf("error %q does not name the invalid variables", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("wrote %q despite errors", buf.String())
+	}
+}
@@ -0,0 +1,201 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ProxyFunc returns a function suitable for use as the Proxy field of an
+// http.Transport, configured by the HTTP_PROXY, HTTPS_PROXY and NO_PROXY
+// variables (or the lowercase versions thereof) in m.
+//
+// ProxyFunc follows the semantics of http.ProxyFromEnvironment, but
+// consults m rather than the process environment. In particular, requests
+// to localhost or loopback addresses are never proxied, and if m carries
+// a REQUEST_METHOD variable, as is the case for CGI programs, HTTP_PROXY
+// is considered untrustworthy and its use results in an error.
+//
+// Changes to m after ProxyFunc returns do not affect the returned function.
+func ProxyFunc(m Map) func(*http.Request) (*url.URL, error) {
+	p := &proxyConfig{
+		httpProxy:  parseProxy(lookupAny(m, "HTTP_PROXY", "http_proxy")),
+		httpsProxy: parseProxy(lookupAny(m, "HTTPS_PROXY", "https_proxy")),
+		cgi:        m["REQUEST_METHOD"] != "",
+	}
+	p.parseNoProxy(lookupAny(m, "NO_PROXY", "no_proxy"))
+	return func(req *http.Request) (*url.URL, error) {
+		return p.proxyForURL(req.URL)
+	}
+}
+
+// lookupAny returns the first non-empty value of the specified keys.
+func lookupAny(m Map, keys ...string) string {
+	for _, k := range keys {
+		if v := m[k]; v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+type proxyConfig struct {
+	httpProxy  *url.URL
+	httpsProxy *url.URL
+	cgi        bool
+
+	all     bool
+	nets    []*net.IPNet
+	ips     []ipPort
+	domains []domainPort
+}
+
+type ipPort struct {
+	ip   net.IP
+	port string
+}
+
+type domainPort struct {
+	suffix    string // always starts with '.'
+	port      string
+	matchHost bool // also match suffix without the leading '.'
+}
+
+// parseProxy parses a proxy address. Addresses lacking a scheme are
+// assumed to be HTTP proxies. Invalid addresses are ignored.
+func parseProxy(s string) *url.URL {
+	if s == "" {
+		return nil
+	}
+	u, err := url.Parse(s)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		if u, err := url.Parse("http://" + s); err == nil {
+			return u
+		}
+		return nil
+	}
+	return u
+}
+
+// parseNoProxy parses a NO_PROXY value. Malformed entries are ignored.
+func (p *proxyConfig) parseNoProxy(s string) {
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry == "" {
+			continue
+		}
+		if entry == "*" {
+			p.all = true
+			return
+		}
+		if _, ipnet, err := net.ParseCIDR(entry); err == nil {
+			p.nets = append(p.nets, ipnet)
+			continue
+		}
+		host, port, err := net.SplitHostPort(entry)
+		if err != nil {
+			host, port = entry, ""
+		}
+		host = strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+		if host == "" {
+			continue
+		}
+		if ip := net.ParseIP(host); ip != nil {
+			p.ips = append(p.ips, ipPort{ip: ip, port: port})
+			continue
+		}
+		host = strings.TrimPrefix(host, "*")
+		matchHost := false
+		if !strings.HasPrefix(host, ".") {
+			matchHost = true
+			host = "." + host
+		}
+		p.domains = append(p.domains, domainPort{
+			suffix:    host,
+			port:      port,
+			matchHost: matchHost,
+		})
+	}
+}
+
+func (p *proxyConfig) proxyForURL(u *url.URL) (*url.URL, error) {
+	var proxy *url.URL
+	switch u.Scheme {
+	case "https":
+		proxy = p.httpsProxy
+	case "http":
+		proxy = p.httpProxy
+		if proxy != nil && p.cgi {
+			return nil, errors.New("env: refusing to use HTTP_PROXY value in CGI environment")
+		}
+	}
+	if proxy == nil || !p.useProxy(u) {
+		return nil, nil
+	}
+	return proxy, nil
+}
+
+// useProxy reports whether requests to u should be proxied.
+func (p *proxyConfig) useProxy(u *url.URL) bool {
+	host := strings.ToLower(u.Hostname())
+	port := u.Port()
+	if port == "" {
+		port = defaultPorts[u.Scheme]
+	}
+	if host == "localhost" {
+		return false
+	}
+	ip := net.ParseIP(host)
+	if ip != nil && ip.IsLoopback() {
+		return false
+	}
+	if p.all {
+		return false
+	}
+	if ip != nil {
+		for _, n := range p.nets {
+			if n.Contains(ip) {
+				return false
+			}
+		}
+		for _, e := range p.ips {
+			if e.ip.Equal(ip) && (e.port == "" || e.port == port) {
+				return false
+			}
+		}
+		return true
+	}
+	for _, d := range p.domains {
+		match := strings.HasSuffix(host, d.suffix) || (d.matchHost && host == d.suffix[1:])
+		if match && (d.port == "" || d.port == port) {
+			return false
+		}
+	}
+	return true
+}
+
+// defaultPorts maps URL schemes to their default ports.
+var defaultPorts = map[string]string{
+	"ftp":    "21",
+	"http":   "80",
+	"https":  "443",
+	"socks5": "1080",
+	"ws":     "80",
+	"wss":    "443",
+}
@@ -0,0 +1,91 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env_test
+
+import (
+	"net/http"
+	"testing"
+
+	"acln.ro/env"
+)
+
+func TestProxyFunc(t *testing.T) {
+	m := env.Map{
+		"HTTP_PROXY":  "http.proxy:3128",
+		"https_proxy": "https://secure.proxy:8443",
+		"NO_PROXY":    "internal.example.com, .corp, 10.0.0.0/8, 192.168.1.1:8080, [::2]",
+	}
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"http://example.com", "http://http.proxy:3128"},
+		{"https://example.com", "https://secure.proxy:8443"},
+		{"ftp://example.com", ""},
+		{"http://localhost:8080", ""},
+		{"http://127.0.0.1", ""},
+		{"http://[::1]:80", ""},
+		{"http://internal.example.com", ""},
+		{"http://api.internal.example.com", ""},
+		{"http://corp", "http://http.proxy:3128"},
+		{"http://git.corp", ""},
+		{"http://10.1.2.3", ""},
+		{"http://11.1.2.3", "http://http.proxy:3128"},
+		{"http://192.168.1.1:8080", ""},
+		{"http://192.168.1.1:9090", "http://http.proxy:3128"},
+		{"http://[::2]:1234", ""},
+	}
+	proxy := env.ProxyFunc(m)
+	for _, tt := range tests {
+		req, err := http.NewRequest("GET", tt.url, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		u, err := proxy(req)
+		if err != nil {
+			t.Errorf("proxy for %s: %v", tt.url, err)
+			continue
+		}
+		got := ""
+		if u != nil {
+			got = u.String()
+		}
+		if got != tt.want {
+			t.Errorf("proxy for %s = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestProxyFuncNoProxyAll(t *testing.T) {
+	m := env.Map{"HTTPS_PROXY": "https://proxy", "no_proxy": "*"}
+	req, err := http.NewRequest("GET", "https://example.com", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u, err := env.ProxyFunc(m)(req); u != nil || err != nil {
+		t.Errorf("got %v, %v, want no proxy", u, err)
+	}
+}
+
+func TestProxyFuncCGI(t *testing.T) {
+	m := env.Map{"HTTP_PROXY": "http://evil", "REQUEST_METHOD": "GET"}
+	req, err := http.NewRequest("GET", "http://example.com", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := env.ProxyFunc(m)(req); err == nil {
+		t.Error("HTTP_PROXY used in CGI environment")
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os/exec"
+	"strings"
+)
+
+// refPrefix marks values which are references to be resolved.
+const refPrefix = "ref+"
+
+// A Resolver resolves references of the form "ref+<scheme>://...".
+type Resolver interface {
+	// Scheme returns the scheme handled by the Resolver, such as "file".
+	Scheme() string
+
+	// Resolve returns the value ref refers to. ref is the reference
+	// without the "ref+" prefix, such as "file:///run/secrets/db".
+	Resolve(ctx context.Context, ref string) (string, error)
+}
+
+// Resolve returns a copy of m in which every value which is a reference
+// of the form "ref+<scheme>://..." is replaced by the value it refers to,
+// as returned by the Resolver for the scheme. Other values are copied
+// unchanged.
+//
+// Resolve fails if a reference uses a scheme for which no Resolver is
+// specified, or if a Resolver fails.
+func (m Map) Resolve(ctx context.Context, resolvers ...Resolver) (Map, error) {
+	byScheme := make(map[string]Resolver)
+	for _, r := range resolvers {
+		byScheme[r.Scheme()] = r
+	}
+	resolved := make(Map, len(m))
+	for _, k := range m.keys() {
+		v := m[k]
+		if !strings.HasPrefix(v, refPrefix) {
+			resolved[k] = v
+			continue
+		}
+		ref := v[len(refPrefix):]
+		i := strings.Index(ref, "://")
+		if i == -1 {
+			return nil, fmt.Errorf("env: %s: malformed reference %q", k, v)
+		}
+		r, ok := byScheme[ref[:i]]
+		if !ok {
+			return nil, fmt.Errorf("env: %s: no resolver for scheme %q", k, ref[:i])
+		}
+		rv, err := r.Resolve(ctx, ref)
+		if err != nil {
+			return nil, fmt.Errorf("env: %s: resolving %q: %v", k, v, err)
+		}
+		resolved[k] = rv
+	}
+	return resolved, nil
+}
+
+// FileResolver resolves "ref+file://" references to the contents of the
+// file named by the rest of the reference, with a single trailing newline
+// removed. This is suitable for secrets mounted as files, such as Docker
+// or Kubernetes secrets. The name is used as it is, without URL decoding:
+// for example, "ref+file:///run/secrets/db" reads /run/secrets/db, and
+// "ref+file://secrets/db" reads secrets/db, relative to the working
+// directory.
+var FileResolver Resolver = fileResolver{}
+
+type fileResolver struct{}
+
+func (fileResolver) Scheme() string { return "file" }
+
+func (fileResolver) Resolve(ctx context.Context, ref string) (string, error) {
+	name := strings.TrimPrefix(ref, "file://")
+	if name == "" {
+		return "", errors.New("empty file name")
+	}
+	b, err := ioutil.ReadFile(name)
+	if err != nil {
+		return "", err
+	}
+	return trimNewline(string(b)), nil
+}
+
+// ExecResolver resolves "ref+exec://" references by running the program
+// named by the rest of the reference, and using its standard output, with
+// a single trailing newline removed. For example, "ref+exec://./get-token"
+// runs ./get-token.
+var ExecResolver Resolver = execResolver{}
+
+type execResolver struct{}
+
+func (execResolver) Scheme() string { return "exec" }
+
+func (execResolver) Resolve(ctx context.Context, ref string) (string, error) {
+	name := strings.TrimPrefix(ref, "exec://")
+	out, err := exec.CommandContext(ctx, name).Output()
+	if err != nil {
+		return "", err
+	}
+	return trimNewline(string(out)), nil
+}
+
+func trimNewline(s string) string {
+	s = strings.TrimSuffix(s, "\n")
+	return strings.TrimSuffix(s, "\r")
+}
@@ -0,0 +1,114 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"acln.ro/env"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type vaultResolver map[string]string
+
+func (vaultResolver) Scheme() string { return "vault" }
+
+func (v vaultResolver) Resolve(ctx context.Context, ref string) (string, error) {
+	val, ok := v[strings.TrimPrefix(ref, "vault://")]
+	if !ok {
+		return "", errors.New("not found")
+	}
+	return val, nil
+}
+
+func TestResolve(t *testing.T) {
+	dir, err := ioutil.TempDir("", "env-resolve")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	secret := filepath.Join(dir, "db#1")
+	if err := ioutil.WriteFile(secret, []byte("hunter2\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	m := env.Map{
+		"PLAIN":     "value",
+		"DB_PASS":   "ref+file://" + filepath.ToSlash(secret),
+		"API_KEY":   "ref+vault://secret/api",
+		"NOT_A_REF": "ref:vault://x",
+	}
+	vault := vaultResolver{"secret/api": "k3y"}
+	got, err := m.Resolve(context.Background(), env.FileResolver, vault)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := env.Map{
+		"PLAIN":     "value",
+		"DB_PASS":   "hunter2",
+		"API_KEY":   "k3y",
+		"NOT_A_REF": "ref:vault://x",
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("Resolve: got %v, want %v: %s", got, want, diff)
+	}
+	if m["DB_PASS"] == "hunter2" {
+		t.Error("Resolve modified its receiver")
+	}
+}
+
+func TestResolveErrors(t *testing.T) {
+	tests := []env.Map{
+		{"X": "ref+vault://missing"},
+		{"X": "ref+unknown://thing"},
+		{"X": "ref+malformed"},
+	}
+	for _, m := range tests {
+		if _, err := m.Resolve(context.Background(), vaultResolver{}); err == nil {
+			t.Errorf("Resolve succeeded for %v", m)
+		}
+	}
+}
+
+func TestExecResolver(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test uses a shell script")
+	}
+	dir, err := ioutil.TempDir("", "env-resolve")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	script := filepath.Join(dir, "get-token")
+	if err := ioutil.WriteFile(script, []byte("#!/bin/sh\necho t0ken\n"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	m := env.Map{"TOKEN": "ref+exec://" + script}
+	got, err := m.Resolve(context.Background(), env.ExecResolver)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got["TOKEN"] != "t0ken" {
+		t.Errorf("TOKEN = %q, want %q", got["TOKEN"], "t0ken")
+	}
+}
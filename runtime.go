@@ -0,0 +1,99 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env
+
+import (
+	"net"
+	"os"
+)
+
+// RuntimeInfo describes the container or service manager environment in
+// which a process runs, as far as it can be told from its variables and,
+// for Docker, from the file system.
+type RuntimeInfo struct {
+	// Kubernetes reports whether the process runs in a Kubernetes pod.
+	Kubernetes bool
+
+	// APIServer is the address of the Kubernetes API server, from
+	// KUBERNETES_SERVICE_HOST and KUBERNETES_SERVICE_PORT.
+	APIServer string
+
+	// Pod, Namespace, Node and ServiceAccount identify the pod. They
+	// are only available if the pod specification exposes them through
+	// the downward API, using one of the conventional variable names,
+	// such as POD_NAME or MY_POD_NAME. If the pod name is not exposed,
+	// Pod defaults to HOSTNAME, which Kubernetes sets to the pod name.
+	Pod            string
+	Namespace      string
+	Node           string
+	ServiceAccount string
+
+	// Container is the container manager, from the "container" variable
+	// set by podman, LXC, systemd-nspawn and others, following the
+	// systemd container interface. Docker does not set the variable, so
+	// Container is "docker" if it is not set and the /.dockerenv file,
+	// which Docker creates in every container, exists.
+	Container string
+
+	// Systemd reports whether the process was started by systemd as
+	// part of a unit, and InvocationID identifies the invocation.
+	Systemd      bool
+	InvocationID string
+}
+
+// Conventional names of variables set through the Kubernetes downward API.
+var (
+	podNameVars        = []string{"POD_NAME", "MY_POD_NAME", "K8S_POD_NAME", "KUBERNETES_POD_NAME"}
+	podNamespaceVars   = []string{"POD_NAMESPACE", "MY_POD_NAMESPACE", "K8S_NAMESPACE", "KUBERNETES_NAMESPACE"}
+	nodeNameVars       = []string{"NODE_NAME", "MY_NODE_NAME", "K8S_NODE_NAME", "KUBERNETES_NODE_NAME"}
+	serviceAccountVars = []string{"POD_SERVICE_ACCOUNT", "MY_POD_SERVICE_ACCOUNT", "SERVICE_ACCOUNT", "K8S_SERVICE_ACCOUNT"}
+)
+
+// DetectRuntime reports what m indicates about the runtime environment of
+// the process: Kubernetes, through KUBERNETES_SERVICE_HOST, container
+// managers, through the "container" variable, and systemd, through
+// INVOCATION_ID. Docker, which sets no variables of its own, is detected
+// through the /.dockerenv file.
+func DetectRuntime(m Map) RuntimeInfo {
+	_, err := os.Stat("/.dockerenv")
+	return detectRuntime(m, err == nil)
+}
+
+// detectRuntime is like DetectRuntime, but reports whether /.dockerenv
+// exists through dockerenv.
+func detectRuntime(m Map, dockerenv bool) RuntimeInfo {
+	var ri RuntimeInfo
+	if host := m["KUBERNETES_SERVICE_HOST"]; host != "" {
+		ri.Kubernetes = true
+		if port := m["KUBERNETES_SERVICE_PORT"]; port != "" {
+			ri.APIServer = net.JoinHostPort(host, port)
+		} else {
+			ri.APIServer = host
+		}
+		ri.Pod = lookupAny(m, append(podNameVars, "HOSTNAME")...)
+		ri.Namespace = lookupAny(m, podNamespaceVars...)
+		ri.Node = lookupAny(m, nodeNameVars...)
+		ri.ServiceAccount = lookupAny(m, serviceAccountVars...)
+	}
+	ri.Container = m["container"]
+	if ri.Container == "" && dockerenv {
+		ri.Container = "docker"
+	}
+	if id := m["INVOCATION_ID"]; id != "" {
+		ri.Systemd = true
+		ri.InvocationID = id
+	}
+	return ri
+}
@@ -0,0 +1,102 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env_test
+
+import (
+	"testing"
+
+	"acln.ro/env"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDetectRuntime(t *testing.T) {
+	tests := []struct {
+		name      string
+		m         env.Map
+		dockerenv bool
+		want      env.RuntimeInfo
+	}{
+		{
+			name: "None",
+			m:    env.Map{"HOME": "/root"},
+		},
+		{
+			name: "Kubernetes",
+			m: env.Map{
+				"KUBERNETES_SERVICE_HOST": "10.0.0.1",
+				"KUBERNETES_SERVICE_PORT": "443",
+				"HOSTNAME":                "web-7d4b9-xk2lp",
+				"MY_POD_NAMESPACE":        "prod",
+				"NODE_NAME":               "node-3",
+				"POD_SERVICE_ACCOUNT":     "web",
+			},
+			want: env.RuntimeInfo{
+				Kubernetes:     true,
+				APIServer:      "10.0.0.1:443",
+				Pod:            "web-7d4b9-xk2lp",
+				Namespace:      "prod",
+				Node:           "node-3",
+				ServiceAccount: "web",
+			},
+		},
+		{
+			name: "KubernetesIPv6",
+			m: env.Map{
+				"KUBERNETES_SERVICE_HOST": "fd00::1",
+				"KUBERNETES_SERVICE_PORT": "443",
+				"POD_NAME":                "web-0",
+				"HOSTNAME":                "ignored",
+			},
+			want: env.RuntimeInfo{
+				Kubernetes: true,
+				APIServer:  "[fd00::1]:443",
+				Pod:        "web-0",
+			},
+		},
+		{
+			name: "SystemdPodman",
+			m: env.Map{
+				"container":     "podman",
+				"INVOCATION_ID": "0123abcd",
+			},
+			want: env.RuntimeInfo{
+				Container:    "podman",
+				Systemd:      true,
+				InvocationID: "0123abcd",
+			},
+		},
+		{
+			name:      "Docker",
+			m:         env.Map{"HOSTNAME": "3f4e8a2b1c0d"},
+			dockerenv: true,
+			want:      env.RuntimeInfo{Container: "docker"},
+		},
+		{
+			name:      "DockerContainerVariable",
+			m:         env.Map{"container": "podman"},
+			dockerenv: true,
+			want:      env.RuntimeInfo{Container: "podman"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := env.DetectRuntimeWith(tt.m, tt.dockerenv)
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Errorf("got %+v, want %+v: %s", got, tt.want, diff)
+			}
+		})
+	}
+}
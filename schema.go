@@ -0,0 +1,288 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// A Schema describes the variables an environment is expected to hold.
+// Schemas can be encoded to and decoded from JSON.
+//
+// A Schema may evolve over time. Version identifies the current version
+// of the schema, and Migrations describe how to upgrade environments
+// conforming to earlier versions.
+type Schema struct {
+	Version    int         `json:"version,omitempty"`
+	Vars       []Var       `json:"vars"`
+	Migrations []Migration `json:"migrations,omitempty"`
+
+	// Checks validate relationships between variables. They cannot be
+	// encoded to JSON.
+	Checks []Check `json:"-"`
+}
+
+// Var describes a variable in a Schema.
+type Var struct {
+	Key         string `json:"key"`
+	Type        Type   `json:"type,omitempty"`
+	Required    bool   `json:"required,omitempty"`
+	Default     string `json:"default,omitempty"`
+	Secret      bool   `json:"secret,omitempty"`
+	Description string `json:"description,omitempty"`
+
+	// RequiredIf and RequiredUnless make the variable conditionally
+	// required: it is required if any condition in RequiredIf holds,
+	// or unless some condition in RequiredUnless does.
+	RequiredIf     []Condition `json:"required_if,omitempty"`
+	RequiredUnless []Condition `json:"required_unless,omitempty"`
+
+	// Constraints on the value, checked after the type. Pattern is a
+	// regular expression, in the syntax of the regexp package, which
+	// must match the whole value. Min, Max and Step constrain values of
+	// type Int, Float and Duration, and are written as such values. If
+	// Step is set, the value must differ from Min, or from zero if Min
+	// is not set, by a multiple of Step.
+	Enum    []string        `json:"enum,omitempty"`
+	Pattern string          `json:"pattern,omitempty"`
+	Min     string          `json:"min,omitempty"`
+	Max     string          `json:"max,omitempty"`
+	Step    string          `json:"step,omitempty"`
+	Path    *PathConstraint `json:"path,omitempty"`
+	URL     *URLConstraint  `json:"url,omitempty"`
+
+	rules *varRules // compiled constraints, set by Schema.Check
+}
+
+// Type is the type of the value of a variable.
+type Type string
+
+// Supported types. The empty Type is equivalent to String.
+const (
+	String   Type = "string"
+	Int      Type = "int"
+	Float    Type = "float"
+	Bool     Type = "bool"
+	Duration Type = "duration"
+	URLType  Type = "url"
+
+	// Network addresses, as accepted by ParseHostPort and
+	// ParseListenAddr.
+	HostPortType Type = "hostport"
+	ListenAddr   Type = "listen"
+)
+
+// check checks that s is a valid value of type t.
+func (t Type) check(s string) error {
+	var err error
+	switch t {
+	case "", String:
+	case Int:
+		_, err = strconv.ParseInt(s, 10, 64)
+	case Float:
+		_, err = strconv.ParseFloat(s, 64)
+	case Bool:
+		_, err = strconv.ParseBool(s)
+	case Duration:
+		_, err = time.ParseDuration(s)
+	case URLType:
+		_, err = url.Parse(s)
+	case HostPortType:
+		_, err = ParseHostPort(s)
+	case ListenAddr:
+		_, err = ParseListenAddr(s)
+	default:
+		return fmt.Errorf("unknown type %q", string(t))
+	}
+	if err != nil {
+		return fmt.Errorf("value is not a valid %s", string(t))
+	}
+	return nil
+}
+
+// Validate checks m against the schema. It reports an error if a required
+// variable is not set, if the value of a variable is not valid for its
+// type, or does not satisfy its constraints, or if one of the Checks
+// fails. Variables with default values are considered set. Variables not
+// described by the schema are ignored. Malformed constraints in a schema
+// which was not checked by Check are reported for each variable, whether
+// it is set or not.
+//
+// All problems are reported, as an ErrorList of *ValidationError. If a
+// required variable is not set, its error wraps ErrNotFound.
+func (s *Schema) Validate(m Map) error {
+	var errs ErrorList
+	am := s.Apply(m)
+	for i := range s.Vars {
+		v := &s.Vars[i]
+		r, err := v.compiled()
+		if err != nil {
+			errs = append(errs, invalid(v.Key, err))
+			continue
+		}
+		val, ok := m[v.Key]
+		if !ok && v.Default != "" {
+			val, ok = v.Default, true
+		}
+		if !ok {
+			if err := v.notSet(am); err != nil {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		if err := v.checkWith(r, val); err != nil {
+			errs = append(errs, invalid(v.Key, err))
+		}
+	}
+	for _, c := range s.Checks {
+		if err := c.run(am); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs.err()
+}
+
+// Check checks that the constraints in the schema are well-formed, and
+// compiles them, such that Validate and Apply do not compile patterns or
+// parse ranges on every call. All problems are reported, as an ErrorList.
+//
+// Schemas decoded from JSON are checked as they are decoded. Schemas
+// built in Go should be checked once, after they are built, and before
+// they are used concurrently.
+func (s *Schema) Check() error {
+	var errs ErrorList
+	for i := range s.Vars {
+		v := &s.Vars[i]
+		if err := v.compile(); err != nil {
+			errs = append(errs, fmt.Errorf("env: %s: %v", v.Key, err))
+		}
+	}
+	return errs.err()
+}
+
+// MustValidate is like Validate, but panics if validation fails. It is
+// intended for use during program initialization.
+func (s *Schema) MustValidate(m Map) {
+	if err := s.Validate(m); err != nil {
+		panic(err)
+	}
+}
+
+// Apply returns a copy of m, with default values from the schema filled
+// in for variables which are not set, and valid values normalized as
+// required by their constraints.
+func (s *Schema) Apply(m Map) Map {
+	am := Merge(m)
+	for i := range s.Vars {
+		v := &s.Vars[i]
+		val, ok := am[v.Key]
+		if !ok && v.Default != "" {
+			val, ok = v.Default, true
+		}
+		if !ok {
+			continue
+		}
+		if v.check(val) == nil {
+			val = v.normalize(val)
+		}
+		am[v.Key] = val
+	}
+	return am
+}
+
+// A Migration upgrades an environment from version From of a Schema to
+// version From+1. The changes described by Rename, then Remove, then Func
+// are applied in order.
+type Migration struct {
+	From int `json:"from"`
+
+	// Rename maps old variable names to new ones. If the new variable
+	// is already set, the old one is removed, and its value discarded.
+	Rename map[string]string `json:"rename,omitempty"`
+
+	// Remove lists variables which are no longer used.
+	Remove []string `json:"remove,omitempty"`
+
+	// Func, if not nil, makes arbitrary changes, such as splitting a
+	// variable into several, or changing the format of a value. It
+	// cannot be encoded to JSON.
+	Func func(tx *Tx) error `json:"-"`
+}
+
+// apply applies the migration to tx.
+func (mig *Migration) apply(tx *Tx) error {
+	olds := make([]string, 0, len(mig.Rename))
+	for old := range mig.Rename {
+		olds = append(olds, old)
+	}
+	sort.Strings(olds)
+	for _, old := range olds {
+		v, ok := tx.Lookup(old)
+		if !ok {
+			continue
+		}
+		if _, ok := tx.Lookup(mig.Rename[old]); !ok {
+			tx.Set(mig.Rename[old], v)
+		}
+		tx.Unset(old)
+	}
+	for _, k := range mig.Remove {
+		if _, ok := tx.Lookup(k); ok {
+			tx.Unset(k)
+		}
+	}
+	if mig.Func != nil {
+		return mig.Func(tx)
+	}
+	return nil
+}
+
+// Migrate upgrades m, an environment conforming to version from of the
+// schema, to the current version, by applying the migrations in sequence.
+// It returns the upgraded environment, and the changes it made, in order.
+// m is not modified.
+//
+// Migrate fails if from is newer than the current version, if a migration
+// is missing, or if a migration function fails.
+func (s *Schema) Migrate(m Map, from int) (Map, Changeset, error) {
+	if from > s.Version {
+		return nil, nil, fmt.Errorf("env: cannot migrate from version %d to older version %d", from, s.Version)
+	}
+	byVersion := make(map[int]*Migration, len(s.Migrations))
+	for i := range s.Migrations {
+		byVersion[s.Migrations[i].From] = &s.Migrations[i]
+	}
+	migrated := Merge(m)
+	tx := migrated.Begin()
+	defer tx.Rollback()
+	for v := from; v < s.Version; v++ {
+		mig, ok := byVersion[v]
+		if !ok {
+			return nil, nil, fmt.Errorf("env: no migration from version %d to %d", v, v+1)
+		}
+		if err := mig.apply(tx); err != nil {
+			return nil, nil, fmt.Errorf("env: migrating from version %d to %d: %v", v, v+1, err)
+		}
+	}
+	changes := tx.Changes()
+	if err := tx.Commit(); err != nil {
+		return nil, nil, err
+	}
+	return migrated, changes, nil
+}
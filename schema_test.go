@@ -0,0 +1,219 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"acln.ro/env"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+var testSchema = &env.Schema{
+	Vars: []env.Var{
+		{Key: "DATABASE_URL", Type: env.URLType, Required: true},
+		{Key: "PORT", Type: env.Int, Default: "8080"},
+		{Key: "DEBUG", Type: env.Bool},
+	},
+}
+
+func TestSchemaValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		m    env.Map
+		want string
+	}{
+		{
+			name: "OK",
+			m:    env.Map{"DATABASE_URL": "postgres://db"},
+		},
+		{
+			name: "Missing",
+			m:    env.Map{"PORT": "80"},
+			want: "DATABASE_URL",
+		},
+		{
+			name: "BadType",
+			m:    env.Map{"DATABASE_URL": "postgres://db", "DEBUG": "maybe"},
+			want: "DEBUG",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := testSchema.Validate(tt.m)
+			if tt.want == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got error %v, want error naming %s", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSchemaApply(t *testing.T) {
+	m := env.Map{"DATABASE_URL": "postgres://db"}
+	got := testSchema.Apply(m)
+	want := env.Map{"DATABASE_URL": "postgres://db", "PORT": "8080"}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("Apply: %s", diff)
+	}
+	if _, ok := m["PORT"]; ok {
+		t.Error("Apply modified its argument")
+	}
+}
+
+func TestSchemaCheck(t *testing.T) {
+	schema := &env.Schema{Vars: []env.Var{
+		{Key: "REGION", Pattern: "[a-z"},
+		{Key: "PORT", Type: env.Int, Min: "10", Max: "1"},
+		{Key: "NAME", Pattern: "[a-z]+"},
+	}}
+
+	// Unchecked schemas report malformed constraints on every use,
+	// whether the variable is set or not.
+	err := schema.Validate(env.Map{"NAME": "app"})
+	var errs env.ErrorList
+	if !errors.As(err, &errs) || len(errs) != 2 {
+		t.Fatalf("Validate: got %v, want 2 errors", err)
+	}
+
+	err = schema.Check()
+	if !errors.As(err, &errs) || len(errs) != 2 {
+		t.Fatalf("Check: got %v, want 2 errors", err)
+	}
+	for _, key := range []string{"REGION", "PORT"} {
+		if !strings.Contains(err.Error(), key) {
+			t.Errorf("Check: got %v, want error naming %s", err, key)
+		}
+	}
+
+	schema.Vars = schema.Vars[2:]
+	if err := schema.Check(); err != nil {
+		t.Fatal(err)
+	}
+	if err := schema.Validate(env.Map{"NAME": "App"}); err == nil {
+		t.Error("Validate accepted value not matching the checked pattern")
+	}
+	schema.Vars[0].Pattern = "[A-Za-z]+"
+	if err := schema.Validate(env.Map{"NAME": "App"}); err != nil {
+		t.Errorf("Validate after changing the pattern: %v", err)
+	}
+}
+
+func TestMustValidate(t *testing.T) {
+	defer func() {
+		if r := recover(); !strings.Contains(fmt.Sprint(r), "DATABASE_URL") {
+			t.Errorf("recovered %v, want error naming DATABASE_URL", r)
+		}
+	}()
+	testSchema.MustValidate(env.Map{})
+}
+
+func TestSchemaMigrate(t *testing.T) {
+	schema := &env.Schema{
+		Version: 3,
+		Vars: []env.Var{
+			{Key: "DB_HOST"},
+			{Key: "DB_PORT"},
+			{Key: "TIMEOUT", Type: env.Duration},
+		},
+		Migrations: []env.Migration{
+			{
+				From:   1,
+				Rename: map[string]string{"DATABASE_ADDR": "DB_ADDR"},
+				Remove: []string{"LEGACY_MODE"},
+			},
+			{
+				From: 2,
+				Func: func(tx *env.Tx) error {
+					if addr, ok := tx.Lookup("DB_ADDR"); ok {
+						i := strings.LastIndexByte(addr, ':')
+						tx.Set("DB_HOST", addr[:i])
+						tx.Set("DB_PORT", addr[i+1:])
+						tx.Unset("DB_ADDR")
+					}
+					if secs, ok := tx.Lookup("TIMEOUT"); ok {
+						tx.Set("TIMEOUT", secs+"s")
+					}
+					return nil
+				},
+			},
+		},
+	}
+	m := env.Map{
+		"DATABASE_ADDR": "db:5432",
+		"LEGACY_MODE":   "1",
+		"TIMEOUT":       "30",
+	}
+	got, changes, err := schema.Migrate(m, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := env.Map{"DB_HOST": "db", "DB_PORT": "5432", "TIMEOUT": "30s"}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("Migrate: %s", diff)
+	}
+	wantChanges := env.Changeset{
+		{Key: "DB_ADDR", Value: "db:5432"},
+		{Unset: true, Key: "DATABASE_ADDR"},
+		{Unset: true, Key: "LEGACY_MODE"},
+		{Key: "DB_HOST", Value: "db"},
+		{Key: "DB_PORT", Value: "5432"},
+		{Unset: true, Key: "DB_ADDR"},
+		{Key: "TIMEOUT", Value: "30s"},
+	}
+	if diff := cmp.Diff(changes, wantChanges); diff != "" {
+		t.Errorf("changes: %s", diff)
+	}
+	if m["DATABASE_ADDR"] != "db:5432" {
+		t.Error("Migrate modified its argument")
+	}
+	if err := schema.Validate(got); err != nil {
+		t.Error(err)
+	}
+
+	if _, _, err := schema.Migrate(m, 0); err == nil {
+		t.Error("Migrate from version without migration succeeded")
+	}
+	if _, _, err := schema.Migrate(m, 4); err == nil {
+		t.Error("Migrate from newer version succeeded")
+	}
+	same, changes, err := schema.Migrate(want, 3)
+	if err != nil || len(changes) != 0 || !cmp.Equal(same, want) {
+		t.Errorf("Migrate from current version: %v, %v, %v", same, changes, err)
+	}
+}
+
+func TestSchemaMigrateRenameConflict(t *testing.T) {
+	schema := &env.Schema{
+		Version:    2,
+		Migrations: []env.Migration{{From: 1, Rename: map[string]string{"OLD": "NEW"}}},
+	}
+	got, _, err := schema.Migrate(env.Map{"OLD": "a", "NEW": "b"}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(got, env.Map{"NEW": "b"}); diff != "" {
+		t.Errorf("Migrate: %s", diff)
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Secret holds a secret value, such as a password or an access token.
+// Its String, Format and MarshalJSON methods redact the value, so that it
+// does not leak into logs or dumps by accident. The zero Secret is empty.
+type Secret struct {
+	b []byte
+}
+
+// NewSecret returns a Secret holding s.
+func NewSecret(s string) Secret {
+	return Secret{b: []byte(s)}
+}
+
+// Reveal returns the secret value.
+func (s Secret) Reveal() string {
+	return string(s.b)
+}
+
+// Equal reports whether s and t hold the same value. The comparison takes
+// time independent of the contents of the values.
+func (s Secret) Equal(t Secret) bool {
+	return subtle.ConstantTimeCompare(s.b, t.b) == 1
+}
+
+// Zero overwrites the memory holding the secret value with zeros. Zeroing
+// is best effort: copies of the value obtained through Reveal, or made
+// by the runtime, are not affected.
+//
+// Copies of s share its memory, and are therefore zeroed as well.
+func (s Secret) Zero() {
+	for i := range s.b {
+		s.b[i] = 0
+	}
+}
+
+// String returns a redacted representation of s.
+func (s Secret) String() string {
+	return redacted
+}
+
+// GoString returns a redacted representation of s.
+func (s Secret) GoString() string {
+	return "env.Secret(" + strconv.Quote(redacted) + ")"
+}
+
+// Format implements fmt.Formatter, such that all formatting verbs produce
+// a redacted representation of s.
+func (s Secret) Format(f fmt.State, verb rune) {
+	if verb == 'v' && f.Flag('#') {
+		fmt.Fprint(f, s.GoString())
+		return
+	}
+	fmt.Fprint(f, redacted)
+}
+
+// MarshalJSON marshals s as a redacted JSON string.
+func (s Secret) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(redacted)), nil
+}
+
+// Secret returns the value of the variable named by key as a Secret.
+func (m Map) Secret(key string) Secret {
+	v, ok := m[key]
+	if !ok {
+		return Secret{}
+	}
+	return NewSecret(v)
+}
+
+// A Policy reports whether the variable named by key holds a secret value.
+type Policy func(key string) bool
+
+// SecretKeys returns a Policy which marks the specified keys as secret.
+func SecretKeys(keys ...string) Policy {
+	set := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		set[k] = true
+	}
+	return func(key string) bool {
+		return set[key]
+	}
+}
+
+// DefaultPolicy marks variables as secret based on their names. Names
+// containing words such as PASSWORD, SECRET, TOKEN or CREDENTIAL, or
+// ending in KEY, as in API_KEY, are considered secret. The comparison is
+// case insensitive.
+var DefaultPolicy Policy = secretName
+
+var secretWords = []string{
+	"PASSWORD",
+	"PASSWD",
+	"PASSPHRASE",
+	"SECRET",
+	"TOKEN",
+	"CREDENTIAL",
+	"PRIVATE",
+	"API_KEY",
+	"APIKEY",
+}
+
+func secretName(key string) bool {
+	key = strings.ToUpper(key)
+	for _, w := range secretWords {
+		if strings.Contains(key, w) {
+			return true
+		}
+	}
+	return strings.HasSuffix(key, "_KEY") || strings.HasSuffix(key, "_PASS") || strings.HasSuffix(key, "_PWD")
+}
+
+// Redact returns a copy of m in which the values of variables marked as
+// secret by p are redacted.
+func (m Map) Redact(p Policy) Map {
+	rm := make(Map, len(m))
+	for k, v := range m {
+		if p(k) {
+			v = redacted
+		}
+		rm[k] = v
+	}
+	return rm
+}
@@ -0,0 +1,109 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"acln.ro/env"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSecretRedaction(t *testing.T) {
+	s := env.NewSecret("hunter2")
+	for _, format := range []string{"%v", "%+v", "%s", "%q", "%x", "%d"} {
+		if got := fmt.Sprintf(format, s); got != "xxxxx" {
+			t.Errorf("formatting Secret with %s: got %q", format, got)
+		}
+	}
+	if got, want := fmt.Sprintf("%#v", s), `env.Secret("xxxxx")`; got != want {
+		t.Errorf("formatting Secret with %%#v: got %q, want %q", got, want)
+	}
+	b, err := json.Marshal(struct{ Password env.Secret }{s})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(b), `{"Password":"xxxxx"}`; got != want {
+		t.Errorf("json.Marshal: got %s, want %s", got, want)
+	}
+	if got := s.Reveal(); got != "hunter2" {
+		t.Errorf("Reveal() = %q, want %q", got, "hunter2")
+	}
+}
+
+func TestSecretEqual(t *testing.T) {
+	a := env.NewSecret("hunter2")
+	tests := []struct {
+		b    env.Secret
+		want bool
+	}{
+		{env.NewSecret("hunter2"), true},
+		{env.NewSecret("hunter3"), false},
+		{env.NewSecret("hunter"), false},
+		{env.Secret{}, false},
+	}
+	for _, tt := range tests {
+		if got := a.Equal(tt.b); got != tt.want {
+			t.Errorf("Equal(%q) = %t, want %t", tt.b.Reveal(), got, tt.want)
+		}
+	}
+}
+
+func TestSecretZero(t *testing.T) {
+	s := env.Map{"TOKEN": "t0ken"}.Secret("TOKEN")
+	s.Zero()
+	if got, want := s.Reveal(), "\x00\x00\x00\x00\x00"; got != want {
+		t.Errorf("after Zero: Reveal() = %q, want %q", got, want)
+	}
+}
+
+func TestRedact(t *testing.T) {
+	m := env.Map{"USER": "app", "PASSWORD": "hunter2", "TOKEN": "t0ken"}
+	got := m.Redact(env.SecretKeys("PASSWORD", "TOKEN"))
+	want := env.Map{"USER": "app", "PASSWORD": "xxxxx", "TOKEN": "xxxxx"}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("Redact: got %v, want %v: %s", got, want, diff)
+	}
+	if m["PASSWORD"] != "hunter2" {
+		t.Error("Redact modified its receiver")
+	}
+}
+
+func TestDefaultPolicy(t *testing.T) {
+	tests := []struct {
+		key  string
+		want bool
+	}{
+		{"PATH", false},
+		{"HOME", false},
+		{"KEYBOARD_LAYOUT", false},
+		{"DB_PASSWORD", true},
+		{"db_password", true},
+		{"AWS_SECRET_ACCESS_KEY", true},
+		{"GITHUB_TOKEN", true},
+		{"STRIPE_API_KEY", true},
+		{"SIGNING_KEY", true},
+		{"SMTP_PASS", true},
+		{"GOOGLE_APPLICATION_CREDENTIALS", true},
+	}
+	for _, tt := range tests {
+		if got := env.DefaultPolicy(tt.key); got != tt.want {
+			t.Errorf("DefaultPolicy(%q) = %t, want %t", tt.key, got, tt.want)
+		}
+	}
+}
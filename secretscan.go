@@ -0,0 +1,189 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"math"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// SecretKind classifies the evidence that a variable holds a secret.
+type SecretKind string
+
+// Kinds of evidence reported by FindLikelySecrets.
+const (
+	SecretByName      SecretKind = "name"         // the name suggests a secret
+	SecretPrivateKey  SecretKind = "private-key"  // a PEM-encoded private key
+	SecretJWT         SecretKind = "jwt"          // a JSON Web Token
+	SecretAWSKey      SecretKind = "aws-key"      // an AWS access key ID
+	SecretToken       SecretKind = "token"        // a token with a well-known prefix
+	SecretURLPassword SecretKind = "url-password" // a URL with a password
+	SecretEntropy     SecretKind = "entropy"      // a random-looking value
+)
+
+// A Finding reports a variable which likely holds a secret.
+type Finding struct {
+	Key    string
+	Kind   SecretKind
+	Reason string
+}
+
+func (f Finding) String() string {
+	return f.Key + ": " + f.Reason
+}
+
+// FindLikelySecrets returns findings for the variables in m which likely
+// hold secrets, sorted by key. Values are examined for the formats of
+// private keys, JSON Web Tokens, AWS access keys, well-known API tokens
+// and URLs with passwords, and for random-looking strings. Variables the
+// values of which match none of these are reported if DefaultPolicy
+// considers their names secret, and their values are not empty. At most
+// one finding is reported per variable, and findings never include
+// values.
+func FindLikelySecrets(m Map) []Finding {
+	var findings []Finding
+	for _, k := range m.keys() {
+		v := m[k]
+		if kind, reason := secretValue(v); kind != "" {
+			findings = append(findings, Finding{Key: k, Kind: kind, Reason: reason})
+			continue
+		}
+		if v != "" && secretName(k) {
+			findings = append(findings, Finding{Key: k, Kind: SecretByName, Reason: "name suggests a secret"})
+		}
+	}
+	return findings
+}
+
+var (
+	jwtPattern    = regexp.MustCompile(`^eyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*$`)
+	awsKeyPattern = regexp.MustCompile(`^(AKIA|ASIA|AGPA|AIDA|AROA)[0-9A-Z]{16}$`)
+)
+
+// tokenPrefixes maps prefixes of well-known API tokens to their issuers.
+var tokenPrefixes = []struct{ prefix, issuer string }{
+	{"ghp_", "GitHub"},
+	{"gho_", "GitHub"},
+	{"ghs_", "GitHub"},
+	{"ghu_", "GitHub"},
+	{"github_pat_", "GitHub"},
+	{"glpat-", "GitLab"},
+	{"xoxb-", "Slack"},
+	{"xoxp-", "Slack"},
+	{"xoxa-", "Slack"},
+	{"sk_live_", "Stripe"},
+	{"rk_live_", "Stripe"},
+	{"AIza", "Google API"},
+	{"npm_", "npm"},
+}
+
+// secretValue reports whether v looks like a secret, and why.
+func secretValue(v string) (SecretKind, string) {
+	switch {
+	case strings.Contains(v, "-----BEGIN ") && strings.Contains(v, "PRIVATE KEY-----"):
+		return SecretPrivateKey, "value is a PEM-encoded private key"
+	case isJWT(v):
+		return SecretJWT, "value is a JSON Web Token"
+	case awsKeyPattern.MatchString(v):
+		return SecretAWSKey, "value is an AWS access key ID"
+	}
+	for _, t := range tokenPrefixes {
+		if strings.HasPrefix(v, t.prefix) && len(v) >= len(t.prefix)+16 && !strings.ContainsAny(v, " \t\n") {
+			return SecretToken, "value looks like a " + t.issuer + " token"
+		}
+	}
+	if u, err := url.Parse(v); err == nil && u.Scheme != "" && u.User != nil {
+		if p, ok := u.User.Password(); ok && p != "" {
+			return SecretURLPassword, "value is a URL which contains a password"
+		}
+	}
+	if isRandom(v) {
+		return SecretEntropy, "value looks random, like a key or token"
+	}
+	return "", ""
+}
+
+// isJWT reports whether v is a JSON Web Token, the header of which names
+// a signing algorithm.
+func isJWT(v string) bool {
+	if !jwtPattern.MatchString(v) {
+		return false
+	}
+	header, err := base64.RawURLEncoding.DecodeString(v[:strings.IndexByte(v, '.')])
+	if err != nil {
+		return false
+	}
+	var h struct {
+		Alg string `json:"alg"`
+	}
+	return json.Unmarshal(header, &h) == nil && h.Alg != ""
+}
+
+// isRandom reports whether v looks like randomly generated key material:
+// a long hexadecimal or base64 string with high entropy, which contains
+// both letters and digits.
+func isRandom(v string) bool {
+	const minLen = 20
+	if len(v) < minLen {
+		return false
+	}
+	hex := true
+	var letters, digits bool
+	for i := 0; i < len(v); i++ {
+		c := v[i]
+		switch {
+		case '0' <= c && c <= '9':
+			digits = true
+		case 'a' <= c && c <= 'f', 'A' <= c && c <= 'F':
+			letters = true
+		case 'g' <= c && c <= 'z', 'G' <= c && c <= 'Z':
+			letters = true
+			hex = false
+		case c == '+' || c == '/' || c == '=' || c == '-' || c == '_':
+			hex = false
+		default:
+			return false
+		}
+	}
+	if !letters || !digits || strings.HasPrefix(v, "/") {
+		return false
+	}
+	if hex {
+		return len(v) >= 32 && entropy(v) >= 3.0
+	}
+	return entropy(v) >= 4.0
+}
+
+// entropy returns the Shannon entropy of s, in bits per byte.
+func entropy(s string) float64 {
+	var counts [256]int
+	for i := 0; i < len(s); i++ {
+		counts[s[i]]++
+	}
+	var h float64
+	n := float64(len(s))
+	for _, c := range counts {
+		if c == 0 {
+			continue
+		}
+		p := float64(c) / n
+		h -= p * math.Log2(p)
+	}
+	return h
+}
@@ -0,0 +1,128 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env
+
+import "strconv"
+
+// LambdaEnv is a typed view of the environment variables set by the AWS
+// Lambda runtime.
+type LambdaEnv struct {
+	FunctionName    string // AWS_LAMBDA_FUNCTION_NAME
+	FunctionVersion string // AWS_LAMBDA_FUNCTION_VERSION
+	Handler         string // _HANDLER
+	MemorySize      int    // AWS_LAMBDA_FUNCTION_MEMORY_SIZE, in MB
+	Region          string // AWS_REGION
+	Runtime         string // AWS_EXECUTION_ENV
+	RuntimeAPI      string // AWS_LAMBDA_RUNTIME_API
+	LogGroup        string // AWS_LAMBDA_LOG_GROUP_NAME
+	LogStream       string // AWS_LAMBDA_LOG_STREAM_NAME
+	TaskRoot        string // LAMBDA_TASK_ROOT
+	InitType        string // AWS_LAMBDA_INITIALIZATION_TYPE
+}
+
+// Lambda returns the AWS Lambda runtime information described by m. It
+// reports false if m does not describe a Lambda function, as indicated by
+// AWS_LAMBDA_FUNCTION_NAME.
+func Lambda(m Map) (LambdaEnv, bool) {
+	name := m["AWS_LAMBDA_FUNCTION_NAME"]
+	if name == "" {
+		return LambdaEnv{}, false
+	}
+	return LambdaEnv{
+		FunctionName:    name,
+		FunctionVersion: m["AWS_LAMBDA_FUNCTION_VERSION"],
+		Handler:         m["_HANDLER"],
+		MemorySize:      atoi(m["AWS_LAMBDA_FUNCTION_MEMORY_SIZE"]),
+		Region:          m["AWS_REGION"],
+		Runtime:         m["AWS_EXECUTION_ENV"],
+		RuntimeAPI:      m["AWS_LAMBDA_RUNTIME_API"],
+		LogGroup:        m["AWS_LAMBDA_LOG_GROUP_NAME"],
+		LogStream:       m["AWS_LAMBDA_LOG_STREAM_NAME"],
+		TaskRoot:        m["LAMBDA_TASK_ROOT"],
+		InitType:        m["AWS_LAMBDA_INITIALIZATION_TYPE"],
+	}, true
+}
+
+// CloudRunEnv is a typed view of the environment variables set by Google
+// Cloud Run, for services and for jobs.
+type CloudRunEnv struct {
+	Port          int    // PORT, the port to listen on
+	Service       string // K_SERVICE
+	Revision      string // K_REVISION
+	Configuration string // K_CONFIGURATION
+	Job           string // CLOUD_RUN_JOB
+	Execution     string // CLOUD_RUN_EXECUTION
+	TaskIndex     int    // CLOUD_RUN_TASK_INDEX
+	TaskCount     int    // CLOUD_RUN_TASK_COUNT
+	TaskAttempt   int    // CLOUD_RUN_TASK_ATTEMPT
+}
+
+// CloudRun returns the Cloud Run runtime information described by m. It
+// reports false if m describes neither a Cloud Run service, as indicated
+// by K_SERVICE, nor a Cloud Run job, as indicated by CLOUD_RUN_JOB.
+func CloudRun(m Map) (CloudRunEnv, bool) {
+	if m["K_SERVICE"] == "" && m["CLOUD_RUN_JOB"] == "" {
+		return CloudRunEnv{}, false
+	}
+	return CloudRunEnv{
+		Port:          atoi(m["PORT"]),
+		Service:       m["K_SERVICE"],
+		Revision:      m["K_REVISION"],
+		Configuration: m["K_CONFIGURATION"],
+		Job:           m["CLOUD_RUN_JOB"],
+		Execution:     m["CLOUD_RUN_EXECUTION"],
+		TaskIndex:     atoi(m["CLOUD_RUN_TASK_INDEX"]),
+		TaskCount:     atoi(m["CLOUD_RUN_TASK_COUNT"]),
+		TaskAttempt:   atoi(m["CLOUD_RUN_TASK_ATTEMPT"]),
+	}, true
+}
+
+// AzureFunctionsEnv is a typed view of the environment variables set by
+// the Azure Functions host.
+type AzureFunctionsEnv struct {
+	SiteName          string // WEBSITE_SITE_NAME, the function app
+	HostName          string // WEBSITE_HOSTNAME
+	InstanceID        string // WEBSITE_INSTANCE_ID
+	ResourceGroup     string // WEBSITE_RESOURCE_GROUP
+	Region            string // REGION_NAME
+	WorkerRuntime     string // FUNCTIONS_WORKER_RUNTIME
+	ExtensionVersion  string // FUNCTIONS_EXTENSION_VERSION
+	CustomHandlerPort int    // FUNCTIONS_CUSTOMHANDLER_PORT
+}
+
+// AzureFunctions returns the Azure Functions runtime information described
+// by m. It reports false if m does not describe a function app, as
+// indicated by FUNCTIONS_WORKER_RUNTIME and FUNCTIONS_EXTENSION_VERSION.
+func AzureFunctions(m Map) (AzureFunctionsEnv, bool) {
+	if m["FUNCTIONS_WORKER_RUNTIME"] == "" && m["FUNCTIONS_EXTENSION_VERSION"] == "" {
+		return AzureFunctionsEnv{}, false
+	}
+	return AzureFunctionsEnv{
+		SiteName:          m["WEBSITE_SITE_NAME"],
+		HostName:          m["WEBSITE_HOSTNAME"],
+		InstanceID:        m["WEBSITE_INSTANCE_ID"],
+		ResourceGroup:     m["WEBSITE_RESOURCE_GROUP"],
+		Region:            m["REGION_NAME"],
+		WorkerRuntime:     m["FUNCTIONS_WORKER_RUNTIME"],
+		ExtensionVersion:  m["FUNCTIONS_EXTENSION_VERSION"],
+		CustomHandlerPort: atoi(m["FUNCTIONS_CUSTOMHANDLER_PORT"]),
+	}, true
+}
+
+// atoi parses s as a decimal integer, returning 0 if s is not one.
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
@@ -0,0 +1,96 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env_test
+
+import (
+	"testing"
+
+	"acln.ro/env"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLambda(t *testing.T) {
+	if _, ok := env.Lambda(env.Map{"AWS_REGION": "eu-west-1"}); ok {
+		t.Error("Lambda reported ok for non-Lambda environment")
+	}
+	m := env.Map{
+		"AWS_LAMBDA_FUNCTION_NAME":        "resize",
+		"AWS_LAMBDA_FUNCTION_VERSION":     "$LATEST",
+		"AWS_LAMBDA_FUNCTION_MEMORY_SIZE": "512",
+		"_HANDLER":                        "main",
+		"AWS_REGION":                      "eu-west-1",
+		"AWS_EXECUTION_ENV":               "AWS_Lambda_go1.x",
+	}
+	got, ok := env.Lambda(m)
+	if !ok {
+		t.Fatal("Lambda reported false")
+	}
+	want := env.LambdaEnv{
+		FunctionName:    "resize",
+		FunctionVersion: "$LATEST",
+		Handler:         "main",
+		MemorySize:      512,
+		Region:          "eu-west-1",
+		Runtime:         "AWS_Lambda_go1.x",
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("got %+v, want %+v: %s", got, want, diff)
+	}
+}
+
+func TestCloudRun(t *testing.T) {
+	if _, ok := env.CloudRun(env.Map{"PORT": "8080"}); ok {
+		t.Error("CloudRun reported ok for non-Cloud Run environment")
+	}
+	got, ok := env.CloudRun(env.Map{
+		"PORT":       "8080",
+		"K_SERVICE":  "api",
+		"K_REVISION": "api-00042-xyz",
+	})
+	want := env.CloudRunEnv{Port: 8080, Service: "api", Revision: "api-00042-xyz"}
+	if !ok {
+		t.Fatal("CloudRun reported false for service")
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("got %+v, want %+v: %s", got, want, diff)
+	}
+	job, ok := env.CloudRun(env.Map{"CLOUD_RUN_JOB": "nightly", "CLOUD_RUN_TASK_INDEX": "3"})
+	if !ok || job.Job != "nightly" || job.TaskIndex != 3 {
+		t.Errorf("job: got %+v, %t", job, ok)
+	}
+}
+
+func TestAzureFunctions(t *testing.T) {
+	if _, ok := env.AzureFunctions(env.Map{"WEBSITE_SITE_NAME": "app"}); ok {
+		t.Error("AzureFunctions reported ok for non-Functions environment")
+	}
+	got, ok := env.AzureFunctions(env.Map{
+		"WEBSITE_SITE_NAME":            "app",
+		"FUNCTIONS_WORKER_RUNTIME":     "custom",
+		"FUNCTIONS_CUSTOMHANDLER_PORT": "7071",
+	})
+	want := env.AzureFunctionsEnv{
+		SiteName:          "app",
+		WorkerRuntime:     "custom",
+		CustomHandlerPort: 7071,
+	}
+	if !ok {
+		t.Fatal("AzureFunctions reported false")
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("got %+v, want %+v: %s", got, want, diff)
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+)
+
+// SourceScript runs shell, a POSIX compatible shell such as /bin/sh or
+// bash, and sources the script at the specified path in it, in the manner
+// of the "." shell builtin. It captures the resulting environment using
+// "env -0", and returns it, along with the differences between the
+// starting environment of the shell, which is inherited from the current
+// process, and the resulting environment.
+//
+// Output produced by the script is discarded. If the script fails,
+// SourceScript returns an error which includes its standard error output.
+func SourceScript(ctx context.Context, shell, script string) (Map, Diff, error) {
+	script, err := filepath.Abs(script)
+	if err != nil {
+		return nil, Diff{}, err
+	}
+	before, err := captureShell(ctx, nil, shell, "-c", captureCommand, "env")
+	if err != nil {
+		return nil, Diff{}, err
+	}
+	after, err := captureShell(ctx, nil, shell, "-c", `. "$1" >/dev/null && `+captureCommand, "env", script)
+	if err != nil {
+		return nil, Diff{}, err
+	}
+	return after, before.Diff(after), nil
+}
+
+// captureBegin marks the beginning of the environment in the output of
+// captureCommand, such that output produced by shell startup files can be
+// told apart from it.
+const captureBegin = "-----BEGIN ENVIRONMENT-----"
+
+// captureCommand prints the environment of the shell running it.
+const captureCommand = "printf '%s' '" + captureBegin + "' && exec env -0"
+
+// captureShell runs shell with the specified arguments and environment,
+// as for the Env field of exec.Cmd. The arguments must run captureCommand.
+// captureShell parses the environment the shell prints.
+func captureShell(ctx context.Context, environ []string, shell string, args ...string) (Map, error) {
+	cmd := exec.CommandContext(ctx, shell, args...)
+	cmd.Env = environ
+	stderr := new(bytes.Buffer)
+	cmd.Stderr = stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if stderr.Len() > 0 {
+			return nil, fmt.Errorf("env: %s: %v: %s", shell, err, bytes.TrimSpace(stderr.Bytes()))
+		}
+		return nil, fmt.Errorf("env: %s: %v", shell, err)
+	}
+	i := bytes.LastIndex(out, []byte(captureBegin))
+	if i == -1 {
+		return nil, fmt.Errorf("env: %s: malformed output", shell)
+	}
+	m := parseNUL(string(out[i+len(captureBegin):]))
+	// The shell sets _ to the path of the last command it executed,
+	// which is env itself.
+	delete(m, "_")
+	return m, nil
+}
+
+// LoginShell returns the environment an interactive login shell of the
+// current user would have. It runs the shell named by the SHELL variable,
+// or /bin/sh if SHELL is not set, as a login shell, such that it reads
+// the user's profile, and captures its environment.
+//
+// This is useful for programs which are not started from a shell, such as
+// GUI applications on macOS, which would otherwise not observe the user's
+// customizations of variables like PATH.
+//
+// On Windows, where the user environment is not established by a shell,
+// LoginShell captures the environment of the command interpreter named by
+// ComSpec instead.
+func LoginShell(ctx context.Context) (Map, error) {
+	return loginShell(ctx, OS)
+}
+
+// LoginShellIn is like LoginShell, but looks up SHELL or ComSpec in e, and
+// runs the shell with the variables of e, rather than with those of the
+// current process.
+func LoginShellIn(ctx context.Context, e Environment) (Map, error) {
+	return loginShell(ctx, e)
+}
+
+// environ returns the environment of a command run in e, as for the Env
+// field of exec.Cmd: nil, for the environment of the current process, if
+// e is OS.
+func environ(e Environment) []string {
+	if e == OS {
+		return nil
+	}
+	return e.All().Encode()
+}
@@ -0,0 +1,157 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"acln.ro/env"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSourceScript(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test requires a POSIX shell")
+	}
+	dir, err := ioutil.TempDir("", "env-source")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	script := filepath.Join(dir, "activate")
+	contents := `
+echo activating
+export VIRTUAL_ENV=/opt/venv
+export ENV_TEST_SOURCE="changed value"
+unset ENV_TEST_REMOVED
+NOT_EXPORTED=1
+`
+	if err := ioutil.WriteFile(script, []byte(contents), 0600); err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv("ENV_TEST_SOURCE", "original")
+	os.Setenv("ENV_TEST_REMOVED", "x")
+	defer os.Unsetenv("ENV_TEST_SOURCE")
+	defer os.Unsetenv("ENV_TEST_REMOVED")
+
+	m, d, err := env.SourceScript(context.Background(), "/bin/sh", script)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := env.Diff{
+		OnlyInM: env.Map{"ENV_TEST_REMOVED": "x"},
+		Changes: []env.Change{
+			{Key: "ENV_TEST_SOURCE", MValue: "original", NValue: "changed value"},
+		},
+		OnlyInN: env.Map{"VIRTUAL_ENV": "/opt/venv"},
+	}
+	if diff := cmp.Diff(d, want); diff != "" {
+		t.Errorf("SourceScript: diff = %+v, want %+v: %s", d, want, diff)
+	}
+	if m["VIRTUAL_ENV"] != "/opt/venv" {
+		t.Errorf("VIRTUAL_ENV = %q, want %q", m["VIRTUAL_ENV"], "/opt/venv")
+	}
+	if _, ok := m["NOT_EXPORTED"]; ok {
+		t.Error("NOT_EXPORTED is in the resulting environment")
+	}
+}
+
+func TestSourceScriptError(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test requires a POSIX shell")
+	}
+	_, _, err := env.SourceScript(context.Background(), "/bin/sh", "/nonexistent/script")
+	if err == nil {
+		t.Error("SourceScript succeeded for a nonexistent script")
+	}
+}
+
+func TestLoginShell(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test requires a POSIX shell")
+	}
+	dir, err := ioutil.TempDir("", "env-login")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	profile := "echo welcome\nexport ENV_TEST_PROFILE=loaded\n"
+	if err := ioutil.WriteFile(filepath.Join(dir, ".profile"), []byte(profile), 0600); err != nil {
+		t.Fatal(err)
+	}
+	defer restoreEnv(env.Variables())
+	os.Setenv("SHELL", "/bin/sh")
+	os.Setenv("HOME", dir)
+	os.Unsetenv("ENV")
+
+	m, err := env.LoginShell(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := m["ENV_TEST_PROFILE"]; got != "loaded" {
+		t.Errorf("ENV_TEST_PROFILE = %q, want %q", got, "loaded")
+	}
+	if got := m["HOME"]; got != dir {
+		t.Errorf("HOME = %q, want %q", got, dir)
+	}
+}
+
+func TestLoginShellIn(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test requires a POSIX shell")
+	}
+	dir, err := ioutil.TempDir("", "env-login")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	profile := "export ENV_TEST_PROFILE=loaded\n"
+	if err := ioutil.WriteFile(filepath.Join(dir, ".profile"), []byte(profile), 0600); err != nil {
+		t.Fatal(err)
+	}
+	defer restoreEnv(env.Variables())
+	os.Setenv("SHELL", filepath.Join(dir, "missing"))
+	os.Setenv("ENV_TEST_PROCESS", "x")
+	e := env.Fake(env.Map{
+		"SHELL": "/bin/sh",
+		"HOME":  dir,
+		"PATH":  os.Getenv("PATH"),
+	})
+
+	m, err := env.LoginShellIn(context.Background(), e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := m["ENV_TEST_PROFILE"]; got != "loaded" {
+		t.Errorf("ENV_TEST_PROFILE = %q, want %q", got, "loaded")
+	}
+	if _, ok := m["ENV_TEST_PROCESS"]; ok {
+		t.Error("LoginShellIn inherited ENV_TEST_PROCESS from the current process")
+	}
+}
+
+// restoreEnv restores the process environment to m.
+func restoreEnv(m env.Map) {
+	os.Clearenv()
+	for k, v := range m {
+		os.Setenv(k, v)
+	}
+}
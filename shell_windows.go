@@ -0,0 +1,50 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+func loginShell(ctx context.Context, e Environment) (Map, error) {
+	comspec := e.All().lookupFold("ComSpec")
+	if comspec == "" {
+		comspec = "cmd.exe"
+	}
+	cmd := exec.CommandContext(ctx, comspec, "/d", "/c", "set")
+	cmd.Env = environ(e)
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("env: %s: %v", comspec, err)
+	}
+	return parseLines(strings.Replace(string(out), "\r\n", "\n", -1)), nil
+}
+
+// parseLines parses newline-separated "key=value" pairs, ignoring empty
+// lines and lines starting with '#'.
+func parseLines(s string) Map {
+	var kvs []string
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		kvs = append(kvs, line)
+	}
+	return Parse(kvs...)
+}
@@ -0,0 +1,125 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env
+
+import (
+	"fmt"
+	"strings"
+)
+
+// A Dialect is a shell command language.
+type Dialect int
+
+// Supported dialects.
+const (
+	POSIXShell Dialect = iota // sh, bash, zsh, and other POSIX shells
+	FishShell                 // fish
+	PowerShell                // PowerShell
+)
+
+// ShellCommands returns the commands which, when evaluated by a shell of
+// the specified dialect, turn environment M into environment N. Variables
+// only in M are unset, and variables only in N, or with different values,
+// are exported. Unsets come first, followed by exports, each sorted by
+// key, one command per line. Values are quoted such that the shell
+// evaluates them literally.
+//
+// POSIX shells cannot quote variable names, so for POSIXShell, all names
+// must be valid shell names: letters, digits and underscores, not
+// starting with a digit. Invalid names are reported together, as an
+// ErrorList, and no commands are returned. Fish and PowerShell names are
+// quoted.
+func (d Diff) ShellCommands(dialect Dialect) (string, error) {
+	unsets := d.OnlyInM.keys()
+	sets := make(Map, len(d.OnlyInN)+len(d.Changes))
+	for k, v := range d.OnlyInN {
+		sets[k] = v
+	}
+	for _, c := range d.Changes {
+		sets[c.Key] = c.NValue
+	}
+	if dialect == POSIXShell {
+		var errs ErrorList
+		for _, k := range append(unsets, sets.keys()...) {
+			if !isShellName(k) {
+				errs = append(errs, fmt.Errorf("env: invalid shell variable name %q", k))
+			}
+		}
+		if err := errs.err(); err != nil {
+			return "", err
+		}
+	}
+	var sb strings.Builder
+	for _, k := range unsets {
+		sb.WriteString(unsetCommand(dialect, k))
+		sb.WriteByte('\n')
+	}
+	for _, k := range sets.keys() {
+		sb.WriteString(exportCommand(dialect, k, sets[k]))
+		sb.WriteByte('\n')
+	}
+	return sb.String(), nil
+}
+
+func unsetCommand(dialect Dialect, key string) string {
+	switch dialect {
+	case FishShell:
+		return "set -e " + fishQuote(key)
+	case PowerShell:
+		return "Remove-Item -LiteralPath " + powerShellQuote("Env:"+key) + " -ErrorAction SilentlyContinue"
+	default:
+		return "unset " + key
+	}
+}
+
+func exportCommand(dialect Dialect, key, value string) string {
+	switch dialect {
+	case FishShell:
+		return "set -gx " + fishQuote(key) + " " + fishQuote(value)
+	case PowerShell:
+		return "Set-Item -LiteralPath " + powerShellQuote("Env:"+key) + " -Value " + powerShellQuote(value)
+	default:
+		return "export " + key + "=" + posixQuote(value)
+	}
+}
+
+// posixQuote quotes s for a POSIX shell, using single quotes.
+func posixQuote(s string) string {
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
+
+// fishQuote quotes s for fish, in which backslashes and single quotes
+// must be escaped inside single quotes.
+func fishQuote(s string) string {
+	s = strings.Replace(s, `\`, `\\`, -1)
+	return "'" + strings.Replace(s, "'", `\'`, -1) + "'"
+}
+
+// powerShellQuote quotes s for PowerShell, in which single quotes are
+// doubled inside single quotes. PowerShell also treats the typographic
+// single quotes as quotes, so they are doubled too.
+func powerShellQuote(s string) string {
+	var sb strings.Builder
+	sb.WriteByte('\'')
+	for _, r := range s {
+		switch r {
+		case '\'', '‘', '’', '‚', '‛':
+			sb.WriteRune(r)
+		}
+		sb.WriteRune(r)
+	}
+	sb.WriteByte('\'')
+	return sb.String()
+}
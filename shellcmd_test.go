@@ -0,0 +1,131 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env_test
+
+import (
+	"errors"
+	"os/exec"
+	"testing"
+
+	"acln.ro/env"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestShellCommands(t *testing.T) {
+	m := env.Map{"GONE": "x", "SAME": "s", "CHANGED": "old"}
+	n := env.Map{"SAME": "s", "CHANGED": "it's new", "ADDED": `a\b`}
+	d := m.Diff(n)
+	tests := []struct {
+		dialect env.Dialect
+		want    string
+	}{
+		{
+			dialect: env.POSIXShell,
+			want: `unset GONE
+export ADDED='a\b'
+export CHANGED='it'\''s new'
+`,
+		},
+		{
+			dialect: env.FishShell,
+			want: `set -e 'GONE'
+set -gx 'ADDED' 'a\\b'
+set -gx 'CHANGED' 'it\'s new'
+`,
+		},
+		{
+			dialect: env.PowerShell,
+			want: `Remove-Item -LiteralPath 'Env:GONE' -ErrorAction SilentlyContinue
+Set-Item -LiteralPath 'Env:ADDED' -Value 'a\b'
+Set-Item -LiteralPath 'Env:CHANGED' -Value 'it''s new'
+`,
+		},
+	}
+	for _, tt := range tests {
+		got, err := d.ShellCommands(tt.dialect)
+		if err != nil {
+			t.Fatalf("dialect %d: %v", tt.dialect, err)
+		}
+		if diff := cmp.Diff(got, tt.want); diff != "" {
+			t.Errorf("dialect %d: %s", tt.dialect, diff)
+		}
+	}
+}
+
+func TestShellCommandsHostileKey(t *testing.T) {
+	m := env.Map{"X;rm -rf ~;": "x"}
+	n := env.Map{"Y'$(id)": "y"}
+	d := m.Diff(n)
+	tests := []struct {
+		dialect env.Dialect
+		want    string
+	}{
+		{
+			dialect: env.FishShell,
+			want: `set -e 'X;rm -rf ~;'
+set -gx 'Y\'$(id)' 'y'
+`,
+		},
+		{
+			dialect: env.PowerShell,
+			want: `Remove-Item -LiteralPath 'Env:X;rm -rf ~;' -ErrorAction SilentlyContinue
+Set-Item -LiteralPath 'Env:Y''$(id)' -Value 'y'
+`,
+		},
+	}
+	for _, tt := range tests {
+		got, err := d.ShellCommands(tt.dialect)
+		if err != nil {
+			t.Fatalf("dialect %d: %v", tt.dialect, err)
+		}
+		if diff := cmp.Diff(got, tt.want); diff != "" {
+			t.Errorf("dialect %d: %s", tt.dialect, diff)
+		}
+	}
+
+	got, err := d.ShellCommands(env.POSIXShell)
+	var errs env.ErrorList
+	if !errors.As(err, &errs) || len(errs) != 2 || got != "" {
+		t.Errorf("POSIX: got %q, %v, want 2 errors and no commands", got, err)
+	}
+}
+
+func TestShellCommandsPOSIX(t *testing.T) {
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("sh not available")
+	}
+	m := env.Map{"GONE": "x", "CHANGED": "old"}
+	n := env.Map{
+		"CHANGED": "it's $new `cmd` \"q\"",
+		"ADDED":   "line 1\nline 2\\",
+	}
+	cmds, err := m.Diff(n).ShellCommands(env.POSIXShell)
+	if err != nil {
+		t.Fatal(err)
+	}
+	script := cmds + `printf '%s|%s|%s' "${GONE-unset}" "$CHANGED" "$ADDED"`
+	cmd := exec.Command(sh, "-c", script)
+	cmd.Env = m.Encode()
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "unset|" + n["CHANGED"] + "|" + n["ADDED"]
+	if got := string(out); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
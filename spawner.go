@@ -0,0 +1,66 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env
+
+import (
+	"context"
+	"os/exec"
+	"runtime"
+)
+
+// A Spawner creates commands which share an environment. The environment
+// is built and encoded once, when the Spawner is created, so that
+// creating many commands does not repeat the work. A Spawner is safe for
+// concurrent use by multiple goroutines.
+type Spawner struct {
+	env []string
+}
+
+// NewSpawner returns a Spawner whose commands run with the variables in
+// base which policy inherits, overlaid with overrides, as Build does. On
+// Windows, SystemRoot is copied from base if the result does not set it.
+//
+// base is usually the environment of the current process, as returned by
+// Variables. Later changes to base and overrides do not affect the
+// Spawner.
+func NewSpawner(base Map, policy Inherit, overrides Map) *Spawner {
+	m := isolated(build(base, policy, []Map{overrides}), runtime.GOOS, base)
+	return &Spawner{env: m.Encode()}
+}
+
+// Command is like exec.Command, but runs the command with the environment
+// of the Spawner.
+func (s *Spawner) Command(name string, args ...string) *exec.Cmd {
+	cmd := exec.Command(name, args...)
+	cmd.Env = s.Environ()
+	return cmd
+}
+
+// CommandContext is like exec.CommandContext, but runs the command with
+// the environment of the Spawner.
+func (s *Spawner) CommandContext(ctx context.Context, name string, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Env = s.Environ()
+	return cmd
+}
+
+// Environ returns the environment of the Spawner, as a slice of
+// "key=value" pairs, sorted by key. The slice is a copy, but the pairs
+// are shared, so creating it is cheap.
+func (s *Spawner) Environ() []string {
+	env := make([]string, len(s.env))
+	copy(env, s.env)
+	return env
+}
@@ -0,0 +1,80 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env_test
+
+import (
+	"context"
+	"os/exec"
+	"runtime"
+	"sync"
+	"testing"
+
+	"acln.ro/env"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSpawner(t *testing.T) {
+	base := env.Map{"HOME": "/home/u", "PATH": "/usr/bin", "API_TOKEN": "t"}
+	overrides := env.Map{
+		"PATH":       "/opt/bin:/usr/bin",
+		"HOME":       env.Tombstone,
+		"SystemRoot": `C:\Windows`,
+		"WORKER":     "1",
+	}
+	s := env.NewSpawner(base, env.InheritExcept("*_TOKEN"), overrides)
+	base["LATE"] = "x"
+	overrides["LATE"] = "x"
+
+	want := []string{"PATH=/opt/bin:/usr/bin", `SystemRoot=C:\Windows`, "WORKER=1"}
+	if diff := cmp.Diff(s.Environ(), want); diff != "" {
+		t.Errorf("Environ: %s", diff)
+	}
+	cmd := s.Command("prog", "arg")
+	if diff := cmp.Diff(cmd.Env, want); diff != "" {
+		t.Errorf("Command: %s", diff)
+	}
+	cmd.Env[0] = "PATH=changed"
+	cmd = s.CommandContext(context.Background(), "prog")
+	if diff := cmp.Diff(cmd.Env, want); diff != "" {
+		t.Errorf("CommandContext after modifying an earlier command: %s", diff)
+	}
+}
+
+func TestSpawnerConcurrent(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses env")
+	}
+	if _, err := exec.LookPath("env"); err != nil {
+		t.Skip("env not available")
+	}
+	s := env.NewSpawner(nil, env.InheritNone, env.Map{"WORKER": "1"})
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			out, err := s.Command("env").Output()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if got, want := string(out), "WORKER=1\n"; got != want {
+				t.Errorf("got %q, want %q", got, want)
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,117 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AcceptEnv returns the variables in m whose names match at least one of
+// the specified patterns, following the semantics of the AcceptEnv and
+// SendEnv directives in OpenSSH configuration files: '*' matches any
+// sequence of characters, and '?' matches exactly one character. Each
+// pattern argument may hold several whitespace-separated patterns, as
+// on an sshd_config line.
+func (m Map) AcceptEnv(patterns ...string) Map {
+	var pats []string
+	for _, p := range patterns {
+		pats = append(pats, strings.Fields(p)...)
+	}
+	accepted := make(Map)
+	for k, v := range m {
+		for _, p := range pats {
+			if matchSSHPattern(p, k) {
+				accepted[k] = v
+				break
+			}
+		}
+	}
+	return accepted
+}
+
+// matchSSHPattern reports whether s matches the OpenSSH wildcard pattern.
+func matchSSHPattern(pattern, s string) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case '*':
+			pattern = pattern[1:]
+			if pattern == "" {
+				return true
+			}
+			for i := 0; i <= len(s); i++ {
+				if matchSSHPattern(pattern, s[i:]) {
+					return true
+				}
+			}
+			return false
+		case '?':
+			if s == "" {
+				return false
+			}
+		default:
+			if s == "" || s[0] != pattern[0] {
+				return false
+			}
+		}
+		pattern = pattern[1:]
+		s = s[1:]
+	}
+	return s == ""
+}
+
+// maxSSHEnv is the number of environment requests OpenSSH's sshd honors
+// per session. Further requests are ignored.
+const maxSSHEnv = 128
+
+// ValidateSSH checks that the variables in m can be transmitted using SSH
+// "env" requests and will be honored by OpenSSH's sshd, provided that it
+// is configured to accept them. Names must be non-empty and must not
+// contain '=', names and values must not contain NUL bytes, and there may
+// be no more than 128 variables. All problems are reported, as an
+// ErrorList.
+func ValidateSSH(m Map) error {
+	var errs ErrorList
+	if len(m) > maxSSHEnv {
+		errs = append(errs, fmt.Errorf("env: %d variables exceed the sshd limit of %d", len(m), maxSSHEnv))
+	}
+	for _, k := range m.keys() {
+		if err := checkEntry(k, m[k]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs.err()
+}
+
+// Setenver is implemented by SSH sessions which can send environment
+// variables to the remote side, such as *ssh.Session from the
+// golang.org/x/crypto/ssh package.
+type Setenver interface {
+	Setenv(name, value string) error
+}
+
+// SetenvSSH validates m using ValidateSSH, then sends each variable in m
+// to s, in lexicographic order of keys. It stops at the first error.
+func SetenvSSH(s Setenver, m Map) error {
+	if err := ValidateSSH(m); err != nil {
+		return err
+	}
+	for _, k := range m.keys() {
+		if err := s.Setenv(k, m[k]); err != nil {
+			return fmt.Errorf("env: setting %s: %v", k, err)
+		}
+	}
+	return nil
+}
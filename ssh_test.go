@@ -0,0 +1,117 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env_test
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"acln.ro/env"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestAcceptEnv(t *testing.T) {
+	m := env.Map{
+		"LANG":         "C.UTF-8",
+		"LC_ALL":       "C",
+		"LC_CTYPE":     "C",
+		"APP_MODE":     "prod",
+		"APP_X":        "x",
+		"LD_PRELOAD":   "evil.so",
+		"GIT_PROTOCOL": "version=2",
+	}
+	tests := []struct {
+		patterns []string
+		want     env.Map
+	}{
+		{
+			patterns: nil,
+			want:     env.Map{},
+		},
+		{
+			patterns: []string{"LANG LC_*"},
+			want:     env.Map{"LANG": "C.UTF-8", "LC_ALL": "C", "LC_CTYPE": "C"},
+		},
+		{
+			patterns: []string{"APP_?", "GIT_PROTOCOL"},
+			want:     env.Map{"APP_X": "x", "GIT_PROTOCOL": "version=2"},
+		},
+		{
+			patterns: []string{"*"},
+			want:     m,
+		},
+	}
+	for _, tt := range tests {
+		got := m.AcceptEnv(tt.patterns...)
+		if diff := cmp.Diff(got, tt.want); diff != "" {
+			t.Errorf("AcceptEnv(%q) = %v, want %v: %s", tt.patterns, got, tt.want, diff)
+		}
+	}
+}
+
+func TestValidateSSH(t *testing.T) {
+	tests := []struct {
+		m  env.Map
+		ok bool
+	}{
+		{env.Map{"LANG": "C"}, true},
+		{env.Map{"": "x"}, false},
+		{env.Map{"A=B": "x"}, false},
+		{env.Map{"A": "x\x00y"}, false},
+	}
+	for _, tt := range tests {
+		err := env.ValidateSSH(tt.m)
+		if ok := err == nil; ok != tt.ok {
+			t.Errorf("ValidateSSH(%q) = %v, want ok = %t", tt.m, err, tt.ok)
+		}
+	}
+	big := make(env.Map)
+	for i := 0; i < 129; i++ {
+		big["V"+strconv.Itoa(i)] = "x"
+	}
+	if err := env.ValidateSSH(big); err == nil {
+		t.Error("ValidateSSH accepted 129 variables")
+	}
+}
+
+type recordingSession struct {
+	sent [][2]string
+	fail string
+}
+
+func (s *recordingSession) Setenv(name, value string) error {
+	if name == s.fail {
+		return errors.New("rejected")
+	}
+	s.sent = append(s.sent, [2]string{name, value})
+	return nil
+}
+
+func TestSetenvSSH(t *testing.T) {
+	s := new(recordingSession)
+	if err := env.SetenvSSH(s, env.Map{"LC_ALL": "C", "LANG": "C.UTF-8"}); err != nil {
+		t.Fatal(err)
+	}
+	want := [][2]string{{"LANG", "C.UTF-8"}, {"LC_ALL", "C"}}
+	if diff := cmp.Diff(s.sent, want); diff != "" {
+		t.Errorf("sent %q, want %q: %s", s.sent, want, diff)
+	}
+	s = &recordingSession{fail: "LANG"}
+	if err := env.SetenvSSH(s, env.Map{"LANG": "C"}); err == nil {
+		t.Error("SetenvSSH did not report Setenv error")
+	}
+}
@@ -0,0 +1,208 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env
+
+import (
+	"context"
+	"sync"
+)
+
+// A Store is a Map which is safe for concurrent use by multiple
+// goroutines. Optionally, a Store keeps a bounded history of the changes
+// applied to it, which can be undone and redone.
+//
+// Hooks registered on a Store are called around mutations, and can be
+// used to enforce policy: a hook registered using BeforeChange can reject
+// a change.
+type Store struct {
+	mu      sync.RWMutex
+	m       Map
+	limit   int
+	history []applied
+	undone  []applied
+	before  []func(Op) error
+	after   []func(Op)
+}
+
+// applied is a Changeset applied to a Store, along with its inverse.
+type applied struct {
+	changes Changeset
+	inverse Changeset
+}
+
+// NewStore returns a Store holding a copy of m.
+func NewStore(m Map) *Store {
+	return &Store{m: Merge(m)}
+}
+
+// Lookup returns the value of the variable named by key, and whether it
+// is set.
+func (s *Store) Lookup(key string) (string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	v, ok := s.m[key]
+	return v, ok
+}
+
+// Snapshot returns a copy of the variables in the Store.
+func (s *Store) Snapshot() Map {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return Merge(s.m)
+}
+
+// Environ implements Source for Store. It returns a snapshot of the
+// variables in the Store.
+func (s *Store) Environ(ctx context.Context) (Map, error) {
+	return s.Snapshot(), nil
+}
+
+// Set sets key to value.
+func (s *Store) Set(key, value string) error {
+	return s.Apply(Changeset{{Key: key, Value: value}})
+}
+
+// Unset unsets key.
+func (s *Store) Unset(key string) error {
+	return s.Apply(Changeset{{Unset: true, Key: key}})
+}
+
+// Apply applies c to the Store atomically, and records it in the
+// history, if enabled. Changes which were undone can no longer be redone
+// after a call to Apply. The history holds a copy of c, so the caller
+// may reuse c after Apply returns.
+//
+// If a hook registered using BeforeChange rejects any of the operations
+// in c, Apply returns the error returned by the hook, and the Store is
+// left untouched.
+func (s *Store) Apply(c Changeset) error {
+	c = append(Changeset(nil), c...)
+	s.mu.Lock()
+	for _, op := range c {
+		for _, hook := range s.before {
+			if err := hook(op); err != nil {
+				s.mu.Unlock()
+				return err
+			}
+		}
+	}
+	inverse := s.apply(c)
+	if s.limit > 0 {
+		s.history = append(s.history, applied{changes: c, inverse: inverse})
+		if len(s.history) > s.limit {
+			s.history = s.history[len(s.history)-s.limit:]
+		}
+		s.undone = nil
+	}
+	after := s.after
+	s.mu.Unlock()
+	runAfterHooks(after, c)
+	return nil
+}
+
+// BeforeChange registers a hook which is called for each operation about
+// to be applied to the Store. If the hook returns an error, the change is
+// rejected. Hooks are called while the Store is locked, so they must not
+// call methods on the Store.
+//
+// Hooks registered using BeforeChange are not consulted by Undo and Redo,
+// which restore states the Store was in previously.
+func (s *Store) BeforeChange(hook func(Op) error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.before = append(s.before, hook)
+}
+
+// AfterChange registers a hook which is called for each operation applied
+// to the Store, including by Undo and Redo, after the Store is unlocked.
+func (s *Store) AfterChange(hook func(Op)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.after = append(s.after, hook)
+}
+
+func runAfterHooks(hooks []func(Op), c Changeset) {
+	for _, op := range c {
+		for _, hook := range hooks {
+			hook(op)
+		}
+	}
+}
+
+// apply applies c to the Store, and returns its inverse.
+func (s *Store) apply(c Changeset) Changeset {
+	inverse := make(Changeset, len(c))
+	for i, op := range c {
+		prev, ok := s.m[op.Key]
+		inverse[len(c)-1-i] = Op{Unset: !ok, Key: op.Key, Value: prev}
+		op.Apply(s.m)
+	}
+	return inverse
+}
+
+// SetHistory sets the number of changesets the Store remembers for the
+// purpose of Undo. If n is zero, which is the default, or negative,
+// history is disabled. If the history holds more than n changesets, the
+// oldest are discarded.
+func (s *Store) SetHistory(n int) {
+	if n < 0 {
+		n = 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.limit = n
+	if len(s.history) > n {
+		s.history = s.history[len(s.history)-n:]
+	}
+	if len(s.undone) > n {
+		s.undone = s.undone[len(s.undone)-n:]
+	}
+}
+
+// Undo reverts the most recently applied changeset in the history. It
+// reports whether there was a changeset to undo.
+func (s *Store) Undo() bool {
+	s.mu.Lock()
+	if len(s.history) == 0 {
+		s.mu.Unlock()
+		return false
+	}
+	a := s.history[len(s.history)-1]
+	s.history = s.history[:len(s.history)-1]
+	s.apply(a.inverse)
+	s.undone = append(s.undone, a)
+	after := s.after
+	s.mu.Unlock()
+	runAfterHooks(after, a.inverse)
+	return true
+}
+
+// Redo re-applies the most recently undone changeset. It reports whether
+// there was a changeset to redo.
+func (s *Store) Redo() bool {
+	s.mu.Lock()
+	if len(s.undone) == 0 {
+		s.mu.Unlock()
+		return false
+	}
+	a := s.undone[len(s.undone)-1]
+	s.undone = s.undone[:len(s.undone)-1]
+	s.apply(a.changes)
+	s.history = append(s.history, a)
+	after := s.after
+	s.mu.Unlock()
+	runAfterHooks(after, a.changes)
+	return true
+}
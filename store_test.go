@@ -0,0 +1,211 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"acln.ro/env"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestStore(t *testing.T) {
+	base := env.Map{"FOO": "x"}
+	s := env.NewStore(base)
+	s.Set("BAR", "y")
+	s.Unset("FOO")
+	if diff := cmp.Diff(s.Snapshot(), env.Map{"BAR": "y"}); diff != "" {
+		t.Errorf("Snapshot: %s", diff)
+	}
+	if base["FOO"] != "x" {
+		t.Error("Store modified the Map it was created from")
+	}
+	if v, ok := s.Lookup("BAR"); v != "y" || !ok {
+		t.Errorf("Lookup(BAR) = %q, %t, want %q, true", v, ok, "y")
+	}
+	if s.Undo() {
+		t.Error("Undo succeeded without history")
+	}
+}
+
+func TestStoreConcurrent(t *testing.T) {
+	s := env.NewStore(nil)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			k := fmt.Sprint("K", i)
+			s.Set(k, "v")
+			s.Lookup(k)
+			s.Snapshot()
+		}(i)
+	}
+	wg.Wait()
+	if n := len(s.Snapshot()); n != 10 {
+		t.Errorf("got %d variables, want 10", n)
+	}
+}
+
+func TestStoreUndoRedo(t *testing.T) {
+	s := env.NewStore(env.Map{"FOO": "x", "BAR": "y"})
+	s.SetHistory(2)
+
+	var c env.Changeset
+	c.Set("FOO", "z")
+	c.Unset("BAR")
+	c.Set("BAR", "w")
+	c.Unset("FOO")
+	s.Apply(c)
+	s.Set("NEW", "n")
+	s.Set("NEWER", "m")
+
+	states := []env.Map{
+		{"BAR": "w", "NEW": "n", "NEWER": "m"},
+		{"BAR": "w", "NEW": "n"},
+		{"BAR": "w"},
+	}
+	if diff := cmp.Diff(s.Snapshot(), states[0]); diff != "" {
+		t.Errorf("initial state: %s", diff)
+	}
+	for i := 1; i < len(states); i++ {
+		if !s.Undo() {
+			t.Fatalf("Undo %d failed", i)
+		}
+		if diff := cmp.Diff(s.Snapshot(), states[i]); diff != "" {
+			t.Errorf("after Undo %d: %s", i, diff)
+		}
+	}
+	if s.Undo() {
+		t.Error("Undo beyond history limit succeeded")
+	}
+	for i := len(states) - 2; i >= 0; i-- {
+		if !s.Redo() {
+			t.Fatal("Redo failed")
+		}
+		if diff := cmp.Diff(s.Snapshot(), states[i]); diff != "" {
+			t.Errorf("after Redo: %s", diff)
+		}
+	}
+	if s.Redo() {
+		t.Error("Redo succeeded with nothing to redo")
+	}
+
+	s.Undo()
+	s.Set("OTHER", "o")
+	if s.Redo() {
+		t.Error("Redo succeeded after a new change")
+	}
+}
+
+func TestStoreUndoComplexChangeset(t *testing.T) {
+	orig := env.Map{"FOO": "x", "BAR": "y"}
+	s := env.NewStore(orig)
+	s.SetHistory(1)
+	var c env.Changeset
+	c.Set("FOO", "z")
+	c.Unset("BAR")
+	c.Set("BAR", "w")
+	c.Unset("FOO")
+	c.Set("NEW", "n")
+	s.Apply(c)
+	s.Undo()
+	if diff := cmp.Diff(s.Snapshot(), orig); diff != "" {
+		t.Errorf("after Undo: %s", diff)
+	}
+}
+
+func TestStoreApplyCopiesChangeset(t *testing.T) {
+	s := env.NewStore(env.Map{"FOO": "x"})
+	s.SetHistory(1)
+	c := env.Changeset{{Key: "FOO", Value: "y"}}
+	s.Apply(c)
+	c[0].Value = "mutated"
+	s.Undo()
+	s.Redo()
+	if diff := cmp.Diff(s.Snapshot(), env.Map{"FOO": "y"}); diff != "" {
+		t.Errorf("after Redo: %s", diff)
+	}
+}
+
+func TestStoreNegativeHistory(t *testing.T) {
+	s := env.NewStore(env.Map{})
+	s.SetHistory(2)
+	s.Set("A", "1")
+	s.SetHistory(-1)
+	if s.Undo() {
+		t.Error("Undo succeeded with history disabled")
+	}
+	s.Set("A", "2")
+	if s.Undo() {
+		t.Error("Undo succeeded after change with history disabled")
+	}
+}
+
+func TestStoreHooks(t *testing.T) {
+	s := env.NewStore(env.Map{"PATH": "/bin"})
+	s.SetHistory(10)
+	errDenied := errors.New("denied")
+	s.BeforeChange(func(op env.Op) error {
+		if op.Key == "LD_PRELOAD" && !op.Unset {
+			return errDenied
+		}
+		if op.Key == "PORT" && !op.Unset && strings.Trim(op.Value, "0123456789") != "" {
+			return fmt.Errorf("PORT %q is not numeric", op.Value)
+		}
+		return nil
+	})
+	var seen []env.Op
+	s.AfterChange(func(op env.Op) {
+		seen = append(seen, op)
+	})
+
+	if err := s.Set("LD_PRELOAD", "evil.so"); err != errDenied {
+		t.Errorf("Set(LD_PRELOAD): got %v, want %v", err, errDenied)
+	}
+	var c env.Changeset
+	c.Set("HOME", "/root")
+	c.Set("PORT", "http")
+	if err := s.Apply(c); err == nil {
+		t.Error("Apply with invalid PORT succeeded")
+	}
+	if diff := cmp.Diff(s.Snapshot(), env.Map{"PATH": "/bin"}); diff != "" {
+		t.Errorf("Store modified by rejected changes: %s", diff)
+	}
+	if len(seen) != 0 {
+		t.Errorf("after hooks called for rejected changes: %v", seen)
+	}
+
+	if err := s.Set("PORT", "8080"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Unset("PATH"); err != nil {
+		t.Fatal(err)
+	}
+	s.Undo()
+	want := []env.Op{
+		{Key: "PORT", Value: "8080"},
+		{Unset: true, Key: "PATH"},
+		{Key: "PATH", Value: "/bin"},
+	}
+	if diff := cmp.Diff(seen, want); diff != "" {
+		t.Errorf("after hooks saw %v, want %v: %s", seen, want, diff)
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env
+
+import (
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"unicode"
+)
+
+// A TableOption configures the output of Table.
+type TableOption func(*tableConfig)
+
+type tableConfig struct {
+	redact Policy
+	order  []EncodeOption
+}
+
+// RedactValues causes the values of variables marked as secret by p to be
+// redacted in the table.
+func RedactValues(p Policy) TableOption {
+	return func(cfg *tableConfig) {
+		cfg.redact = p
+	}
+}
+
+// TableOrder orders the rows of the table as Encode orders pairs when
+// configured by opts. By default, rows are sorted lexicographically by key.
+func TableOrder(opts ...EncodeOption) TableOption {
+	return func(cfg *tableConfig) {
+		cfg.order = opts
+	}
+}
+
+// Table writes m to w as a table with aligned KEY and VALUE columns, one
+// variable per row, for display in terminals:
+//
+//	KEY       VALUE
+//	EDITOR    vi
+//	GOPATH    /home/u/go
+//	GREETING  "hello\n"
+//
+// Values which contain control characters or other invisible characters,
+// or which begin or end with a space, are quoted using Go syntax, so that
+// each row is a single line, and the characters show up.
+func (m Map) Table(w io.Writer, opts ...TableOption) error {
+	var cfg tableConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.redact != nil {
+		m = m.Redact(cfg.redact)
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	io.WriteString(tw, "KEY\tVALUE\n")
+	for _, k := range m.orderedKeys(cfg.order) {
+		io.WriteString(tw, tableCell(k))
+		io.WriteString(tw, "\t")
+		io.WriteString(tw, tableCell(m[k]))
+		io.WriteString(tw, "\n")
+	}
+	return tw.Flush()
+}
+
+// tableCell returns s, quoted if it would not display faithfully in a
+// table cell.
+func tableCell(s string) string {
+	if strings.HasPrefix(s, " ") || strings.HasSuffix(s, " ") {
+		return strconv.Quote(s)
+	}
+	for _, r := range s {
+		if r != ' ' && !unicode.IsPrint(r) {
+			return strconv.Quote(s)
+		}
+	}
+	return s
+}
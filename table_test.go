@@ -0,0 +1,91 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env_test
+
+import (
+	"bytes"
+	"testing"
+
+	"acln.ro/env"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTable(t *testing.T) {
+	m := env.Map{
+		"EDITOR":    "vi",
+		"GOPATH":    "/home/u/go",
+		"GREETING":  "hello\n",
+		"PADDED":    " x ",
+		"TABBED":    "a\tb",
+		"API_TOKEN": "t0ken",
+		"SPACED":    "a b",
+	}
+	tests := []struct {
+		name string
+		opts []env.TableOption
+		want string
+	}{
+		{
+			name: "default",
+			want: `KEY        VALUE
+API_TOKEN  t0ken
+EDITOR     vi
+GOPATH     /home/u/go
+GREETING   "hello\n"
+PADDED     " x "
+SPACED     a b
+TABBED     "a\tb"
+`,
+		},
+		{
+			name: "redacted and ordered",
+			opts: []env.TableOption{
+				env.RedactValues(env.DefaultPolicy),
+				env.TableOrder(env.Priority("GOPATH", "EDITOR")),
+			},
+			want: `KEY        VALUE
+GOPATH     /home/u/go
+EDITOR     vi
+API_TOKEN  xxxxx
+GREETING   "hello\n"
+PADDED     " x "
+SPACED     a b
+TABBED     "a\tb"
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := m.Table(&buf, tt.opts...); err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(buf.String(), tt.want); diff != "" {
+				t.Errorf("Table: %s", diff)
+			}
+		})
+	}
+}
+
+func TestTableEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	if err := (env.Map{}).Table(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := buf.String(), "KEY  VALUE\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env
+
+import (
+	"fmt"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+)
+
+// TracedMap wraps a Map, and records reads of its variables. It is safe
+// for concurrent use by multiple goroutines.
+type TracedMap struct {
+	// RecordCallers specifies whether to record the location of the
+	// first read of each variable. It must be set before the TracedMap
+	// is used.
+	RecordCallers bool
+
+	m     Map
+	mu    sync.Mutex
+	reads map[string]*Read
+}
+
+// Read describes reads of a variable from a TracedMap.
+type Read struct {
+	Key    string
+	Count  int
+	First  time.Time
+	Last   time.Time
+	Caller string // file:line of the first read, if recorded
+}
+
+// Traced returns a TracedMap wrapping m. The caller must not modify m
+// while the TracedMap is in use.
+func Traced(m Map) *TracedMap {
+	return &TracedMap{
+		m:     m,
+		reads: make(map[string]*Read),
+	}
+}
+
+// Lookup returns the value of the variable named by key, and whether it
+// is set, recording the read.
+func (t *TracedMap) Lookup(key string) (string, bool) {
+	t.record(key)
+	v, ok := t.m[key]
+	return v, ok
+}
+
+// Get returns the value of the variable named by key, recording the read.
+func (t *TracedMap) Get(key string) string {
+	t.record(key)
+	return t.m[key]
+}
+
+func (t *TracedMap) record(key string) {
+	now := time.Now()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	r, ok := t.reads[key]
+	if !ok {
+		r = &Read{Key: key, First: now}
+		if t.RecordCallers {
+			if _, file, line, ok := runtime.Caller(2); ok {
+				r.Caller = fmt.Sprintf("%s:%d", file, line)
+			}
+		}
+		t.reads[key] = r
+	}
+	r.Count++
+	r.Last = now
+}
+
+// Reads returns the reads recorded so far, sorted by key.
+func (t *TracedMap) Reads() []Read {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	reads := make([]Read, 0, len(t.reads))
+	for _, r := range t.reads {
+		reads = append(reads, *r)
+	}
+	sort.Slice(reads, func(i, j int) bool {
+		return reads[i].Key < reads[j].Key
+	})
+	return reads
+}
+
+// TraceReport summarizes the use of the variables in a TracedMap.
+type TraceReport struct {
+	// Unread lists variables which are set, but were never read.
+	Unread []string
+
+	// Undeclared lists variables which were read, but are not set.
+	Undeclared []string
+}
+
+// Report returns a report of the reads recorded so far. The lists in the
+// report are sorted lexicographically.
+func (t *TracedMap) Report() TraceReport {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var rep TraceReport
+	for _, k := range t.m.keys() {
+		if _, ok := t.reads[k]; !ok {
+			rep.Unread = append(rep.Unread, k)
+		}
+	}
+	for k := range t.reads {
+		if _, ok := t.m[k]; !ok {
+			rep.Undeclared = append(rep.Undeclared, k)
+		}
+	}
+	sort.Strings(rep.Undeclared)
+	return rep
+}
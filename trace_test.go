@@ -0,0 +1,68 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env_test
+
+import (
+	"strings"
+	"testing"
+
+	"acln.ro/env"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTraced(t *testing.T) {
+	tm := env.Traced(env.Map{"HOST": "localhost", "PORT": "8080", "DEAD": "x"})
+	tm.RecordCallers = true
+	if got := tm.Get("HOST"); got != "localhost" {
+		t.Errorf("Get(HOST) = %q, want %q", got, "localhost")
+	}
+	tm.Get("HOST")
+	if v, ok := tm.Lookup("PORT"); v != "8080" || !ok {
+		t.Errorf("Lookup(PORT) = %q, %t, want %q, true", v, ok, "8080")
+	}
+	if _, ok := tm.Lookup("DEBUG"); ok {
+		t.Error("Lookup(DEBUG) reported the variable as set")
+	}
+
+	reads := tm.Reads()
+	var keys []string
+	var counts []int
+	for _, r := range reads {
+		keys = append(keys, r.Key)
+		counts = append(counts, r.Count)
+		if !strings.Contains(r.Caller, "trace_test.go") {
+			t.Errorf("read of %s: caller %q is not in trace_test.go", r.Key, r.Caller)
+		}
+		if r.First.IsZero() || r.Last.Before(r.First) {
+			t.Errorf("read of %s: bad timestamps %v, %v", r.Key, r.First, r.Last)
+		}
+	}
+	if diff := cmp.Diff(keys, []string{"DEBUG", "HOST", "PORT"}); diff != "" {
+		t.Errorf("read keys %q: %s", keys, diff)
+	}
+	if diff := cmp.Diff(counts, []int{1, 2, 1}); diff != "" {
+		t.Errorf("read counts %v: %s", counts, diff)
+	}
+
+	got := tm.Report()
+	want := env.TraceReport{
+		Unread:     []string{"DEAD"},
+		Undeclared: []string{"DEBUG"},
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("Report() = %+v, want %+v: %s", got, want, diff)
+	}
+}
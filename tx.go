@@ -0,0 +1,97 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env
+
+import "errors"
+
+// ErrTxDone is returned by operations on a transaction which has already
+// been committed or rolled back.
+var ErrTxDone = errors.New("env: transaction has already been committed or rolled back")
+
+// A Tx is a transaction on a Map. Changes staged in a Tx are applied to
+// the Map all at once when the transaction is committed, or not at all.
+//
+// A Tx is not safe for concurrent use, and the Map must not be modified
+// by other means while the transaction is in progress.
+type Tx struct {
+	m       Map
+	staged  Map
+	changes Changeset
+	done    bool
+}
+
+// Begin starts a transaction on m.
+func (m Map) Begin() *Tx {
+	return &Tx{m: m, staged: Merge(m)}
+}
+
+// Set stages the setting of key to value.
+func (tx *Tx) Set(key, value string) {
+	tx.stage(Op{Key: key, Value: value})
+}
+
+// Unset stages the unsetting of key.
+func (tx *Tx) Unset(key string) {
+	tx.stage(Op{Unset: true, Key: key})
+}
+
+func (tx *Tx) stage(op Op) {
+	if tx.done {
+		return
+	}
+	op.Apply(tx.staged)
+	tx.changes = append(tx.changes, op)
+}
+
+// Lookup returns the value of the variable named by key as of the staged
+// changes, and whether it is set.
+func (tx *Tx) Lookup(key string) (string, bool) {
+	v, ok := tx.staged[key]
+	return v, ok
+}
+
+// Changes returns the changes staged so far.
+func (tx *Tx) Changes() Changeset {
+	return append(Changeset(nil), tx.changes...)
+}
+
+// Commit runs the specified validators on the Map resulting from the
+// staged changes. If all validators succeed, Commit applies the staged
+// changes to the Map. Otherwise, it returns the errors of all failing
+// validators, as an ErrorList, and leaves the Map untouched. In both
+// cases, the transaction is over.
+func (tx *Tx) Commit(validators ...func(Map) error) error {
+	if tx.done {
+		return ErrTxDone
+	}
+	tx.done = true
+	var errs ErrorList
+	for _, validate := range validators {
+		if err := validate(Merge(tx.staged)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := errs.err(); err != nil {
+		return err
+	}
+	tx.changes.Apply(tx.m)
+	return nil
+}
+
+// Rollback discards the staged changes. Rollback after Commit is a no-op,
+// so it is safe to defer a call to Rollback.
+func (tx *Tx) Rollback() {
+	tx.done = true
+}
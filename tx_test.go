@@ -0,0 +1,105 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env_test
+
+import (
+	"errors"
+	"testing"
+
+	"acln.ro/env"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTxCommit(t *testing.T) {
+	m := env.Map{"FOO": "x", "BAR": "y"}
+	tx := m.Begin()
+	defer tx.Rollback()
+	tx.Set("FOO", "z")
+	tx.Unset("BAR")
+	tx.Set("NEW", "n")
+	if v, ok := tx.Lookup("FOO"); v != "z" || !ok {
+		t.Errorf("Lookup(FOO) = %q, %t, want %q, true", v, ok, "z")
+	}
+	if _, ok := tx.Lookup("BAR"); ok {
+		t.Error("Lookup(BAR) reported staged-unset variable as set")
+	}
+	if m["FOO"] != "x" {
+		t.Error("staged change applied before Commit")
+	}
+	var seen env.Map
+	err := tx.Commit(func(staged env.Map) error {
+		seen = staged
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := env.Map{"FOO": "z", "NEW": "n"}
+	if diff := cmp.Diff(m, want); diff != "" {
+		t.Errorf("after Commit: got %v, want %v: %s", m, want, diff)
+	}
+	if diff := cmp.Diff(seen, want); diff != "" {
+		t.Errorf("validator saw %v, want %v: %s", seen, want, diff)
+	}
+	if err := tx.Commit(); err != env.ErrTxDone {
+		t.Errorf("second Commit: got %v, want ErrTxDone", err)
+	}
+}
+
+func TestTxValidationFailure(t *testing.T) {
+	m := env.Map{"FOO": "x"}
+	tx := m.Begin()
+	tx.Set("LD_PRELOAD", "evil.so")
+	tx.Unset("FOO")
+	errDenied := errors.New("LD_PRELOAD is not allowed")
+	errMissing := errors.New("FOO is required")
+	err := tx.Commit(func(staged env.Map) error {
+		if _, ok := staged["LD_PRELOAD"]; ok {
+			return errDenied
+		}
+		return nil
+	}, func(staged env.Map) error {
+		return nil
+	}, func(staged env.Map) error {
+		if _, ok := staged["FOO"]; !ok {
+			return errMissing
+		}
+		return nil
+	})
+	var errs env.ErrorList
+	if !errors.As(err, &errs) || len(errs) != 2 {
+		t.Fatalf("Commit: got %v, want 2 errors", err)
+	}
+	if !errors.Is(err, errDenied) || !errors.Is(err, errMissing) {
+		t.Errorf("Commit: got %v, want %v and %v", err, errDenied, errMissing)
+	}
+	if diff := cmp.Diff(m, env.Map{"FOO": "x"}); diff != "" {
+		t.Errorf("Map modified by failed Commit: %s", diff)
+	}
+}
+
+func TestTxRollback(t *testing.T) {
+	m := env.Map{"FOO": "x"}
+	tx := m.Begin()
+	tx.Set("FOO", "y")
+	tx.Rollback()
+	if err := tx.Commit(); err != env.ErrTxDone {
+		t.Errorf("Commit after Rollback: got %v, want ErrTxDone", err)
+	}
+	if m["FOO"] != "x" {
+		t.Error("rolled back change applied")
+	}
+}
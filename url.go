@@ -0,0 +1,123 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// URL is connection information parsed from a URL-valued variable, such
+// as DATABASE_URL or REDIS_URL.
+type URL struct {
+	Scheme   string
+	Host     string
+	Port     string
+	User     string
+	Password string
+	Path     string
+	Query    url.Values
+}
+
+// ParseURL parses s into connection information. s must be an absolute
+// URL.
+func ParseURL(s string) (*URL, error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme == "" || u.Opaque != "" {
+		return nil, fmt.Errorf("env: %q is not an absolute URL", s)
+	}
+	cu := &URL{
+		Scheme: u.Scheme,
+		Host:   u.Hostname(),
+		Port:   u.Port(),
+		Path:   u.Path,
+		Query:  u.Query(),
+	}
+	if u.User != nil {
+		cu.User = u.User.Username()
+		cu.Password, _ = u.User.Password()
+	}
+	return cu, nil
+}
+
+// URL parses the value of the variable named by key as connection
+// information. It returns an error if the variable is not set, or if
+// its value is not a valid URL, or does not satisfy the constraints
+// specified by opts. If the variable is not set, the error wraps
+// ErrNotFound.
+func (m Map) URL(key string, opts ...URLOption) (*URL, error) {
+	v, ok := m[key]
+	if !ok {
+		return nil, notSet(key)
+	}
+	if len(opts) > 0 {
+		var c URLConstraint
+		for _, opt := range opts {
+			opt(&c)
+		}
+		if err := c.check(v); err != nil {
+			return nil, invalid(key, err)
+		}
+		v = c.normalize(v)
+	}
+	u, err := ParseURL(v)
+	if err != nil {
+		return nil, fmt.Errorf("env: %s: %v", key, err)
+	}
+	return u, nil
+}
+
+// URL returns the connection information as a *url.URL, including the
+// password, if any.
+func (u *URL) URL() *url.URL {
+	host := u.Host
+	switch {
+	case u.Port != "":
+		host = net.JoinHostPort(u.Host, u.Port)
+	case strings.Contains(u.Host, ":"):
+		host = "[" + u.Host + "]"
+	}
+	uu := &url.URL{
+		Scheme:   u.Scheme,
+		Host:     host,
+		Path:     u.Path,
+		RawQuery: u.Query.Encode(),
+	}
+	switch {
+	case u.Password != "":
+		uu.User = url.UserPassword(u.User, u.Password)
+	case u.User != "":
+		uu.User = url.User(u.User)
+	}
+	return uu
+}
+
+// String returns the connection information in URL form. The password,
+// if any, is redacted.
+func (u *URL) String() string {
+	uu := u.URL()
+	if u.Password != "" {
+		uu.User = url.UserPassword(u.User, redacted)
+	}
+	return uu.String()
+}
+
+// redacted is the replacement for secret values in redacted output.
+const redacted = "xxxxx"
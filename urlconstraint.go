@@ -0,0 +1,132 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// A URLConstraint constrains a URL-valued variable.
+type URLConstraint struct {
+	// Schemes lists the allowed schemes, compared ignoring case. If
+	// empty, any scheme is allowed, but the URL must be absolute.
+	Schemes []string `json:"schemes,omitempty"`
+
+	// RequireHost requires the URL to name a host.
+	RequireHost bool `json:"require_host,omitempty"`
+
+	// NoUserinfo forbids user information, such as credentials, in the
+	// URL. Credentials are better kept in variables of their own, which
+	// can be marked secret.
+	NoUserinfo bool `json:"no_userinfo,omitempty"`
+
+	// Normalize causes the value to be normalized, as by NormalizeURL,
+	// when it is applied by Schema.Apply, or decoded by Decode.
+	Normalize bool `json:"normalize,omitempty"`
+}
+
+// check checks that s satisfies c. Since URLs may hold credentials, error
+// messages do not include s.
+func (c *URLConstraint) check(s string) error {
+	u, err := url.Parse(s)
+	if err != nil {
+		return errors.New("value is not a valid URL")
+	}
+	if u.Scheme == "" {
+		return errors.New("URL is not absolute")
+	}
+	if len(c.Schemes) > 0 && !containsFold(c.Schemes, u.Scheme) {
+		return fmt.Errorf("URL scheme %s is not one of %s", u.Scheme, strings.Join(c.Schemes, ", "))
+	}
+	if c.RequireHost && u.Hostname() == "" {
+		return errors.New("URL has no host")
+	}
+	if c.NoUserinfo && u.User != nil {
+		return errors.New("URL must not contain user information")
+	}
+	return nil
+}
+
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// NormalizeURL normalizes the URL s, such that equivalent URLs compare
+// equal: the scheme and host are converted to lower case, and default
+// ports, such as 443 for https, are removed.
+func NormalizeURL(s string) (string, error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		return "", err
+	}
+	normalizeURL(u)
+	return u.String(), nil
+}
+
+func normalizeURL(u *url.URL) {
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	if port := u.Port(); port != "" && port == defaultPorts[u.Scheme] {
+		host := u.Hostname()
+		if strings.Contains(host, ":") {
+			host = "[" + host + "]"
+		}
+		u.Host = host
+	}
+}
+
+// normalize returns s, normalized if c requires it, and if s is valid.
+func (c *URLConstraint) normalize(s string) string {
+	if !c.Normalize {
+		return s
+	}
+	if n, err := NormalizeURL(s); err == nil {
+		return n
+	}
+	return s
+}
+
+// A URLOption constrains or normalizes a URL obtained by Map.URL.
+type URLOption func(*URLConstraint)
+
+// AllowSchemes restricts the allowed URL schemes.
+func AllowSchemes(schemes ...string) URLOption {
+	return func(c *URLConstraint) {
+		c.Schemes = append(c.Schemes, schemes...)
+	}
+}
+
+// RequireHost requires the URL to name a host.
+func RequireHost() URLOption {
+	return func(c *URLConstraint) { c.RequireHost = true }
+}
+
+// NoUserinfo forbids user information in the URL.
+func NoUserinfo() URLOption {
+	return func(c *URLConstraint) { c.NoUserinfo = true }
+}
+
+// Normalized normalizes the URL, as by NormalizeURL.
+func Normalized() URLOption {
+	return func(c *URLConstraint) { c.Normalize = true }
+}
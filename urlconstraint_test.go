@@ -0,0 +1,134 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"acln.ro/env"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestURLConstraint(t *testing.T) {
+	tests := []struct {
+		c     env.URLConstraint
+		value string
+		error string // substring of the reason, if any
+	}{
+		{c: env.URLConstraint{}, value: "https://api.example.com"},
+		{c: env.URLConstraint{}, value: "api.example.com", error: "not absolute"},
+		{c: env.URLConstraint{}, value: "http://[::1", error: "not a valid URL"},
+		{c: env.URLConstraint{Schemes: []string{"http", "https"}}, value: "HTTPS://x"},
+		{c: env.URLConstraint{Schemes: []string{"http", "https"}}, value: "ftp://x", error: "ftp is not one of http, https"},
+		{c: env.URLConstraint{RequireHost: true}, value: "file:///etc/hosts", error: "no host"},
+		{c: env.URLConstraint{RequireHost: true}, value: "redis://cache:6379"},
+		{c: env.URLConstraint{NoUserinfo: true}, value: "postgres://u:hunter2@db/app", error: "user information"},
+		{c: env.URLConstraint{NoUserinfo: true}, value: "postgres://db/app"},
+	}
+	for _, tt := range tests {
+		c := tt.c
+		schema := &env.Schema{Vars: []env.Var{{Key: "U", URL: &c}}}
+		err := schema.Validate(env.Map{"U": tt.value})
+		if tt.error == "" {
+			if err != nil {
+				t.Errorf("%+v, %q: %v", tt.c, tt.value, err)
+			}
+			continue
+		}
+		var verr *env.ValidationError
+		if !errors.As(err, &verr) || !strings.Contains(verr.Reason, tt.error) {
+			t.Errorf("%+v, %q: got %v, want error containing %q", tt.c, tt.value, err, tt.error)
+			continue
+		}
+		if strings.Contains(verr.Reason, "hunter2") {
+			t.Errorf("error %q leaks credentials", verr.Reason)
+		}
+	}
+}
+
+func TestNormalizeURL(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{in: "HTTPS://API.Example.COM:443/Path", want: "https://api.example.com/Path"},
+		{in: "http://example.com:80", want: "http://example.com"},
+		{in: "http://example.com:8080", want: "http://example.com:8080"},
+		{in: "https://[::1]:443/", want: "https://[::1]/"},
+		{in: "wss://example.com:443/ws", want: "wss://example.com/ws"},
+		{in: "redis://cache:6379", want: "redis://cache:6379"},
+	}
+	for _, tt := range tests {
+		got, err := env.NormalizeURL(tt.in)
+		if err != nil {
+			t.Errorf("NormalizeURL(%q): %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("NormalizeURL(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestURLOptions(t *testing.T) {
+	m := env.Map{
+		"API_URL":      "HTTPS://API.Example.com:443/v1",
+		"DATABASE_URL": "postgres://u:p@db/app",
+	}
+	u, err := m.URL("API_URL", env.AllowSchemes("https"), env.RequireHost(), env.Normalized())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Host != "api.example.com" || u.Port != "" {
+		t.Errorf("got host %q, port %q", u.Host, u.Port)
+	}
+	_, err = m.URL("DATABASE_URL", env.NoUserinfo())
+	var verr *env.ValidationError
+	if !errors.As(err, &verr) || verr.Key != "DATABASE_URL" {
+		t.Errorf("got %v, want ValidationError", err)
+	}
+}
+
+func TestURLNormalizeApplyDecode(t *testing.T) {
+	schema := &env.Schema{Vars: []env.Var{
+		{Key: "API_URL", URL: &env.URLConstraint{Normalize: true}},
+		{Key: "WEB_URL", Default: "HTTP://Example.com:80", URL: &env.URLConstraint{Normalize: true}},
+		{Key: "RAW_URL", URL: &env.URLConstraint{}},
+	}}
+	got := schema.Apply(env.Map{"API_URL": "https://API.example.com:443", "RAW_URL": "HTTP://X:80"})
+	want := env.Map{
+		"API_URL": "https://api.example.com",
+		"WEB_URL": "http://example.com",
+		"RAW_URL": "HTTP://X:80",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Apply: (-want +got)\n%s", diff)
+	}
+
+	var cfg struct {
+		API string `env:"API_URL" validate:"scheme=http,https host normalize"`
+	}
+	if err := env.Decode(env.Map{"API_URL": "HTTPS://API.example.com:443"}, &cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.API != "https://api.example.com" {
+		t.Errorf("Decode: got %q", cfg.API)
+	}
+	if err := env.Decode(env.Map{"API_URL": "ftp://x"}, &cfg); err == nil {
+		t.Error("Decode with disallowed scheme succeeded")
+	}
+}
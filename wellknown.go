@@ -0,0 +1,178 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env
+
+import (
+	"runtime"
+	"strings"
+)
+
+// Names of well-known variables. Some only have meaning on Unix, others
+// only on Windows. The Map methods Home, User, Shell, TempDir, Hostname,
+// Term and Editor resolve the variants appropriate for the running
+// platform.
+const (
+	KeyHome         = "HOME"
+	KeyHomeDrive    = "HOMEDRIVE"
+	KeyHomePath     = "HOMEPATH"
+	KeyUserProfile  = "USERPROFILE"
+	KeyUser         = "USER"
+	KeyLogname      = "LOGNAME"
+	KeyUsername     = "USERNAME"
+	KeyShell        = "SHELL"
+	KeyComSpec      = "ComSpec"
+	KeyTmpDir       = "TMPDIR"
+	KeyTmp          = "TMP"
+	KeyTemp         = "TEMP"
+	KeyHostname     = "HOSTNAME"
+	KeyComputerName = "COMPUTERNAME"
+	KeyTerm         = "TERM"
+	KeyPath         = "PATH"
+	KeyVisual       = "VISUAL"
+	KeyEditor       = "EDITOR"
+)
+
+// Home returns the home directory of the user. On Windows, it consults
+// USERPROFILE, then HOMEDRIVE and HOMEPATH, then HOME. Elsewhere, it
+// consults HOME.
+func (m Map) Home() string {
+	return m.home(runtime.GOOS)
+}
+
+func (m Map) home(goos string) string {
+	if goos != "windows" {
+		return m[KeyHome]
+	}
+	if v := m.lookupFold(KeyUserProfile); v != "" {
+		return v
+	}
+	drive, path := m.lookupFold(KeyHomeDrive), m.lookupFold(KeyHomePath)
+	if drive != "" && path != "" {
+		return drive + path
+	}
+	return m.lookupFold(KeyHome)
+}
+
+// User returns the name of the user. On Windows, it consults USERNAME.
+// Elsewhere, it consults USER, then LOGNAME.
+func (m Map) User() string {
+	return m.user(runtime.GOOS)
+}
+
+func (m Map) user(goos string) string {
+	return m.wellKnown(goos, []string{KeyUser, KeyLogname}, []string{KeyUsername})
+}
+
+// Shell returns the command interpreter of the user. On Windows, it
+// consults ComSpec. Elsewhere, it consults SHELL.
+func (m Map) Shell() string {
+	return m.wellKnown(runtime.GOOS, []string{KeyShell}, []string{KeyComSpec})
+}
+
+// TempDir returns the directory to use for temporary files, following the
+// rules of os.TempDir. On Windows, it consults TMP, then TEMP, then
+// USERPROFILE. Elsewhere, it consults TMPDIR, and defaults to "/tmp".
+func (m Map) TempDir() string {
+	return m.tempDir(runtime.GOOS)
+}
+
+func (m Map) tempDir(goos string) string {
+	v := m.wellKnown(goos, []string{KeyTmpDir}, []string{KeyTmp, KeyTemp, KeyUserProfile})
+	if v == "" && goos != "windows" {
+		v = "/tmp"
+	}
+	return v
+}
+
+// Hostname returns the name of the host, as recorded in the environment.
+// On Windows, it consults COMPUTERNAME. Elsewhere, it consults HOSTNAME,
+// which many shells set, but do not export.
+func (m Map) Hostname() string {
+	return m.wellKnown(runtime.GOOS, []string{KeyHostname}, []string{KeyComputerName})
+}
+
+// Term returns the terminal type, from TERM.
+func (m Map) Term() string {
+	return m.wellKnown(runtime.GOOS, []string{KeyTerm}, []string{KeyTerm})
+}
+
+// Editor returns the preferred text editor of the user, from VISUAL, then
+// EDITOR.
+func (m Map) Editor() string {
+	return m.wellKnown(runtime.GOOS, []string{KeyVisual, KeyEditor}, []string{KeyVisual, KeyEditor})
+}
+
+// wellKnown returns the first non-empty value among the variables for the
+// specified platform. Lookups on Windows are case-insensitive.
+func (m Map) wellKnown(goos string, unix, windows []string) string {
+	if goos != "windows" {
+		return lookupAny(m, unix...)
+	}
+	for _, k := range windows {
+		if v := m.lookupFold(k); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// lookupFold returns the value of the variable named by key, ignoring
+// case, as Windows does. An exact match takes precedence. Among other
+// matches, the least key wins.
+func (m Map) lookupFold(key string) string {
+	if v, ok := m[key]; ok {
+		return v
+	}
+	match, found := "", false
+	for k := range m {
+		if strings.EqualFold(k, key) && (!found || k < match) {
+			match, found = k, true
+		}
+	}
+	if !found {
+		return ""
+	}
+	return m[match]
+}
+
+// foldedMap supports repeated case-insensitive lookups in a Map, without
+// scanning the Map for each lookup.
+type foldedMap struct {
+	m    Map
+	keys map[string]string // upper case key -> least matching key in m
+}
+
+// folded indexes the keys of m for case-insensitive lookups.
+func (m Map) folded() foldedMap {
+	f := foldedMap{m: m, keys: make(map[string]string, len(m))}
+	for k := range m {
+		uk := strings.ToUpper(k)
+		if prev, ok := f.keys[uk]; !ok || k < prev {
+			f.keys[uk] = k
+		}
+	}
+	return f
+}
+
+// lookup is like Map.lookupFold.
+func (f foldedMap) lookup(key string) string {
+	if v, ok := f.m[key]; ok {
+		return v
+	}
+	if k, ok := f.keys[strings.ToUpper(key)]; ok {
+		return f.m[k]
+	}
+	return ""
+}
@@ -0,0 +1,85 @@
+// Copyright 2019 Andrei Tudor Călin
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package env_test
+
+import (
+	"testing"
+
+	"acln.ro/env"
+)
+
+func TestHome(t *testing.T) {
+	tests := []struct {
+		name string
+		m    env.Map
+		goos string
+		want string
+	}{
+		{"Unix", env.Map{"HOME": "/home/u", "USERPROFILE": `C:\Users\u`}, "linux", "/home/u"},
+		{"WindowsProfile", env.Map{"HOME": "/home/u", "USERPROFILE": `C:\Users\u`}, "windows", `C:\Users\u`},
+		{"WindowsDrivePath", env.Map{"HOMEDRIVE": "C:", "HOMEPATH": `\Users\u`}, "windows", `C:\Users\u`},
+		{"WindowsFold", env.Map{"UserProfile": `C:\Users\u`}, "windows", `C:\Users\u`},
+		{"WindowsHome", env.Map{"HOME": `D:\u`}, "windows", `D:\u`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := env.HomeFor(tt.m, tt.goos); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUser(t *testing.T) {
+	m := env.Map{"LOGNAME": "u", "USERNAME": "w"}
+	if got := env.UserFor(m, "linux"); got != "u" {
+		t.Errorf("linux: got %q, want %q", got, "u")
+	}
+	if got := env.UserFor(m, "windows"); got != "w" {
+		t.Errorf("windows: got %q, want %q", got, "w")
+	}
+}
+
+func TestTempDir(t *testing.T) {
+	if got := env.TempDirFor(env.Map{}, "linux"); got != "/tmp" {
+		t.Errorf("linux default: got %q, want %q", got, "/tmp")
+	}
+	if got := env.TempDirFor(env.Map{"TEMP": `C:\Temp`}, "windows"); got != `C:\Temp` {
+		t.Errorf("windows: got %q, want %q", got, `C:\Temp`)
+	}
+	if got := env.TempDirFor(env.Map{}, "windows"); got != "" {
+		t.Errorf("windows default: got %q, want empty", got)
+	}
+}
+
+func TestWellKnown(t *testing.T) {
+	m := env.Map{
+		"TERM":         "xterm",
+		"EDITOR":       "vi",
+		"SHELL":        "/bin/sh",
+		"ComSpec":      `C:\Windows\system32\cmd.exe`,
+		"HOSTNAME":     "box",
+		"COMPUTERNAME": "BOX",
+	}
+	if got := m.Term(); got != "xterm" {
+		t.Errorf("Term() = %q, want %q", got, "xterm")
+	}
+	if got := m.Editor(); got != "vi" {
+		t.Errorf("Editor() = %q, want %q", got, "vi")
+	}
+	if m.Shell() == "" || m.Hostname() == "" {
+		t.Errorf("Shell() = %q, Hostname() = %q, want non-empty", m.Shell(), m.Hostname())
+	}
+}